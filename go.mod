@@ -5,6 +5,7 @@ go 1.23.5
 require (
 	github.com/go-i2p/i2pkeys v0.33.92
 	github.com/magiconair/properties v1.8.9
+	github.com/santhosh-tekuri/jsonschema/v5 v5.3.1
 	github.com/urfave/cli/v2 v2.27.7
 	gopkg.in/yaml.v2 v2.4.0
 )
@@ -0,0 +1,49 @@
+package i2pconv
+
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v2"
+)
+
+// applyBaseConfig loads the base template at path for --base and returns
+// config laid over it: the fields config sets win, and the option maps are
+// merged key by key so the base supplies every option config leaves out.
+func applyBaseConfig(config *TunnelConfig, path string, converter *Converter) (*TunnelConfig, error) {
+	base, err := readBaseConfig(path, converter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load base config '%s': %w", path, err)
+	}
+	base.Merge(config)
+	return base, nil
+}
+
+// readBaseConfig parses the base template at path. The format is detected
+// from the extension, and a .gz template is decompressed first. A YAML template may be a bare document holding only the
+// shared fields, such as a top-level "i2cp" map, without a name or type.
+func readBaseConfig(path string, converter *Converter) (*TunnelConfig, error) {
+	data, err := readInputFile(path)
+	if err != nil {
+		return nil, err
+	}
+	format, err := converter.DetectFormat(path)
+	if err != nil {
+		return nil, err
+	}
+	if format == "yaml" {
+		data = normalizeInput(data)
+		var top map[string]interface{}
+		if err := yaml.Unmarshal(data, &top); err != nil {
+			return nil, fmt.Errorf("yaml parse error: %w", err)
+		}
+		if _, nested := top["tunnels"]; !nested {
+			var base TunnelConfig
+			if err := yaml.Unmarshal(data, &base); err != nil {
+				return nil, fmt.Errorf("yaml parse error: %w", err)
+			}
+			normalizeOptionTypes(&base)
+			return &base, nil
+		}
+	}
+	return converter.ParseInput(data, format)
+}
@@ -0,0 +1,80 @@
+package i2pconv
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestApplyBaseConfig checks that a child config laid over a base template
+// keeps the base's i2cp options and its own port.
+func TestApplyBaseConfig(t *testing.T) {
+	dir := t.TempDir()
+	basePath := filepath.Join(dir, "common.yaml")
+	base := "port: 4444\ni2cp:\n  reduceIdleTime: 900000\n  reduceOnIdle: true\n"
+	if err := os.WriteFile(basePath, []byte(base), 0o644); err != nil {
+		t.Fatalf("setup: %v", err)
+	}
+	child := &TunnelConfig{
+		Name: "web",
+		Type: "httpclient",
+		Port: 8080,
+		I2CP: map[string]interface{}{"reduceOnIdle": false},
+	}
+
+	got, err := applyBaseConfig(child, basePath, &Converter{})
+	if err != nil {
+		t.Fatalf("applyBaseConfig() error = %v", err)
+	}
+	if got.Name != "web" || got.Type != "httpclient" {
+		t.Errorf("name/type = %q/%q, want web/httpclient", got.Name, got.Type)
+	}
+	if got.Port != 8080 {
+		t.Errorf("Port = %d, want the child's 8080", got.Port)
+	}
+	if fmt.Sprint(got.I2CP["reduceIdleTime"]) != "900000" {
+		t.Errorf("i2cp.reduceIdleTime = %v, want 900000 from the base", got.I2CP["reduceIdleTime"])
+	}
+	if got.I2CP["reduceOnIdle"] != false {
+		t.Errorf("i2cp.reduceOnIdle = %v, want the child's false", got.I2CP["reduceOnIdle"])
+	}
+	if _, ok := child.I2CP["reduceIdleTime"]; ok {
+		t.Error("applyBaseConfig() modified the child config")
+	}
+
+	if _, err := applyBaseConfig(child, filepath.Join(dir, "missing.yaml"), &Converter{}); err == nil {
+		t.Error("applyBaseConfig() with a missing base should fail")
+	}
+}
+
+// TestProcessSingleFile_Base converts a properties tunnel over a YAML base
+// template and checks the output carries the options of both.
+func TestProcessSingleFile_Base(t *testing.T) {
+	dir := t.TempDir()
+	basePath := filepath.Join(dir, "common.yaml")
+	if err := os.WriteFile(basePath, []byte("i2cp:\n  reduceIdleTime: 900000\ninbound:\n  length: 2\n"), 0o644); err != nil {
+		t.Fatalf("setup: %v", err)
+	}
+	inputFile := filepath.Join(dir, "web.properties")
+	content := "name=web\ntype=httpclient\nlistenPort=8080\noption.inbound.length=3\n"
+	if err := os.WriteFile(inputFile, []byte(content), 0o644); err != nil {
+		t.Fatalf("setup: %v", err)
+	}
+
+	outputFile := filepath.Join(dir, "web.ini")
+	opts := processOptions{outputFormat: "ini", baseFile: basePath}
+	if _, err := processSingleFile(inputFile, outputFile, opts, &Converter{}); err != nil {
+		t.Fatalf("processSingleFile() error = %v", err)
+	}
+	out, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	for _, want := range []string{"port = 8080\n", "i2cp.reduceIdleTime = 900000\n", "inbound.length = 3\n"} {
+		if !strings.Contains(string(out), want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package i2pconv
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+)
+
+// clientBinding returns the local address a client tunnel listens on, with an
+// unset interface read as the default 127.0.0.1. The second result is false
+// for server tunnels and for clients without a port.
+func clientBinding(config *TunnelConfig) (string, bool) {
+	if config == nil || !TunnelType(config.Type).IsClient() || config.Port <= 0 {
+		return "", false
+	}
+	host := config.Interface
+	if host == "" {
+		host = defaultClientInterface
+	}
+	return net.JoinHostPort(host, strconv.Itoa(config.Port)), true
+}
+
+// bindingsOverlap reports whether two client bindings would claim the same
+// socket: the ports match and the interfaces are equal, or either one is a
+// wildcard address that listens on every interface.
+func bindingsOverlap(a, b string) bool {
+	hostA, portA, _ := net.SplitHostPort(a)
+	hostB, portB, _ := net.SplitHostPort(b)
+	if portA != portB {
+		return false
+	}
+	wildcard := func(h string) bool { return h == "0.0.0.0" || h == "::" }
+	return hostA == hostB || wildcard(hostA) || wildcard(hostB)
+}
+
+// checkBatchBindings marks as failed every successfully processed result
+// whose client tunnel binds an address already bound by an earlier file in
+// the batch, since only one of them could start. Each file is converted on its
+// own, so this is the one check that needs the whole batch.
+func checkBatchBindings(results []BatchResult) {
+	type bound struct {
+		addr string
+		name string
+		file string
+	}
+	var seen []bound
+	for i := range results {
+		r := &results[i]
+		if !r.Success {
+			continue
+		}
+		addr, ok := clientBinding(r.config)
+		if !ok {
+			continue
+		}
+		collision := false
+		for _, b := range seen {
+			if bindingsOverlap(addr, b.addr) {
+				r.Success = false
+				via := ""
+				if b.addr != addr {
+					via = " (on " + b.addr + ")"
+				}
+				r.Error = fmt.Errorf("tunnel '%s' listens on %s, already bound by tunnel '%s' in '%s'%s", r.config.Name, addr, b.name, b.file, via)
+				collision = true
+				break
+			}
+		}
+		if !collision {
+			seen = append(seen, bound{addr: addr, name: r.config.Name, file: r.InputFile})
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package i2pconv
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestBindingsOverlap checks that two listen addresses collide on the same port
+// when they share a host or one is a wildcard.
+func TestBindingsOverlap(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"127.0.0.1:4444", "127.0.0.1:4444", true},
+		{"127.0.0.1:4444", "127.0.0.1:4445", false},
+		{"127.0.0.1:4444", "192.168.1.2:4444", false},
+		{"0.0.0.0:4444", "127.0.0.1:4444", true},
+		{"[::1]:4444", "[::]:4444", true},
+	}
+	for _, tt := range tests {
+		if got := bindingsOverlap(tt.a, tt.b); got != tt.want {
+			t.Errorf("bindingsOverlap(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+// TestClientBinding checks the address a client tunnel listens on, and that
+// servers and clients without a port have none.
+func TestClientBinding(t *testing.T) {
+	tests := []struct {
+		name   string
+		config *TunnelConfig
+		want   string
+	}{
+		{name: "default interface", config: &TunnelConfig{Type: "httpclient", Port: 4444}, want: "127.0.0.1:4444"},
+		{name: "explicit interface", config: &TunnelConfig{Type: "socks", Interface: "::1", Port: 4447}, want: "[::1]:4447"},
+		{name: "server", config: &TunnelConfig{Type: "httpserver", Port: 8080, Target: "127.0.0.1:80"}},
+		{name: "no port", config: &TunnelConfig{Type: "client"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := clientBinding(tt.config)
+			if got != tt.want || ok != (tt.want != "") {
+				t.Errorf("clientBinding() = %q, %v, want %q", got, ok, tt.want)
+			}
+		})
+	}
+}
+
+// TestProcessBatch_BindingCollisions checks that a strict batch fails the
+// client that binds an address already bound by an earlier file, and that a
+// non-strict batch allows it.
+func TestProcessBatch_BindingCollisions(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"a.config": "name=web\ntype=httpclient\ninterface=127.0.0.1\nlistenPort=4444\n",
+		"b.config": "name=web2\ntype=httpclient\nlistenPort=4444\n",
+		"c.config": "name=socks\ntype=sockstunnel\ninterface=127.0.0.1\nlistenPort=4447\n",
+		"d.config": "name=site\ntype=httpserver\ntargetHost=127.0.0.1\ntargetPort=4444\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("setup: %v", err)
+		}
+	}
+	pattern := filepath.Join(dir, "*.config")
+
+	run := func(args ...string) (string, error) {
+		var err error
+		stdout := captureOutput(t, &os.Stdout, func() {
+			err = makeStdinApp().Run(append(append([]string{"go-i2ptunnel-config", "--batch", "--validate"}, args...), pattern))
+		})
+		return stdout, err
+	}
+
+	stdout, err := run("--strict")
+	if err == nil || err.Error() != "1 of 4 files failed processing" {
+		t.Errorf("strict batch error = %v, want only the colliding file to fail\n%s", err, stdout)
+	}
+	want := "tunnel 'web2' listens on 127.0.0.1:4444, already bound by tunnel 'web' in '" + filepath.Join(dir, "a.config") + "'"
+	if !strings.Contains(stdout, want) {
+		t.Errorf("collision not reported as %q:\n%s", want, stdout)
+	}
+
+	if stdout, err := run(); err != nil {
+		t.Errorf("non-strict batch error = %v\n%s", err, stdout)
+	}
+}
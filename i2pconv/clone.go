@@ -0,0 +1,85 @@
+package i2pconv
+
+// Clone returns a deep copy of t. The option maps, any slices or maps nested
+// in their values, the Unknown map, and the Comments are all copied, so the
+// clone can be modified without affecting t.
+func (t *TunnelConfig) Clone() *TunnelConfig {
+	if t == nil {
+		return nil
+	}
+	c := *t
+	c.I2CP = cloneOptionMap(t.I2CP)
+	c.Tunnel = cloneOptionMap(t.Tunnel)
+	c.Inbound = cloneOptionMap(t.Inbound)
+	c.Outbound = cloneOptionMap(t.Outbound)
+	if t.Unknown != nil {
+		c.Unknown = make(map[string]string, len(t.Unknown))
+		for k, v := range t.Unknown {
+			c.Unknown[k] = v
+		}
+	}
+	if t.Enabled != nil {
+		enabled := *t.Enabled
+		c.Enabled = &enabled
+	}
+	c.Comments = t.Comments.clone()
+	return &c
+}
+
+// cloneOptionMap deep-copies an option map. A nil map stays nil.
+func cloneOptionMap(m map[string]interface{}) map[string]interface{} {
+	if m == nil {
+		return nil
+	}
+	out := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		out[k] = cloneOptionValue(v)
+	}
+	return out
+}
+
+// cloneOptionValue copies the slice and map types the parsers produce. Other
+// values are immutable and returned as is.
+func cloneOptionValue(v interface{}) interface{} {
+	switch val := v.(type) {
+	case []string:
+		return append([]string(nil), val...)
+	case []interface{}:
+		out := make([]interface{}, len(val))
+		for i, item := range val {
+			out[i] = cloneOptionValue(item)
+		}
+		return out
+	case map[string]interface{}:
+		return cloneOptionMap(val)
+	case map[interface{}]interface{}:
+		out := make(map[interface{}]interface{}, len(val))
+		for k, item := range val {
+			out[k] = cloneOptionValue(item)
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+// clone returns a deep copy of c.
+func (c *Comments) clone() *Comments {
+	if c == nil {
+		return nil
+	}
+	out := &Comments{
+		Format:   c.Format,
+		Header:   append([]string(nil), c.Header...),
+		Leading:  make(map[string][]string, len(c.Leading)),
+		Inline:   make(map[string]string, len(c.Inline)),
+		Trailing: append([]string(nil), c.Trailing...),
+	}
+	for k, v := range c.Leading {
+		out.Leading[k] = append([]string(nil), v...)
+	}
+	for k, v := range c.Inline {
+		out.Inline[k] = v
+	}
+	return out
+}
@@ -0,0 +1,77 @@
+package i2pconv
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestTunnelConfig_Clone checks that a clone equals the original and shares
+// none of its maps, slices, or comments.
+func TestTunnelConfig_Clone(t *testing.T) {
+	original := &TunnelConfig{
+		Name: "web",
+		Type: "httpclient",
+		Port: 4444,
+		I2CP: map[string]interface{}{
+			"leaseSetEncType": []string{"4", "0"},
+			"reduceOnIdle":    true,
+		},
+		Tunnel:   map[string]interface{}{"proxyList": []interface{}{"a.i2p", "b.i2p"}},
+		Inbound:  map[string]interface{}{"length": 3},
+		Outbound: map[string]interface{}{"length": 3},
+		Unknown:  map[string]string{"foo": "bar"},
+		Comments: &Comments{
+			Format:  "ini",
+			Leading: map[string][]string{"port": {"; local port"}},
+			Inline:  map[string]string{},
+		},
+	}
+
+	clone := original.Clone()
+	if !reflect.DeepEqual(clone, original) {
+		t.Fatalf("Clone() = %+v, want %+v", clone, original)
+	}
+
+	clone.I2CP["reduceOnIdle"] = false
+	clone.I2CP["closeOnIdle"] = true
+	clone.I2CP["leaseSetEncType"].([]string)[0] = "6"
+	clone.Tunnel["proxyList"].([]interface{})[1] = "c.i2p"
+	clone.Inbound["length"] = 1
+	clone.Unknown["foo"] = "baz"
+	clone.Comments.Leading["port"][0] = "; changed"
+
+	if original.I2CP["reduceOnIdle"] != true {
+		t.Error("mutating the clone's I2CP map changed the original")
+	}
+	if _, ok := original.I2CP["closeOnIdle"]; ok {
+		t.Error("adding to the clone's I2CP map changed the original")
+	}
+	if got := original.I2CP["leaseSetEncType"].([]string)[0]; got != "4" {
+		t.Errorf("original leaseSetEncType[0] = %q, want 4", got)
+	}
+	if got := original.Tunnel["proxyList"].([]interface{})[1]; got != "b.i2p" {
+		t.Errorf("original proxyList[1] = %v, want b.i2p", got)
+	}
+	if original.Inbound["length"] != 3 {
+		t.Error("mutating the clone's Inbound map changed the original")
+	}
+	if original.Unknown["foo"] != "bar" {
+		t.Error("mutating the clone's Unknown map changed the original")
+	}
+	if original.Comments.Leading["port"][0] != "; local port" {
+		t.Error("mutating the clone's comments changed the original")
+	}
+}
+
+// TestTunnelConfig_CloneNil checks that cloning a nil config gives nil and that
+// nil maps stay nil.
+func TestTunnelConfig_CloneNil(t *testing.T) {
+	var config *TunnelConfig
+	if config.Clone() != nil {
+		t.Error("Clone() of nil config should be nil")
+	}
+	empty := (&TunnelConfig{Name: "x"}).Clone()
+	if empty.I2CP != nil || empty.Unknown != nil || empty.Comments != nil {
+		t.Errorf("Clone() should keep nil maps nil, got %+v", empty)
+	}
+}
@@ -0,0 +1,431 @@
+package i2pconv
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Comments holds the comment lines of an INI or properties file so that a
+// same-format round trip can write them back. Keys are option keys as they
+// appear in the file ("port", "i2cp.leaseSetEncType", "listenPort"); numbered
+// properties keys are stored without their "tunnel.N." prefix. Comment lines
+// are kept verbatim, including the ';', '#', or '!' marker.
+type Comments struct {
+	Format   string              // Format the comments were read from
+	Header   []string            // Comment block at the top of the file
+	Leading  map[string][]string // Comment lines directly above each key
+	Inline   map[string]string   // Comment following the value on a key's line (INI only)
+	Trailing []string            // Comment lines after the last key
+}
+
+// commentCollector gathers comment lines while a parser walks its input.
+type commentCollector struct {
+	comments *Comments
+	pending  []string
+	seenKey  bool
+}
+
+func newCommentCollector(format string) *commentCollector {
+	return &commentCollector{comments: &Comments{
+		Format:  format,
+		Leading: make(map[string][]string),
+		Inline:  make(map[string]string),
+	}}
+}
+
+// comment records a comment line.
+func (cc *commentCollector) comment(line string) {
+	cc.pending = append(cc.pending, line)
+}
+
+// blank records a blank line. A comment block separated by a blank line from
+// everything after it, before any key, is the file header.
+func (cc *commentCollector) blank() {
+	cc.flushHeader()
+}
+
+// flushHeader moves pending comments into the header while no key has been
+// seen yet.
+func (cc *commentCollector) flushHeader() {
+	if !cc.seenKey && len(cc.pending) > 0 {
+		cc.comments.Header = append(cc.comments.Header, cc.pending...)
+		cc.pending = nil
+	}
+}
+
+// key attaches the pending comments and an optional inline comment to key.
+func (cc *commentCollector) key(key, inline string) {
+	cc.seenKey = true
+	if len(cc.pending) > 0 {
+		cc.comments.Leading[key] = append(cc.comments.Leading[key], cc.pending...)
+		cc.pending = nil
+	}
+	if inline != "" {
+		cc.comments.Inline[key] = inline
+	}
+}
+
+// result returns the collected comments, or nil when the input had none.
+func (cc *commentCollector) result() *Comments {
+	cc.comments.Trailing = cc.pending
+	c := cc.comments
+	if len(c.Header) == 0 && len(c.Leading) == 0 && len(c.Inline) == 0 && len(c.Trailing) == 0 {
+		return nil
+	}
+	return c
+}
+
+// iniFreeTextKeys lists the INI keys whose values are free text, in which
+// " #" or " ;" is part of the value, as in "description = Tunnel #1 proxy".
+// Inline comments are not split from them.
+var iniFreeTextKeys = map[string]bool{
+	"description": true,
+}
+
+// splitINIInlineComment separates a trailing "; ..." or "# ..." comment from
+// an INI value. The marker must follow whitespace so values such as
+// "pass#word" are left intact. It is not applied to iniFreeTextKeys.
+func splitINIInlineComment(value string) (string, string) {
+	for i := 1; i < len(value); i++ {
+		if (value[i] == ';' || value[i] == '#') && (value[i-1] == ' ' || value[i-1] == '\t') {
+			return strings.TrimSpace(value[:i]), value[i:]
+		}
+	}
+	return value, ""
+}
+
+// applyComments writes comments back into generated output for format. The
+// output is returned unchanged when comments were read from another format.
+// keyOf extracts the key from an output line, returning "" for lines that do
+// not hold a key.
+func applyComments(out []byte, format string, comments *Comments, keyOf func(line string) string) []byte {
+	if comments == nil || comments.Format != format {
+		return out
+	}
+
+	var sb strings.Builder
+	for _, c := range comments.Header {
+		sb.WriteString(c + "\n")
+	}
+	if len(comments.Header) > 0 {
+		sb.WriteString("\n")
+	}
+	for _, line := range strings.SplitAfter(string(out), "\n") {
+		if line == "" {
+			continue
+		}
+		key := keyOf(strings.TrimRight(line, "\r\n"))
+		if key == "" {
+			sb.WriteString(line)
+			continue
+		}
+		indent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+		for _, c := range comments.Leading[key] {
+			sb.WriteString(indent + c + "\n")
+		}
+		if inline, ok := comments.Inline[key]; ok {
+			sb.WriteString(strings.TrimRight(line, "\n") + " " + inline + "\n")
+			continue
+		}
+		sb.WriteString(line)
+	}
+	for _, c := range comments.Trailing {
+		sb.WriteString(c + "\n")
+	}
+	return []byte(sb.String())
+}
+
+// iniLineKey returns the key of an INI "key = value" line.
+func iniLineKey(line string) string {
+	line = strings.TrimSpace(line)
+	if strings.HasPrefix(line, "[") {
+		return ""
+	}
+	if k, _, ok := strings.Cut(line, "="); ok {
+		return trimINIPoolPrefix(strings.TrimSpace(k))
+	}
+	return ""
+}
+
+// propertiesLineKey returns the key of a properties line without any
+// "tunnel.N." prefix.
+func propertiesLineKey(line string) string {
+	line = strings.TrimSpace(line)
+	if line == "" || line[0] == '#' || line[0] == '!' {
+		return ""
+	}
+	return stripTunnelIndex(propertyKey(line))
+}
+
+// stripTunnelIndex removes a leading "tunnel.N." from a numbered properties key.
+func stripTunnelIndex(key string) string {
+	if !strings.HasPrefix(key, "tunnel.") {
+		return key
+	}
+	rest := key[len("tunnel."):]
+	i := 0
+	for i < len(rest) && rest[i] >= '0' && rest[i] <= '9' {
+		i++
+	}
+	if i == 0 || i >= len(rest) || rest[i] != '.' {
+		return key
+	}
+	return rest[i+1:]
+}
+
+// CommentSidecar holds the comments of a tunnel file apart from its format, so
+// a conversion between formats can carry them in a ".meta.yaml" file. Comments
+// are keyed by the field of the line they precede, using the paths of
+// TunnelConfig.Get ("port", "i2cp.leaseSetEncType"), and stored without their
+// comment marker. A key whose field cannot be told is kept as it appeared in
+// the file.
+type CommentSidecar struct {
+	Source   string              `yaml:"source"` // Format the comments were read from
+	Header   []string            `yaml:"header,omitempty"`
+	Fields   map[string][]string `yaml:"fields,omitempty"`
+	Inline   map[string]string   `yaml:"inline,omitempty"`
+	Trailing []string            `yaml:"trailing,omitempty"`
+}
+
+// ExtractComments returns the comments of config as a sidecar, or nil when
+// config has none. Only the INI and properties parsers collect comments.
+func ExtractComments(config *TunnelConfig) *CommentSidecar {
+	c := config.Comments
+	if c == nil {
+		return nil
+	}
+	s := &CommentSidecar{
+		Source:   c.Format,
+		Header:   commentTexts(c.Header),
+		Trailing: commentTexts(c.Trailing),
+	}
+	for key, lines := range c.Leading {
+		if s.Fields == nil {
+			s.Fields = make(map[string][]string)
+		}
+		field := commentField(c.Format, key)
+		s.Fields[field] = append(s.Fields[field], commentTexts(lines)...)
+	}
+	for key, line := range c.Inline {
+		if s.Inline == nil {
+			s.Inline = make(map[string]string)
+		}
+		s.Inline[commentField(c.Format, key)] = commentText(line)
+	}
+	return s
+}
+
+// LoadCommentSidecar reads a sidecar written by WriteCommentSidecar.
+func LoadCommentSidecar(path string) (*CommentSidecar, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read comments file '%s': %w", path, err)
+	}
+	var s CommentSidecar
+	if err := yaml.UnmarshalStrict(data, &s); err != nil {
+		return nil, fmt.Errorf("failed to parse comments file '%s': %w", path, err)
+	}
+	return &s, nil
+}
+
+// WriteCommentSidecar writes s to path as YAML.
+func WriteCommentSidecar(s *CommentSidecar, path string) error {
+	data, err := yaml.Marshal(s)
+	if err != nil {
+		return fmt.Errorf("failed to encode comments: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write comments file '%s': %w", path, err)
+	}
+	return nil
+}
+
+// Apply writes the sidecar's comments into out, generated output of format,
+// each above the line that sets its field. Comments whose field out does not
+// set are written after the last line rather than dropped. Inline comments
+// stay inline in INI output and go above the line elsewhere, since properties
+// and YAML values may contain '#'.
+func (s *CommentSidecar) Apply(out []byte, format string) []byte {
+	if s == nil {
+		return out
+	}
+	keyOf := commentFieldOf(format)
+	present := make(map[string]bool)
+	for _, line := range strings.Split(string(out), "\n") {
+		if field := keyOf(line); field != "" {
+			present[field] = true
+		}
+	}
+
+	c := &Comments{
+		Format:  format,
+		Header:  commentLines(s.Header),
+		Leading: make(map[string][]string),
+		Inline:  make(map[string]string),
+	}
+	var leftover []string
+	for _, field := range sortedCommentFields(s) {
+		lines := commentLines(s.Fields[field])
+		inline, hasInline := s.Inline[field]
+		if hasInline && format != "ini" {
+			lines = append(lines, commentLines([]string{inline})...)
+		}
+		if !present[field] {
+			leftover = append(leftover, lines...)
+			if hasInline && format == "ini" {
+				leftover = append(leftover, commentLines([]string{inline})...)
+			}
+			continue
+		}
+		if len(lines) > 0 {
+			c.Leading[field] = lines
+		}
+		if hasInline && format == "ini" {
+			c.Inline[field] = "# " + inline
+		}
+	}
+	c.Trailing = append(leftover, commentLines(s.Trailing)...)
+	// A fresh keyOf, since the YAML one tracks indentation from the first line
+	return applyComments(out, format, c, commentFieldOf(format))
+}
+
+// sortedCommentFields returns the fields of s that have leading or inline
+// comments, sorted.
+func sortedCommentFields(s *CommentSidecar) []string {
+	seen := make(map[string]bool)
+	var fields []string
+	for field := range s.Fields {
+		seen[field] = true
+		fields = append(fields, field)
+	}
+	for field := range s.Inline {
+		if !seen[field] {
+			fields = append(fields, field)
+		}
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// commentText strips the comment marker, and one space after it, from line.
+func commentText(line string) string {
+	line = strings.TrimSpace(line)
+	if line != "" && strings.ContainsRune("#;!", rune(line[0])) {
+		line = strings.TrimPrefix(line[1:], " ")
+	}
+	return line
+}
+
+// commentTexts applies commentText to every line.
+func commentTexts(lines []string) []string {
+	var texts []string
+	for _, line := range lines {
+		texts = append(texts, commentText(line))
+	}
+	return texts
+}
+
+// commentLines turns sidecar texts back into '#' comment lines, which every
+// supported format accepts.
+func commentLines(texts []string) []string {
+	var lines []string
+	for _, text := range texts {
+		lines = append(lines, strings.TrimRight("# "+text, " "))
+	}
+	return lines
+}
+
+// commentField returns the TunnelConfig.Get path that key sets in a format
+// file, found by parsing the key into an empty config. It returns key itself
+// when no field is set. YAML keys are already paths.
+func commentField(format, key string) string {
+	if format == "yaml" {
+		return key
+	}
+	conv := &Converter{}
+	// Some keys only take typed values, such as a port number or a boolean
+	for _, value := range []string{"1", "true", "x"} {
+		probe := &TunnelConfig{}
+		switch format {
+		case "ini":
+			conv.parseINIKeyValue(key, value, probe)
+		case "properties":
+			conv.parsePropertyKey(key, value, probe)
+		}
+		if diffs := Diff(&TunnelConfig{}, probe); len(diffs) > 0 {
+			return diffs[0].Path
+		}
+	}
+	return key
+}
+
+// commentFieldOf returns a function giving the field set by each line of
+// generated output in format, or "" for lines that set none. The YAML
+// function follows indentation, so it must see every line in order.
+func commentFieldOf(format string) func(line string) string {
+	switch format {
+	case "ini":
+		return func(line string) string {
+			if key := iniLineKey(line); key != "" {
+				return commentField(format, key)
+			}
+			return ""
+		}
+	case "properties":
+		return func(line string) string {
+			if key := propertiesLineKey(line); key != "" {
+				return commentField(format, key)
+			}
+			return ""
+		}
+	case "yaml":
+		return yamlLineField()
+	}
+	return func(string) string { return "" }
+}
+
+// yamlLineField returns a function giving the TunnelConfig.Get path of each
+// "key: value" line of generated YAML, such as "port" or "i2cp.leaseSetEncType".
+// Lines that open a mapping return "", as do the "tunnels:" and tunnel name
+// lines of the multi-tunnel layout.
+func yamlLineField() func(line string) string {
+	type parent struct {
+		indent int
+		key    string
+	}
+	var parents []parent
+	return func(line string) string {
+		trimmed := strings.TrimLeft(line, " ")
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, "- ") {
+			return ""
+		}
+		key, value, ok := strings.Cut(trimmed, ":")
+		if !ok {
+			return ""
+		}
+		indent := len(line) - len(trimmed)
+		for len(parents) > 0 && parents[len(parents)-1].indent >= indent {
+			parents = parents[:len(parents)-1]
+		}
+		path := make([]string, 0, len(parents)+1)
+		for _, p := range parents {
+			path = append(path, p.key)
+		}
+		path = append(path, key)
+		if strings.TrimSpace(value) == "" {
+			parents = append(parents, parent{indent: indent, key: key})
+			return ""
+		}
+		if path[0] == "tunnels" {
+			if len(path) < 3 {
+				return ""
+			}
+			path = path[2:]
+		}
+		return strings.Join(path, ".")
+	}
+}
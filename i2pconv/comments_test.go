@@ -0,0 +1,185 @@
+package i2pconv
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/urfave/cli/v2"
+)
+
+const commentedProperties = `# Web proxy for the office
+# maintained by ops
+
+name=web
+type=httpclient
+# local listener
+listenPort=4480
+! outbound tuning
+option.outbound.length=2
+# idle reduction
+option.i2cp.reduceIdleTime=900000
+# end of file
+`
+
+// TestExtractComments_Properties checks the header, field, and trailing
+// comments extracted from commented properties input.
+func TestExtractComments_Properties(t *testing.T) {
+	config, err := (&Converter{}).ParseInput([]byte(commentedProperties), "properties")
+	if err != nil {
+		t.Fatalf("ParseInput() error = %v", err)
+	}
+	got := ExtractComments(config)
+	want := &CommentSidecar{
+		Source: "properties",
+		Header: []string{"Web proxy for the office", "maintained by ops"},
+		Fields: map[string][]string{
+			"port":                {"local listener"},
+			"outbound.length":     {"outbound tuning"},
+			"i2cp.reduceIdleTime": {"idle reduction"},
+		},
+		Trailing: []string{"end of file"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ExtractComments() = %#v, want %#v", got, want)
+	}
+
+	if got := ExtractComments(&TunnelConfig{Name: "bare"}); got != nil {
+		t.Errorf("ExtractComments() without comments = %#v, want nil", got)
+	}
+}
+
+// TestExtractComments_INIInline checks that an inline INI comment is extracted
+// apart from the comment line above its key.
+func TestExtractComments_INIInline(t *testing.T) {
+	input := "[web]\ntype = http\n; listen here\nport = 4480 ; not 4444\nkeys = web.dat\n"
+	config, err := (&Converter{}).ParseInput([]byte(input), "ini")
+	if err != nil {
+		t.Fatalf("ParseInput() error = %v", err)
+	}
+	got := ExtractComments(config)
+	if !reflect.DeepEqual(got.Fields, map[string][]string{"port": {"listen here"}}) {
+		t.Errorf("Fields = %v", got.Fields)
+	}
+	if !reflect.DeepEqual(got.Inline, map[string]string{"port": "not 4444"}) {
+		t.Errorf("Inline = %v", got.Inline)
+	}
+}
+
+// TestCommentSidecar_Apply checks that a sidecar's comments are written above
+// the matching keys of each output format, with those whose key is gone kept at
+// the end.
+func TestCommentSidecar_Apply(t *testing.T) {
+	sidecar := &CommentSidecar{
+		Source: "ini",
+		Header: []string{"Office tunnels"},
+		Fields: map[string][]string{
+			"port":                {"local listener"},
+			"i2cp.reduceIdleTime": {"idle reduction"},
+			"options.gone":        {"kept anyway"},
+		},
+		Inline: map[string]string{"port": "not 4444"},
+	}
+	config := &TunnelConfig{Name: "web", Type: "httpclient", Port: 4480, I2CP: map[string]interface{}{"reduceIdleTime": 900000}}
+
+	tests := []struct {
+		format string
+		want   []string // Lines that must appear in order
+	}{
+		{format: "ini", want: []string{"# Office tunnels", "# local listener", "port = 4480 # not 4444", "# idle reduction", "i2cp.reduceIdleTime = 900000", "# kept anyway"}},
+		{format: "properties", want: []string{"# Office tunnels", "# local listener", "# not 4444", "listenPort=4480", "# idle reduction", "option.i2cp.reduceIdleTime=900000", "# kept anyway"}},
+		{format: "yaml", want: []string{"# Office tunnels", "    # local listener", "    # not 4444", "    port: 4480", "      # idle reduction", "      reduceIdleTime: 900000", "# kept anyway"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			conv := &Converter{}
+			out, err := conv.generateOutput(config, tt.format)
+			if err != nil {
+				t.Fatalf("generateOutput() error = %v", err)
+			}
+			applied := string(sidecar.Apply(out, tt.format))
+			rest := applied
+			for _, line := range tt.want {
+				i := strings.Index(rest, line+"\n")
+				if i < 0 {
+					t.Fatalf("output is missing %q in order:\n%s", line, applied)
+				}
+				rest = rest[i+len(line):]
+			}
+			reparsed, err := conv.ParseInput([]byte(applied), tt.format)
+			if err != nil {
+				t.Fatalf("commented output does not parse: %v\n%s", err, applied)
+			}
+			if !config.Equal(reparsed) {
+				t.Errorf("comments changed the config: %v", Diff(config, reparsed))
+			}
+		})
+	}
+}
+
+// TestConvertCommand_ExtractAndApplyComments extracts comments to a sidecar in
+// one conversion and applies them in a later one, and checks that an existing
+// sidecar is not replaced without --force.
+func TestConvertCommand_ExtractAndApplyComments(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "web.properties")
+	if err := os.WriteFile(input, []byte(commentedProperties), 0o644); err != nil {
+		t.Fatalf("setup: %v", err)
+	}
+	newApp := func() *cli.App {
+		app := makeStdinApp()
+		app.Flags = append(app.Flags,
+			&cli.BoolFlag{Name: "extract-comments"},
+			&cli.StringFlag{Name: "apply-comments"},
+		)
+		return app
+	}
+
+	yamlFile := filepath.Join(dir, "web.yaml")
+	if err := newApp().Run([]string{"go-i2ptunnel-config", "--extract-comments", "-o", yamlFile, input}); err != nil {
+		t.Fatalf("extract run error = %v", err)
+	}
+	sidecarFile := filepath.Join(dir, "web.meta.yaml")
+	sidecar, err := LoadCommentSidecar(sidecarFile)
+	if err != nil {
+		t.Fatalf("sidecar not written: %v", err)
+	}
+	if got := sidecar.Fields["port"]; !reflect.DeepEqual(got, []string{"local listener"}) {
+		t.Errorf("sidecar port comments = %v, want [local listener]", got)
+	}
+
+	iniFile := filepath.Join(dir, "web.conf")
+	if err := newApp().Run([]string{"go-i2ptunnel-config", "--out-format", "ini", "--apply-comments", sidecarFile, "-o", iniFile, yamlFile}); err != nil {
+		t.Fatalf("apply run error = %v", err)
+	}
+	data, err := os.ReadFile(iniFile)
+	if err != nil {
+		t.Fatalf("output not written: %v", err)
+	}
+	if !strings.Contains(string(data), "# local listener\nport = 4480\n") {
+		t.Errorf("INI output lacks the port comment:\n%s", data)
+	}
+	if !strings.HasPrefix(string(data), "# Web proxy for the office\n# maintained by ops\n\n") {
+		t.Errorf("INI output lacks the header:\n%s", data)
+	}
+
+	// A second extraction must not replace the sidecar without --force
+	other := filepath.Join(dir, "other.yaml")
+	err = newApp().Run([]string{"go-i2ptunnel-config", "--extract-comments", "-o", other, input})
+	if err != nil {
+		t.Fatalf("extract to another output error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "other.meta.yaml")); err != nil {
+		t.Errorf("sidecar for other output not written: %v", err)
+	}
+	iniOut := filepath.Join(dir, "web.ini")
+	err = newApp().Run([]string{"go-i2ptunnel-config", "--extract-comments", "--out-format", "ini", "-o", iniOut, input})
+	if err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("extract over existing sidecar error = %v, want already exists", err)
+	}
+	if _, err := os.Stat(iniOut); err == nil {
+		t.Error("output written although its comments file could not be")
+	}
+}
@@ -186,11 +186,11 @@ func TestYAMLParser(t *testing.T) {
 				Description:   "HTTP proxy tunnel",
 				PersistentKey: true,
 				I2CP: map[string]interface{}{
-					"leaseSetEncType": []interface{}{"4", "0"},
+					"leaseSetEncType": []string{"4", "0"},
 					"reduceIdleTime":  900000,
 				},
 				Tunnel: map[string]interface{}{
-					"proxyList":    "proxy1.i2p,proxy2.i2p",
+					"proxyList":    []string{"proxy1.i2p", "proxy2.i2p"},
 					"sharedClient": true,
 				},
 				Inbound: map[string]interface{}{
@@ -453,6 +453,7 @@ description=Load test tunnel`
 	})
 
 	t.Run("Options_and_SetOptions", func(t *testing.T) {
+		disabled := false
 		config := &TunnelConfig{
 			Name:          "OptionsTest",
 			Type:          "httpclient",
@@ -461,6 +462,8 @@ description=Load test tunnel`
 			Target:        "example.i2p",
 			PersistentKey: true,
 			Description:   "Options test tunnel",
+			Enabled:       &disabled,
+			Style:         "RAW",
 			I2CP: map[string]interface{}{
 				"leaseSetEncType": "4,0",
 			},
@@ -486,6 +489,8 @@ description=Load test tunnel`
 			"Target":               "example.i2p",
 			"PersistentKey":        "true",
 			"Description":          "Options test tunnel",
+			"Enabled":              "false",
+			"Style":                "RAW",
 			"I2CP.leaseSetEncType": "4,0",
 			"Tunnel.proxyList":     "proxy1.i2p",
 			"Inbound.length":       "3",
@@ -521,6 +526,12 @@ description=Load test tunnel`
 		if newConfig.PersistentKey != config.PersistentKey {
 			t.Errorf("SetOptions() PersistentKey = %t, want %t", newConfig.PersistentKey, config.PersistentKey)
 		}
+		if newConfig.Enabled == nil || *newConfig.Enabled {
+			t.Errorf("SetOptions() Enabled = %v, want false", newConfig.Enabled)
+		}
+		if newConfig.Style != config.Style {
+			t.Errorf("SetOptions() Style = %q, want %q", newConfig.Style, config.Style)
+		}
 
 		// Test SetOptions() with invalid port
 		invalidOptions := map[string]string{
@@ -600,6 +611,17 @@ func TestFormatDetection(t *testing.T) {
 			expectedFormat: "ini",
 			expectError:    false,
 		},
+		{
+			name:           "gzipped_config_extension",
+			filename:       "tunnel.config.gz",
+			expectedFormat: "properties",
+			expectError:    false,
+		},
+		{
+			name:        "gzip_without_inner_extension",
+			filename:    "tunnel.gz",
+			expectError: true,
+		},
 		{
 			name:        "unsupported_extension",
 			filename:    "tunnel.xml",
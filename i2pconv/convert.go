@@ -1,23 +1,46 @@
 package i2pconv
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/urfave/cli/v2"
+	"gopkg.in/yaml.v2"
 )
 
 // BatchResult represents the result of processing a single file in batch mode
 type BatchResult struct {
-	InputFile    string
-	OutputFile   string
-	InputFormat  string
-	OutputFormat string
-	Success      bool
-	Error        error
+	InputFile    string `json:"input"`
+	OutputFile   string `json:"output,omitempty"`
+	InputFormat  string `json:"inputFormat,omitempty"`
+	OutputFormat string `json:"outputFormat"`
+	TunnelType   string `json:"type,omitempty"`
+	Success      bool   `json:"success"`
+	Error        error  `json:"-"`
+
+	config *TunnelConfig // Parsed input, kept for checks across the batch
+}
+
+// MarshalJSON encodes the result with Error rendered as its message string,
+// omitted when the file was processed successfully.
+func (r BatchResult) MarshalJSON() ([]byte, error) {
+	type plain BatchResult
+	var msg string
+	if r.Error != nil {
+		msg = r.Error.Error()
+	}
+	return json.Marshal(struct {
+		plain
+		Error string `json:"error,omitempty"`
+	}{plain(r), msg})
 }
 
 // ProcessBatch processes multiple files using glob patterns and returns results for each file.
@@ -31,59 +54,302 @@ type BatchResult struct {
 //   - []BatchResult: Results for each processed file
 //   - error: Fatal error that prevented batch processing from starting
 func ProcessBatch(pattern string, c *cli.Context) ([]BatchResult, error) {
-	// Expand glob pattern to get list of files
-	files, err := filepath.Glob(pattern)
+	// Expand glob pattern (or walk the directory tree) to get list of files
+	files, err := expandBatchPattern(pattern, c.Bool("recursive"))
 	if err != nil {
-		return nil, fmt.Errorf("invalid glob pattern '%s': %w", pattern, err)
+		return nil, err
 	}
 
 	if len(files) == 0 {
 		return nil, fmt.Errorf("no files match pattern '%s'", pattern)
 	}
 
+	converter, err := converterFromContext(c)
+	if err != nil {
+		return nil, err
+	}
+	root := ""
+	if c.Bool("recursive") {
+		root = batchRoot(pattern)
+	}
+	return processBatchFiles(files, root, c, converter), nil
+}
+
+// processBatchFiles converts each file in files with converter and the
+// options in c, and returns one result per file, in order. Files are
+// processed one at a time; with --fail-fast the loop stops after the first
+// failure and only the files processed so far have results. In strict mode
+// client tunnels that bind the same interface and port are reported as
+// failures once every file has been processed. root is the
+// directory whose layout is mirrored under --output-dir, or empty to write
+// every output directly into it.
+func processBatchFiles(files []string, root string, c *cli.Context, converter *Converter) []BatchResult {
 	// Get flags
-	inputFormat := c.String("in-format")
-	outputFormat := c.String("out-format")
-	validateOnly := c.Bool("validate")
-	strict := c.Bool("strict")
-	dryRun := c.Bool("dry-run")
-	sam := c.Bool("sam")
-	keystore := c.String("keystore")
+	opts := processOptionsFromContext(c)
 
 	// Process each file individually
 	results := make([]BatchResult, 0, len(files))
-	converter := &Converter{strict: strict}
 
 	for _, inputFile := range files {
 		result := BatchResult{
 			InputFile:    inputFile,
-			OutputFormat: outputFormat,
+			OutputFormat: opts.outputFormat,
 		}
-
 		// Process single file using existing logic
-		err := processSingleFile(inputFile, "", inputFormat, outputFormat, validateOnly, dryRun, sam, keystore, converter)
+		outputFile := batchOutputFile(inputFile, root, opts)
+		var err error
+		if outputFile != "" && !opts.dryRun && !opts.validateOnly && !opts.canonicalCheck {
+			if mkErr := os.MkdirAll(filepath.Dir(outputFile), 0o755); mkErr != nil {
+				err = fmt.Errorf("failed to create output directory '%s': %w", filepath.Dir(outputFile), mkErr)
+			}
+		}
+		if err == nil {
+			result.config, err = processSingleFile(inputFile, outputFile, opts, converter)
+			if result.config != nil {
+				result.TunnelType = result.config.Type
+			}
+		}
 		if err != nil {
 			result.Success = false
 			result.Error = err
 		} else {
 			result.Success = true
-			result.OutputFile = generateOutputFilename(inputFile, outputFormat)
+			result.OutputFile = generateOutputFilename(inputFile, opts.outputFormat)
+			if outputFile != "" {
+				result.OutputFile = outputFile
+			}
+			if opts.inPlace || opts.normalize {
+				result.OutputFile = inputFile
+			}
 
 			// Detect input format for reporting
-			if inputFormat == "" {
+			if opts.inputFormat == "" {
 				detectedFormat, err := converter.DetectFormat(inputFile)
 				if err == nil {
 					result.InputFormat = detectedFormat
 				}
 			} else {
-				result.InputFormat = inputFormat
+				result.InputFormat = opts.inputFormat
 			}
 		}
 
 		results = append(results, result)
+
+		if err != nil && opts.failFast {
+			if skipped := len(files) - len(results); skipped > 0 {
+				fmt.Fprintf(os.Stderr, "⚠ Stopping at the first failure (--fail-fast); %d file(s) not processed\n", skipped)
+			}
+			break
+		}
 	}
 
-	return results, nil
+	if converter.strict {
+		checkBatchBindings(results)
+	}
+	return results
+}
+
+// batchOutputFile returns the output path a batch passes to processSingleFile
+// for inputFile: empty, so the output is named and placed next to the input,
+// unless --output-dir is set, in which case the same base name is joined to
+// that directory. When root is set, the input's directory relative to root is
+// kept in between, so "root/a/b/c.config" is written as "out/a/b/c.yaml".
+func batchOutputFile(inputFile, root string, opts processOptions) string {
+	if opts.outputDir == "" {
+		return ""
+	}
+	base := filepath.Base(generateOutputFilename(inputFile, opts.outputFormat))
+	if root != "" {
+		rel, err := filepath.Rel(root, filepath.Dir(inputFile))
+		if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return filepath.Join(opts.outputDir, rel, base)
+		}
+	}
+	return filepath.Join(opts.outputDir, base)
+}
+
+// batchRoot returns the directory a recursive batch pattern is walked from,
+// which is the pattern itself when it names a directory. Otherwise it is the
+// leading directories of the pattern up to the first one holding a glob or
+// brace group, so the alternatives of "tunnels/{a,b}" share the root "tunnels".
+func batchRoot(pattern string) string {
+	if info, err := os.Stat(pattern); err == nil && info.IsDir() {
+		return pattern
+	}
+	dir := filepath.Dir(pattern)
+	for strings.ContainsAny(dir, "*?[{") {
+		dir = filepath.Dir(dir)
+	}
+	return dir
+}
+
+// readFileList reads the input paths for --files-from from path, or from
+// stdin when path is "-". See splitFileList for the accepted delimiters.
+func readFileList(path string) ([]string, error) {
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file list '%s': %w", path, err)
+	}
+
+	files := splitFileList(data)
+	if len(files) == 0 {
+		return nil, fmt.Errorf("file list '%s' is empty", path)
+	}
+	for _, f := range files {
+		if f == "-" {
+			return nil, fmt.Errorf("file list '%s' cannot name stdin ('-')", path)
+		}
+	}
+	return files, nil
+}
+
+// splitFileList splits a list of paths. Input containing a NUL byte is treated
+// as NUL-delimited (find -print0), so paths may hold spaces and newlines.
+// Otherwise each line is a path; trailing carriage returns are removed. Empty
+// entries are skipped.
+func splitFileList(data []byte) []string {
+	sep, trim := "\n", "\r"
+	if bytes.IndexByte(data, 0) >= 0 {
+		sep, trim = "\x00", ""
+	}
+	var files []string
+	for _, f := range strings.Split(string(data), sep) {
+		if trim != "" {
+			f = strings.TrimSuffix(f, trim)
+		}
+		if f != "" {
+			files = append(files, f)
+		}
+	}
+	return files
+}
+
+// expandBatchPattern returns the input files selected by a --batch pattern.
+// Brace groups such as "{http,socks}" are expanded first, as a shell would,
+// and the matches of the resulting patterns are combined in order with
+// duplicates dropped.
+func expandBatchPattern(pattern string, recursive bool) ([]string, error) {
+	var files []string
+	seen := make(map[string]bool)
+	for _, p := range expandBraces(pattern) {
+		matches, err := expandGlobPattern(p, recursive)
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range matches {
+			if !seen[f] {
+				seen[f] = true
+				files = append(files, f)
+			}
+		}
+	}
+	return files, nil
+}
+
+// expandBraces expands the brace groups of pattern into the list of patterns
+// they stand for: "a{b,c}d" becomes "abd" and "acd". Groups may nest. A group
+// without a top-level comma, or an unmatched brace, is kept literally.
+func expandBraces(pattern string) []string {
+	depth, open := 0, -1
+	var commas []int
+	for i := 0; i < len(pattern); i++ {
+		switch pattern[i] {
+		case '{':
+			if depth == 0 {
+				open = i
+				commas = commas[:0]
+			}
+			depth++
+		case ',':
+			if depth == 1 {
+				commas = append(commas, i)
+			}
+		case '}':
+			if depth == 0 {
+				continue
+			}
+			depth--
+			if depth > 0 {
+				continue
+			}
+			if len(commas) == 0 {
+				// "{x}" is not a group; expand what follows it instead
+				var out []string
+				for _, rest := range expandBraces(pattern[i+1:]) {
+					out = append(out, pattern[:i+1]+rest)
+				}
+				return out
+			}
+			prefix, suffix := pattern[:open], pattern[i+1:]
+			bounds := append(append([]int{open}, commas...), i)
+			var out []string
+			for j := 0; j+1 < len(bounds); j++ {
+				alternative := pattern[bounds[j]+1 : bounds[j+1]]
+				out = append(out, expandBraces(prefix+alternative+suffix)...)
+			}
+			return out
+		}
+	}
+	return []string{pattern}
+}
+
+// expandGlobPattern returns the input files selected by a single pattern.
+// Without recursive the pattern is passed to filepath.Glob. With recursive the
+// pattern is either a root directory, in which case every file below it whose
+// extension maps to a known format is returned, or "dir/glob", in which case
+// dir is walked and each file's base name must also match glob.
+func expandGlobPattern(pattern string, recursive bool) ([]string, error) {
+	if !recursive {
+		files, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid glob pattern '%s': %w", pattern, err)
+		}
+		return files, nil
+	}
+
+	root, namePattern := pattern, ""
+	if info, err := os.Stat(pattern); err != nil || !info.IsDir() {
+		root, namePattern = filepath.Dir(pattern), filepath.Base(pattern)
+		if _, err := filepath.Match(namePattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid glob pattern '%s': %w", pattern, err)
+		}
+	}
+
+	detector := &Converter{}
+	var files []string
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// Skip unreadable directories and entries but keep walking.
+			fmt.Fprintf(os.Stderr, "⚠ Skipping '%s': %v\n", path, err)
+			if d != nil && d.IsDir() && path != root {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			return nil
+		}
+		if namePattern != "" {
+			if ok, _ := filepath.Match(namePattern, d.Name()); !ok {
+				return nil
+			}
+		}
+		if _, detectErr := detector.DetectFormat(path); detectErr != nil {
+			return nil
+		}
+		files = append(files, path)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk '%s': %w", root, err)
+	}
+	return files, nil
 }
 
 // reportBatchResults prints a summary of batch processing results and returns appropriate error.
@@ -139,6 +405,216 @@ func reportBatchResults(results []BatchResult, validateOnly, dryRun bool) error
 	return nil
 }
 
+// printWarnings prints validation warnings to stderr, one per line.
+func printWarnings(warnings []string) {
+	for _, w := range warnings {
+		fmt.Fprintf(os.Stderr, "⚠ %s\n", w)
+	}
+}
+
+// reportCanonicalResults prints, like gofmt -l, the files of a --canonical-check
+// batch that are not in canonical form, followed by the reasons on stderr.
+func reportCanonicalResults(results []BatchResult) error {
+	failed := 0
+	for _, result := range results {
+		if !result.Success {
+			failed++
+			fmt.Println(result.InputFile)
+			fmt.Fprintf(os.Stderr, "✗ %v\n", result.Error)
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d files are not in canonical form or failed to parse", failed, len(results))
+	}
+	return nil
+}
+
+// reportBatchTypes prints the number of successfully processed files per
+// tunnel type, most common first, e.g. "By type: 5 httpclient, 2 httpserver".
+// Files whose type could not be read are counted as "unknown".
+func reportBatchTypes(results []BatchResult) {
+	counts := make(map[string]int)
+	for _, result := range results {
+		if !result.Success {
+			continue
+		}
+		t := result.TunnelType
+		if t == "" {
+			t = "unknown"
+		}
+		counts[t]++
+	}
+	if len(counts) == 0 {
+		return
+	}
+
+	types := make([]string, 0, len(counts))
+	for t := range counts {
+		types = append(types, t)
+	}
+	sort.Slice(types, func(i, j int) bool {
+		if counts[types[i]] != counts[types[j]] {
+			return counts[types[i]] > counts[types[j]]
+		}
+		return types[i] < types[j]
+	})
+	parts := make([]string, len(types))
+	for i, t := range types {
+		parts[i] = fmt.Sprintf("%d %s", counts[t], t)
+	}
+	fmt.Printf("By type: %s\n", strings.Join(parts, ", "))
+}
+
+// writeBatchReportJSON writes results to w as an indented JSON array and
+// returns an error when any file failed, mirroring reportBatchResults.
+func writeBatchReportJSON(w io.Writer, results []BatchResult) error {
+	if err := encodeBatchReport(w, results); err != nil {
+		return err
+	}
+	failureCount := 0
+	for _, result := range results {
+		if !result.Success {
+			failureCount++
+		}
+	}
+	if failureCount > 0 {
+		return fmt.Errorf("%d of %d files failed processing", failureCount, len(results))
+	}
+	return nil
+}
+
+// encodeBatchReport writes results to w as an indented JSON array.
+func encodeBatchReport(w io.Writer, results []BatchResult) error {
+	data, err := json.MarshalIndent(results, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode batch report: %w", err)
+	}
+	if _, err := fmt.Fprintln(w, string(data)); err != nil {
+		return fmt.Errorf("failed to write batch report: %w", err)
+	}
+	return nil
+}
+
+// writeBatchReportFile writes the JSON batch report to path for --report-file,
+// leaving stdout to the normal summary. Failed files are reported by the
+// summary, so only errors writing the report itself are returned.
+func writeBatchReportFile(path string, results []BatchResult) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create report file '%s': %w", path, err)
+	}
+	if err := encodeBatchReport(f, results); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write report file '%s': %w", path, err)
+	}
+	return nil
+}
+
+// converterFromContext returns a Converter configured by the validation flags
+// in c, loading the --rules file when one is given.
+func converterFromContext(c *cli.Context) (*Converter, error) {
+	converter := &Converter{strict: c.Bool("strict"), warningsAsErrors: c.Bool("warnings-as-errors"), preserveEmptyMaps: c.Bool("preserve-empty-maps")}
+	if mode := strings.ToLower(c.String("canonical-types")); canonicalTypeModes[mode] {
+		converter.canonicalTypes = mode
+	} else {
+		return nil, fmt.Errorf("unsupported --canonical-types value '%s' (use java, i2pd, or none)", c.String("canonical-types"))
+	}
+	dialect := strings.ToLower(c.String("ini-dialect"))
+	if _, ok := iniDialects[dialect]; !ok {
+		return nil, fmt.Errorf("unsupported --ini-dialect value '%s' (use standard or prefixed)", c.String("ini-dialect"))
+	}
+	converter.iniDialect = dialect
+	if path := c.String("rules"); path != "" {
+		rules, err := LoadValidationRules(path)
+		if err != nil {
+			return nil, err
+		}
+		converter.rules = rules
+	}
+	return converter, nil
+}
+
+// processOptions holds the per-file settings shared by single-file and batch
+// processing. It is built once from the CLI flags and passed to processSingleFile.
+type processOptions struct {
+	inputFormat     string // Input format (empty string for auto-detection)
+	outputFormat    string // Output format
+	validateOnly    bool   // Only validate, do not convert
+	dryRun          bool   // Print output instead of writing to file
+	sam             bool   // Generate or load SAM I2P keys
+	keystore        string // Directory for SAM .keys files (empty uses current working directory)
+	mergeI2CPFrom   string // Config file whose i2cp options are merged into each tunnel
+	baseFile        string // Base template the input is laid over, the input's fields winning
+	name            string // Tunnel name that replaces the parsed one (empty keeps it)
+	inPlace         bool   // Write the converted output back over the input file
+	sortOutput      bool   // Sort set-like list values before generating output
+	backup          bool   // Rename an existing output file to a timestamped .bak first
+	force           bool   // Allow overwriting an existing output file
+	minimal         bool   // Drop options that match the router defaults
+	probeRouter     string // SAM bridge address used to test-create the tunnel
+	verify          bool   // Re-parse the generated output and compare it to the config
+	lineEndings     string // Newline style of the output: "lf" (default) or "crlf"
+	confirmFormat   bool   // Check --in-format against the extension and content
+	schemaFile      string // JSON Schema the parsed config must satisfy
+	humanize        bool   // Render duration options as "15m" instead of milliseconds
+	extractComments bool   // Write the input's comments to a .meta.yaml sidecar
+	applyComments   string // Sidecar whose comments are written into the output
+	normalize       bool   // Re-emit the input in its own format with defaults and canonical values
+	canonicalCheck  bool   // Fail unless the input already equals its canonical form
+	noKeyWarning    bool   // Do not warn about private keys embedded in the config
+	stats           bool   // Print the field and option counts of each parsed input
+	failFast        bool   // Stop a batch at the first file that fails
+	outputDir       string // Directory a batch writes its converted files into (empty writes next to each input)
+	reportJSON      bool   // A batch prints its results as JSON on stdout
+}
+
+// processOptionsFromContext reads the flags used by processSingleFile from c.
+func processOptionsFromContext(c *cli.Context) processOptions {
+	return processOptions{
+		inputFormat:     c.String("in-format"),
+		outputFormat:    c.String("out-format"),
+		validateOnly:    c.Bool("validate"),
+		dryRun:          c.Bool("dry-run"),
+		sam:             c.Bool("sam"),
+		keystore:        c.String("keystore"),
+		mergeI2CPFrom:   c.String("merge-i2cp-from"),
+		baseFile:        c.String("base"),
+		name:            c.String("name"),
+		inPlace:         c.Bool("in-place"),
+		sortOutput:      c.Bool("sort-output"),
+		backup:          c.Bool("backup"),
+		force:           c.Bool("force"),
+		minimal:         c.Bool("minimal"),
+		probeRouter:     c.String("probe-router"),
+		verify:          c.Bool("verify"),
+		lineEndings:     c.String("line-endings"),
+		confirmFormat:   c.Bool("confirm-format"),
+		schemaFile:      c.String("schema-validate"),
+		humanize:        c.Bool("humanize-durations"),
+		extractComments: c.Bool("extract-comments"),
+		applyComments:   c.String("apply-comments"),
+		normalize:       c.Bool("normalize"),
+		canonicalCheck:  c.Bool("canonical-check"),
+		noKeyWarning:    c.Bool("no-key-warning"),
+		stats:           c.Bool("stats"),
+		failFast:        c.Bool("fail-fast"),
+		outputDir:       c.String("output-dir"),
+		reportJSON:      c.Bool("report-json"),
+	}
+}
+
+// dryRunOutput returns where --dry-run prints its preview: stdout, unless a
+// batch's --report-json needs stdout to hold nothing but the JSON report.
+func dryRunOutput(opts processOptions) io.Writer {
+	if opts.reportJSON {
+		return os.Stderr
+	}
+	return os.Stdout
+}
+
 // processSingleFile handles the conversion of a single file with the given parameters.
 // This function contains the core conversion logic extracted from ConvertCommand to enable reuse
 // in both single-file and batch processing modes.
@@ -146,35 +622,39 @@ func reportBatchResults(results []BatchResult, validateOnly, dryRun bool) error
 // Parameters:
 //   - inputFile: Path to input configuration file
 //   - outputFile: Path for output file (empty string for auto-generation)
-//   - inputFormat: Input format (empty string for auto-detection)
-//   - outputFormat: Output format
-//   - validateOnly: Whether to only validate without conversion
-//   - dryRun: Whether to print output instead of writing to file
-//   - sam: Whether to generate or load SAM I2P keys
-//   - keystore: Directory for SAM .keys files (empty string uses current working directory)
+//   - opts: Format, mode, and SAM settings taken from the command-line flags
 //   - converter: Converter instance with configuration
 //
 // Returns:
+//   - config: The parsed tunnel configuration, or nil if the input could not be
+//     read and parsed; it is returned alongside any later error
 //   - error: Any error that occurred during processing
-func processSingleFile(inputFile, outputFile, inputFormat, outputFormat string, validateOnly, dryRun, sam bool, keystore string, converter *Converter) error {
+func processSingleFile(inputFile, outputFile string, opts processOptions, converter *Converter) (config *TunnelConfig, err error) {
+	inputFormat, outputFormat := opts.inputFormat, opts.outputFormat
+	validateOnly, dryRun := opts.validateOnly, opts.dryRun
 	// Read input: treat "-" as stdin
 	var inputData []byte
-	var err error
 	if inputFile == "-" {
 		if inputFormat == "" {
-			return fmt.Errorf("reading from stdin requires --in-format")
+			return config, fmt.Errorf("reading from stdin requires --in-format")
 		}
 		if !dryRun && outputFile == "" {
-			return fmt.Errorf("reading from stdin requires --output when not using --dry-run")
+			return config, fmt.Errorf("reading from stdin requires --output when not using --dry-run")
 		}
 		inputData, err = io.ReadAll(os.Stdin)
 		if err != nil {
-			return fmt.Errorf("failed to read from stdin: %w", err)
+			return config, fmt.Errorf("failed to read from stdin: %w", err)
 		}
 	} else {
-		inputData, err = os.ReadFile(inputFile)
+		inputData, err = readInputFile(inputFile)
 		if err != nil {
-			return fmt.Errorf("failed to read input file '%s': %w", inputFile, err)
+			return config, fmt.Errorf("failed to read input file '%s': %w", inputFile, err)
+		}
+	}
+
+	if opts.confirmFormat && inputFormat != "" {
+		if err := confirmDeclaredFormat(inputFile, inputData, inputFormat, converter); err != nil {
+			return config, err
 		}
 	}
 
@@ -182,49 +662,377 @@ func processSingleFile(inputFile, outputFile, inputFormat, outputFormat string,
 	if inputFormat == "" {
 		inputFormat, err = converter.DetectFormat(inputFile)
 		if err != nil {
-			return fmt.Errorf("failed to detect input format for '%s': %w (try specifying --in-format)", inputFile, err)
+			return config, fmt.Errorf("failed to detect input format for '%s': %w (try specifying --in-format)", inputFile, err)
+		}
+	}
+
+	// --normalize rewrites the input in its own format, in place unless an
+	// output file or --dry-run is given
+	if opts.normalize {
+		outputFormat = inputFormat
+		if outputFile == "" && !dryRun {
+			opts.inPlace = true
 		}
 	}
 
 	// Parse input configuration
-	config, err := converter.ParseInput(inputData, inputFormat)
+	config, err = converter.ParseInput(inputData, inputFormat)
 	if err != nil {
-		return fmt.Errorf("failed to parse %s input from '%s': %w", inputFormat, inputFile, err)
+		return nil, fmt.Errorf("failed to parse %s input from '%s': %w", inputFormat, inputFile, err)
 	}
 
 	warnIfMultiTunnel(inputData, inputFormat, inputFile, config.Name)
+	if !converter.strict {
+		warnDuplicateKeys(inputData, inputFormat, inputFile)
+	}
+	if opts.stats {
+		fmt.Fprintf(os.Stderr, "ℹ Stats for '%s': %s\n", inputFile, countConfig(config))
+	}
+
+	if opts.baseFile != "" {
+		if config, err = applyBaseConfig(config, opts.baseFile, converter); err != nil {
+			return config, err
+		}
+	}
+
+	if opts.name != "" {
+		config.Name = opts.name
+	}
+
+	if opts.mergeI2CPFrom != "" {
+		if err := mergeSharedI2CP(config, opts.mergeI2CPFrom, converter); err != nil {
+			return config, err
+		}
+	}
 
 	// Validate configuration
-	if err := converter.validateWithFormat(config, inputFormat); err != nil {
-		return fmt.Errorf("validation error in '%s': %w", inputFile, err)
+	warnings, err := converter.validateWithFormat(config, inputFormat)
+	if err != nil {
+		return config, fmt.Errorf("validation error in '%s': %w", inputFile, err)
+	}
+	printWarnings(warnings)
+	if opts.schemaFile != "" {
+		if err := validateAgainstSchema(config, opts.schemaFile); err != nil {
+			return config, fmt.Errorf("validation error in '%s': %w", inputFile, err)
+		}
+	}
+
+	if opts.probeRouter != "" {
+		if err := ProbeRouter(config, opts.probeRouter); err != nil {
+			return config, fmt.Errorf("router probe failed for '%s': %w", inputFile, err)
+		}
+		fmt.Fprintf(os.Stderr, "✓ Router at %s accepted tunnel '%s'\n", opts.probeRouter, config.Name)
+	}
+
+	if opts.canonicalCheck {
+		return config, checkCanonical(config, inputData, inputFile, inputFormat, opts.lineEndings, converter)
 	}
 
 	// If validate-only mode, we're done
 	if validateOnly {
-		return nil
+		return config, nil
+	}
+
+	warnings, err = converter.checkConversion(config, inputFormat, outputFormat)
+	if err != nil {
+		return config, fmt.Errorf("cannot convert '%s': %w", inputFile, err)
+	}
+	printWarnings(warnings)
+	if !opts.noKeyWarning {
+		if err := checkPrivateKeys(config); err != nil {
+			fmt.Fprintf(os.Stderr, "⚠ WARNING: %v (silence with --no-key-warning)\n", err)
+		}
+	}
+
+	if opts.minimal {
+		stripDefaults(config, outputFormat)
+	}
+	if opts.normalize {
+		normalizeConfig(config, outputFormat)
+	}
+	if opts.sortOutput {
+		sortOptionLists(config)
+	}
+
+	var sidecar *CommentSidecar
+	if opts.applyComments != "" {
+		if sidecar, err = LoadCommentSidecar(opts.applyComments); err != nil {
+			return config, err
+		}
+	}
+
+	// Generate output. Durations are humanized on a copy so verification
+	// still compares against the millisecond values. The sidecar's comments
+	// replace any the generator would carry over from the input.
+	outConfig := config
+	if opts.humanize || sidecar != nil {
+		outConfig = config.Clone()
+	}
+	if opts.humanize {
+		humanizeDurations(outConfig)
+	}
+	if sidecar != nil {
+		outConfig.Comments = nil
+	}
+	outputData, err := converter.generateOutput(outConfig, outputFormat)
+	if err != nil {
+		return config, fmt.Errorf("failed to generate %s output: %w", outputFormat, err)
+	}
+	outputData = sidecar.Apply(outputData, outputFormat)
+
+	if opts.verify {
+		if err := verifyOutput(config, outputData, outputFormat, converter); err != nil {
+			return config, fmt.Errorf("verification failed for '%s': %w", inputFile, err)
+		}
 	}
 
-	// Generate output
-	outputData, err := converter.generateOutput(config, outputFormat)
+	outputData, err = applyLineEndings(outputData, opts.lineEndings)
 	if err != nil {
-		return fmt.Errorf("failed to generate %s output: %w", outputFormat, err)
+		return config, err
 	}
 
 	if dryRun {
-		return printDryRunOutput(config, outputData, inputFile, inputFormat, outputFormat, sam)
+		if opts.inPlace {
+			if inputFile == "-" {
+				return config, fmt.Errorf("--in-place cannot be used when reading from stdin")
+			}
+			printDryRunDiff(dryRunOutput(opts), inputData, outputData, inputFile, inputFormat, outputFormat)
+			return config, nil
+		}
+		return config, printDryRunOutput(dryRunOutput(opts), config, outputData, inputFile, inputFormat, outputFormat, opts.sam)
 	}
 
 	// Determine output file name if not specified
-	if outputFile == "" {
+	if opts.inPlace {
+		if inputFile == "-" {
+			return config, fmt.Errorf("--in-place cannot be used when reading from stdin")
+		}
+		if isGzipPath(inputFile) {
+			return config, fmt.Errorf("--in-place cannot rewrite compressed input '%s'; give an output file instead", inputFile)
+		}
+		outputFile = inputFile
+	} else if outputFile == "" {
 		outputFile = generateOutputFilename(inputFile, outputFormat)
 	}
 
+	// Refuse to clobber an existing file unless the user opted in. --in-place
+	// always overwrites its own input, and --backup keeps the old content.
+	if !opts.inPlace && !opts.force && !opts.backup {
+		if _, err := os.Stat(outputFile); err == nil {
+			return config, fmt.Errorf("output file '%s' already exists, use --force to overwrite", outputFile)
+		}
+	}
+
+	if opts.extractComments && !opts.force {
+		path := commentSidecarPath(outputFile)
+		if _, err := os.Stat(path); err == nil {
+			return config, fmt.Errorf("comments file '%s' already exists, use --force to overwrite", path)
+		}
+	}
+
+	if opts.backup {
+		if _, err := backupExistingFile(outputFile); err != nil {
+			return config, err
+		}
+	}
+
 	// Write output file
 	if err := os.WriteFile(outputFile, outputData, 0o644); err != nil {
-		return fmt.Errorf("failed to write output file '%s': %w", outputFile, err)
+		return config, fmt.Errorf("failed to write output file '%s': %w", outputFile, err)
 	}
 
-	return applyOrReportSAMKeys(config, inputFile, keystore, sam)
+	if opts.extractComments {
+		if err := writeExtractedComments(config, inputFile, outputFile); err != nil {
+			return config, err
+		}
+	}
+
+	return config, applyOrReportSAMKeys(config, inputFile, opts.keystore, opts.sam)
+}
+
+// commentSidecarPath returns the sidecar written by --extract-comments for
+// outputFile: the same path with ".meta.yaml" in place of its extension.
+func commentSidecarPath(outputFile string) string {
+	return strings.TrimSuffix(outputFile, filepath.Ext(outputFile)) + ".meta.yaml"
+}
+
+// writeExtractedComments writes the comments of config to the sidecar of
+// outputFile.
+func writeExtractedComments(config *TunnelConfig, inputFile, outputFile string) error {
+	sidecar := ExtractComments(config)
+	if sidecar == nil {
+		fmt.Fprintf(os.Stderr, "ℹ No comments to extract from '%s'\n", inputFile)
+		return nil
+	}
+	path := commentSidecarPath(outputFile)
+	if err := WriteCommentSidecar(sidecar, path); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "✓ Wrote comments of '%s' to %s\n", inputFile, path)
+	return nil
+}
+
+// applyLineEndings converts the LF newlines written by the generators to the
+// requested style. An empty style or "lf" leaves data unchanged.
+func applyLineEndings(data []byte, style string) ([]byte, error) {
+	switch strings.ToLower(style) {
+	case "", "lf":
+		return data, nil
+	case "crlf":
+		return bytes.ReplaceAll(data, []byte("\n"), []byte("\r\n")), nil
+	default:
+		return nil, fmt.Errorf("unsupported line ending style '%s' (use lf or crlf)", style)
+	}
+}
+
+// generatorAddedFields lists paths a generator may fill in on its own, such
+// as the key file name the INI generator derives for persistent keys. They are
+// not reported by verifyOutput when only the re-parsed config has them.
+var generatorAddedFields = map[string]bool{
+	"options.keyfile": true,
+}
+
+// verifyOutput re-parses outputData as format and compares the result with
+// config, returning an error listing every field that did not survive. It
+// guards against generators that write output the parser cannot read back.
+func verifyOutput(config *TunnelConfig, outputData []byte, format string, converter *Converter) error {
+	reparsed, err := converter.ParseInput(outputData, format)
+	if err != nil {
+		return fmt.Errorf("generated %s output does not parse: %w", format, err)
+	}
+	if format == "ini" {
+		// i2pd reads a server's target host and port back as one address
+		if address, combined := iniServerAddress(config); combined {
+			config = config.Clone()
+			config.Target = address
+			delete(config.Tunnel, "targetPort")
+		}
+	}
+	if format == "properties" && TunnelType(config.Type).IsServer() {
+		// Java I2P reads a server's "host:port" target back as host and port
+		if host, port, split := propertiesServerTarget(config); split {
+			config = config.Clone()
+			config.Target = host
+			if config.Tunnel == nil {
+				config.Tunnel = make(map[string]interface{})
+			}
+			config.Tunnel["targetPort"] = port
+		}
+	}
+	var lines []string
+	for _, d := range Diff(config, reparsed) {
+		if d.A == nil && generatorAddedFields[d.Path] {
+			continue
+		}
+		lines = append(lines, "  "+d.String())
+	}
+	if len(lines) == 0 {
+		return nil
+	}
+	return fmt.Errorf("generated %s output does not match the parsed config:\n%s", format, strings.Join(lines, "\n"))
+}
+
+// normalizeConfig puts config in the normalized form --normalize writes for
+// format: every router default made explicit and list options joined into
+// one comma-separated value.
+func normalizeConfig(config *TunnelConfig, format string) {
+	fillDefaults(config, format)
+	joinOptionLists(config)
+}
+
+// canonicalOutput renders config in its canonical form for format: normalized
+// as by normalizeConfig and everything else as the generator writes it, which
+// fixes key order, boolean spelling, and the format's own type names. It is
+// what --normalize writes, so a normalized file passes --canonical-check.
+func canonicalOutput(config *TunnelConfig, format string, converter *Converter) ([]byte, error) {
+	canonical := config.Clone()
+	normalizeConfig(canonical, format)
+	return converter.generateOutput(canonical, format)
+}
+
+// checkCanonical returns an error naming the first differing line when
+// inputData is not the canonical form of config, as gofmt -l does for Go
+// source. The section headers generated in properties output are not
+// required. Canonical form is what --normalize writes, so the router defaults
+// of the format must be spelled out: rather than rejecting redundant defaults,
+// the check rejects a config that omits them.
+func checkCanonical(config *TunnelConfig, inputData []byte, inputFile, format, lineEndings string, converter *Converter) error {
+	want, err := canonicalOutput(config, format, converter)
+	if err != nil {
+		return fmt.Errorf("failed to generate canonical %s output for '%s': %w", format, inputFile, err)
+	}
+	if want, err = applyLineEndings(want, lineEndings); err != nil {
+		return err
+	}
+	line, got, exp, differ := firstDifferentLine(inputData, want, format)
+	if !differ {
+		return nil
+	}
+	return fmt.Errorf("'%s' is not in canonical form: line %d is %q, want %q (rewrite it with --normalize)", inputFile, line, got, exp)
+}
+
+// canonicalLines splits data into lines, each with its 1-based line number.
+// For properties the generated section headers and the blank lines are left
+// out: they only lay the file out.
+func canonicalLines(data []byte, format string) ([]string, []int) {
+	var lines []string
+	var numbers []int
+	for i, line := range strings.SplitAfter(string(data), "\n") {
+		if format == "properties" {
+			text := strings.TrimRight(line, "\r\n")
+			if strings.TrimSpace(text) == "" || propertiesSectionHeaders[text] {
+				continue
+			}
+		}
+		lines = append(lines, line)
+		numbers = append(numbers, i+1)
+	}
+	return lines, numbers
+}
+
+// firstDifferentLine compares the lines canonicalLines keeps from a and b. It
+// returns the 1-based number in a of the first line that differs, with that
+// line from each; a missing line is returned as "". The last result is false
+// when the lines are the same.
+func firstDifferentLine(a, b []byte, format string) (int, string, string, bool) {
+	linesA, numbers := canonicalLines(a, format)
+	linesB, _ := canonicalLines(b, format)
+	for i := 0; i < len(linesA) || i < len(linesB); i++ {
+		var la, lb string
+		if i < len(linesA) {
+			la = linesA[i]
+		}
+		if i < len(linesB) {
+			lb = linesB[i]
+		}
+		if la == lb {
+			continue
+		}
+		line := len(strings.SplitAfter(string(a), "\n"))
+		if i < len(numbers) {
+			line = numbers[i]
+		}
+		return line, strings.TrimRight(la, "\r\n"), strings.TrimRight(lb, "\r\n"), true
+	}
+	return 0, "", "", false
+}
+
+// backupExistingFile renames the file at path to "<path>.<RFC3339 timestamp>.bak"
+// when it exists, returning the backup path. It returns an empty path and no
+// error when there is nothing to back up.
+func backupExistingFile(path string) (string, error) {
+	if _, err := os.Stat(path); err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to check output file '%s': %w", path, err)
+	}
+	backupPath := path + "." + time.Now().Format(time.RFC3339) + ".bak"
+	if err := os.Rename(path, backupPath); err != nil {
+		return "", fmt.Errorf("failed to back up '%s': %w", path, err)
+	}
+	fmt.Fprintf(os.Stderr, "ℹ Backed up '%s' to '%s'\n", path, backupPath)
+	return backupPath, nil
 }
 
 // warnIfMultiTunnel prints a warning to stderr when the input contains more than
@@ -245,11 +1053,75 @@ func warnIfMultiTunnel(inputData []byte, format, inputFile, tunnelName string) {
 	}
 }
 
-// printDryRunOutput prints converted output to stdout and, when SAM keys are
+// warnDuplicateKeys prints a warning to stderr for each key that is defined
+// twice in INI (within one section) or properties input. The parsers keep the
+// later value; strict mode rejects such input instead.
+func warnDuplicateKeys(inputData []byte, format, inputFile string) {
+	var dups []duplicateKey
+	switch format {
+	case "ini":
+		dups = findDuplicateINIKeys(inputData)
+	case "properties":
+		dups = findDuplicatePropertyKeys(inputData)
+	}
+	for _, d := range dups {
+		fmt.Fprintf(os.Stderr, "⚠ '%s' line %d: duplicate key '%s' (first defined on line %d); the later value is used\n",
+			inputFile, d.Line, d.Key, d.FirstLine)
+	}
+}
+
+// mergeSharedI2CP reads the i2cp options from the config file at path and adds
+// them to config.I2CP. Options already set on config take precedence, so a
+// shared block only fills in settings the tunnel does not define itself.
+func mergeSharedI2CP(config *TunnelConfig, path string, converter *Converter) error {
+	shared, err := readI2CPOptions(path, converter)
+	if err != nil {
+		return fmt.Errorf("failed to merge i2cp options from '%s': %w", path, err)
+	}
+	if config.I2CP == nil {
+		config.I2CP = make(map[string]interface{})
+	}
+	for k, v := range shared {
+		if _, exists := config.I2CP[k]; !exists {
+			config.I2CP[k] = v
+		}
+	}
+	return nil
+}
+
+// readI2CPOptions returns the i2cp options defined in the config file at path.
+// The format is detected from the extension, and a .gz file is decompressed
+// first. YAML files may either be a full
+// go-i2p tunnels document or a bare document with a top-level "i2cp" map.
+func readI2CPOptions(path string, converter *Converter) (map[string]interface{}, error) {
+	data, err := readInputFile(path)
+	if err != nil {
+		return nil, err
+	}
+	format, err := converter.DetectFormat(path)
+	if err != nil {
+		return nil, err
+	}
+	if format == "yaml" {
+		var bare struct {
+			I2CP map[string]interface{} `yaml:"i2cp"`
+		}
+		if err := yaml.Unmarshal(data, &bare); err == nil && len(bare.I2CP) > 0 {
+			return bare.I2CP, nil
+		}
+	}
+	config, err := converter.ParseInput(data, format)
+	if err != nil {
+		return nil, err
+	}
+	return config.I2CP, nil
+}
+
+// printDryRunOutput prints converted output to w and, when SAM keys are
 // relevant, prints the SAM options without writing any key files.
-func printDryRunOutput(config *TunnelConfig, outputData []byte, inputFile, inputFormat, outputFormat string, sam bool) error {
-	fmt.Printf("# Converted '%s' from %s to %s format:\n", inputFile, inputFormat, outputFormat)
-	fmt.Println(string(outputData))
+func printDryRunOutput(w io.Writer, config *TunnelConfig, outputData []byte, inputFile, inputFormat, outputFormat string, sam bool) error {
+	fmt.Fprintf(w, "# Converted '%s' from %s to %s format:\n", inputFile, inputFormat, outputFormat)
+	fmt.Fprintln(w, string(outputData))
 	if sam || config.PersistentKey {
 		cfgCopy := *config
 		cfgCopy.PersistentKey = false
@@ -257,14 +1129,26 @@ func printDryRunOutput(config *TunnelConfig, outputData []byte, inputFile, input
 		if samErr != nil {
 			return fmt.Errorf("failed to compute SAM options for '%s': %w", inputFile, samErr)
 		}
-		fmt.Printf("# SAM options for '%s':\n", config.Name)
+		fmt.Fprintf(w, "# SAM options for '%s':\n", config.Name)
 		for _, opt := range opts {
-			fmt.Printf("  %s\n", opt)
+			fmt.Fprintf(w, "  %s\n", opt)
 		}
 	}
 	return nil
 }
 
+// printDryRunDiff prints, for --in-place --dry-run, a unified diff from the
+// current contents of inputFile to the output that would replace them, to w.
+func printDryRunDiff(w io.Writer, inputData, outputData []byte, inputFile, inputFormat, outputFormat string) {
+	diff := unifiedDiff(inputFile, inputFile+" (converted)", inputData, outputData)
+	if diff == "" {
+		fmt.Fprintf(w, "# '%s' would be unchanged (%s -> %s)\n", inputFile, inputFormat, outputFormat)
+		return
+	}
+	fmt.Fprintf(w, "# Changes --in-place would make to '%s' (%s -> %s):\n", inputFile, inputFormat, outputFormat)
+	fmt.Fprint(w, diff)
+}
+
 // applyOrReportSAMKeys generates or loads SAM keys for the tunnel when requested
 // or when the tunnel has persistentKey set, and reports the key file path.
 func applyOrReportSAMKeys(config *TunnelConfig, inputFile, keystore string, sam bool) error {
@@ -303,8 +1187,40 @@ func applyOrReportSAMKeys(config *TunnelConfig, inputFile, keystore string, sam
 //   - output: Output file path - takes precedence over positional output-file argument
 //   - validate: Validate input without performing conversion
 //   - strict: Enable strict validation of the configuration
+//   - rules: YAML or JSON file of extra validation rules by tunnel type
+//   - schema-validate: JSON Schema file the parsed config must satisfy before conversion
+//   - warnings-as-errors: Fail on strict-mode warnings such as a privileged port
 //   - dry-run: Print output to console instead of writing to file
 //   - batch: Process multiple files using glob patterns
+//   - recursive: With batch, walk a directory tree instead of a single glob
+//   - files-from: Read input paths from a file, or stdin with "-", instead of a pattern (NUL or newline delimited)
+//   - report-file: With batch, also write the JSON results to this file
+//   - fail-fast: With batch, stop at the first failing file and report only the files processed so far
+//   - group-by-type: With batch, add a count of converted files per tunnel type to the summary
+//   - generate-keys: With split, write a key file next to each persistent tunnel's output
+//   - humanize-durations: Write duration options such as reduceIdleTime as "15m" instead of milliseconds
+//   - extract-comments: Also write the input's comments to a <output>.meta.yaml sidecar
+//   - apply-comments: Write the comments of a .meta.yaml sidecar into the output
+//   - list-types: Print the supported tunnel types and their required fields, then exit
+//   - count-tunnels: Print how many tunnels the input contains without converting
+//   - quiet: With count-tunnels, print only the number
+//   - count-only: Same as count-tunnels with quiet
+//   - merge: Combine every input file into one multi-tunnel YAML document
+//   - in-place: Overwrite the input file with the converted output
+//   - normalize: Rewrite the input in its own format with explicit defaults and canonical values
+//   - canonical-check: Fail unless the input is already in canonical form, defaults included, without writing anything
+//   - stats: Print the number of fields set and options per map for each input, in single and batch mode
+//   - preserve-empty-maps: Write empty i2cp/options/inbound/outbound maps to YAML as "{}" instead of omitting them
+//   - no-key-warning: Do not warn when the config embeds private keys such as i2cp.leaseSetPrivateKey
+//   - force: Overwrite an existing output file
+//   - confirm-format: Fail early when --in-format disagrees with the file extension or content
+//   - line-endings: Newline style of generated files (lf|crlf) - defaults to lf
+//   - ini-dialect: Key convention of INI tunnel pool options, standard (inbound.length) or prefixed (tunnels.inbound.length)
+//   - canonical-types: Write Java I2P (java) or i2pd (i2pd) type names in every format, or the type as parsed (none)
+//   - verify: Re-parse the generated output and fail if it differs from the input config
+//   - name: Tunnel name written instead of the one in the input (single-file mode only)
+//   - base: Base template config the input is laid over; the input's fields and option keys win
+//   - probe-router: SAM bridge address used to check that the router accepts the tunnel
 //
 // Returns:
 //   - error: An error if any step fails, including argument validation, file I/O,
@@ -319,6 +1235,7 @@ func applyOrReportSAMKeys(config *TunnelConfig, inputFile, keystore string, sam
 //
 // Batch Processing:
 //   - When --batch flag is used, the input argument is treated as a glob pattern
+//   - With --files-from, the paths are read from a file or stdin instead
 //   - Multiple files are processed independently with individual success/failure reporting
 //   - Processing continues even if some files fail
 //
@@ -326,8 +1243,19 @@ func applyOrReportSAMKeys(config *TunnelConfig, inputFile, keystore string, sam
 //   - Converter.DetectFormat, Converter.ParseInput, Converter.validate, Converter.generateOutput
 //   - ProcessBatch, processSingleFile
 func ConvertCommand(c *cli.Context) error {
+	if c.Bool("list-types") {
+		listTunnelTypes()
+		return nil
+	}
+
+	if c.Bool("schema") {
+		return printSchema(os.Stdout)
+	}
+
+	filesFrom := c.String("files-from")
+
 	// Validate required arguments
-	if c.NArg() < 1 {
+	if c.NArg() < 1 && filesFrom == "" {
 		return fmt.Errorf("input file is required\nUsage: %s <input-file> [output-file]", c.App.Name)
 	}
 
@@ -339,11 +1267,8 @@ func ConvertCommand(c *cli.Context) error {
 	outputFormat := c.String("out-format")
 	outputFlag := c.String("output")
 	validateOnly := c.Bool("validate")
-	strict := c.Bool("strict")
 	dryRun := c.Bool("dry-run")
 	batchMode := c.Bool("batch")
-	sam := c.Bool("sam")
-	keystore := c.String("keystore")
 	split := c.Bool("split")
 	listTunnels := c.Bool("list-tunnels")
 
@@ -352,41 +1277,145 @@ func ConvertCommand(c *cli.Context) error {
 		outputFile = outputFlag
 	}
 
-	// --split / --list-tunnels mode: operate on all tunnels in the input file
-	if split || listTunnels {
-		converter := &Converter{strict: strict}
+	if c.Bool("in-place") {
+		if outputFile != "" {
+			return fmt.Errorf("--in-place cannot be combined with an output file")
+		}
+	}
+
+	if c.Bool("canonical-check") && (c.Bool("normalize") || c.Bool("in-place") || c.Bool("dry-run")) {
+		return fmt.Errorf("--canonical-check only reads its input and cannot be combined with --normalize, --in-place, or --dry-run")
+	}
+
+	if c.Bool("normalize") {
+		if c.Bool("minimal") {
+			return fmt.Errorf("--normalize makes defaults explicit and cannot be combined with --minimal")
+		}
+		if c.IsSet("out-format") {
+			return fmt.Errorf("--normalize keeps the input format and cannot be combined with --out-format")
+		}
+	}
+
+	// --count-only is shorthand for --count-tunnels --quiet
+	countMode := c.Bool("count-tunnels") || c.Bool("count-only")
+	if c.Bool("quiet") && !countMode {
+		return fmt.Errorf("--quiet only applies to --count-tunnels")
+	}
+
+	if c.IsSet("name") {
+		if batchMode || filesFrom != "" || split || c.Bool("merge") {
+			return fmt.Errorf("--name renames a single tunnel and cannot be used with --batch, --files-from, --split, or --merge, where tunnel names must stay unique")
+		}
+		if c.String("name") == "" {
+			return fmt.Errorf("--name must not be empty")
+		}
+		if err := checkTunnelName(c.String("name")); err != nil {
+			return fmt.Errorf("invalid --name: %w", err)
+		}
+	}
+
+	if _, err := applyLineEndings(nil, c.String("line-endings")); err != nil {
+		return err
+	}
+
+	converter, err := converterFromContext(c)
+	if err != nil {
+		return err
+	}
+
+	// --merge mode: every positional argument is an input file
+	if c.Bool("merge") {
+		if outputFlag == "" && !dryRun {
+			return fmt.Errorf("--merge requires --output when not using --dry-run")
+		}
+		return mergeTunnelFiles(c.Args().Slice(), outputFlag, inputFormat, dryRun, c.Bool("force"), converter)
+	}
+
+	// --split / --list-tunnels / --count-tunnels mode: operate on all tunnels in the input file
+	if split || listTunnels || countMode {
+		if countMode {
+			return countTunnels(inputArg, inputFormat, c.Bool("quiet") || c.Bool("count-only"), converter)
+		}
 		if listTunnels {
 			return listTunnelNames(inputArg, inputFormat, converter)
 		}
-		return writeSplitTunnels(inputArg, inputFormat, outputFormat, dryRun, converter)
+		// In split mode the output path names a directory for the per-tunnel files
+		return writeSplitTunnels(inputArg, inputFormat, outputFormat, outputFile, dryRun, c.Bool("force"), c.Bool("generate-keys"), converter)
+	}
+
+	outputDir := c.String("output-dir")
+	if outputDir != "" && !batchMode && filesFrom == "" {
+		return fmt.Errorf("--output-dir is only used in batch mode; use --output for a single file")
 	}
 
 	// Check for incompatible options in batch mode
-	if batchMode {
+	if batchMode || filesFrom != "" {
 		if outputFile != "" {
 			return fmt.Errorf("cannot specify output file in batch mode - files are auto-generated")
 		}
+		if outputDir != "" {
+			if c.Bool("in-place") || c.Bool("normalize") {
+				return fmt.Errorf("--output-dir cannot be combined with --in-place or --normalize, which rewrite each input")
+			}
+			if !dryRun && !validateOnly && !c.Bool("canonical-check") {
+				if err := os.MkdirAll(outputDir, 0o755); err != nil {
+					return fmt.Errorf("failed to create output directory '%s': %w", outputDir, err)
+				}
+			}
+		}
 
-		// Process batch
-		results, err := ProcessBatch(inputArg, c)
-		if err != nil {
-			return fmt.Errorf("batch processing failed: %w", err)
+		// Process batch: paths come from --files-from or the glob pattern
+		var results []BatchResult
+		if filesFrom != "" {
+			if inputArg != "" {
+				return fmt.Errorf("--files-from cannot be combined with an input file argument")
+			}
+			files, err := readFileList(filesFrom)
+			if err != nil {
+				return err
+			}
+			results = processBatchFiles(files, "", c, converter)
+		} else {
+			var err error
+			results, err = ProcessBatch(inputArg, c)
+			if err != nil {
+				return fmt.Errorf("batch processing failed: %w", err)
+			}
 		}
 
 		// Report results
-		return reportBatchResults(results, validateOnly, dryRun)
+		if reportFile := c.String("report-file"); reportFile != "" {
+			if err := writeBatchReportFile(reportFile, results); err != nil {
+				return err
+			}
+		}
+		if c.Bool("report-json") {
+			return writeBatchReportJSON(os.Stdout, results)
+		}
+		if c.Bool("canonical-check") {
+			return reportCanonicalResults(results)
+		}
+		err := reportBatchResults(results, validateOnly, dryRun)
+		if c.Bool("group-by-type") {
+			reportBatchTypes(results)
+		}
+		return err
 	}
 
 	// Single file processing (original behavior)
 	inputFile := inputArg
-	converter := &Converter{strict: strict}
 
 	// Use extracted single file processing logic
-	err := processSingleFile(inputFile, outputFile, inputFormat, outputFormat, validateOnly, dryRun, sam, keystore, converter)
+	_, err = processSingleFile(inputFile, outputFile, processOptionsFromContext(c), converter)
 	if err != nil {
 		return err
 	}
 
+	if c.Bool("canonical-check") {
+		fmt.Printf("✓ '%s' is in canonical form\n", inputFile)
+		return nil
+	}
+
 	// Report success for single file (only if not validate-only or dry-run, as those print their own messages)
 	if !validateOnly && !dryRun {
 		// Auto-detect format for reporting if not specified
@@ -397,9 +1426,20 @@ func ConvertCommand(c *cli.Context) error {
 			}
 		}
 
+		if c.Bool("normalize") {
+			reportOutputFile := outputFile
+			if reportOutputFile == "" {
+				reportOutputFile = inputFile
+			}
+			fmt.Printf("✓ Normalized '%s' (%s) -> '%s'\n", inputFile, reportInputFormat, reportOutputFile)
+			return nil
+		}
+
 		// Generate output filename for reporting if not specified
 		reportOutputFile := outputFile
-		if reportOutputFile == "" {
+		if c.Bool("in-place") {
+			reportOutputFile = inputFile
+		} else if reportOutputFile == "" {
 			reportOutputFile = generateOutputFilename(inputFile, outputFormat)
 		}
 
@@ -428,7 +1468,7 @@ func ConvertCommand(c *cli.Context) error {
 // Returns:
 //   - string: Generated output filename with appropriate extension
 func generateOutputFilename(inputFile, format string) string {
-	base := inputFile
+	base := trimGzipExt(inputFile)
 
 	// Remove existing extension
 	if idx := strings.LastIndex(base, "."); idx != -1 {
@@ -462,21 +1502,69 @@ func extensionForFormat(format string) string {
 	}
 }
 
-// listTunnelNames reads inputFile, splits it into tunnels, and prints each tunnel's name.
-func listTunnelNames(inputFile, inputFormat string, converter *Converter) error {
-	inputData, err := os.ReadFile(inputFile)
+// mergeTunnelFiles parses every tunnel from each input file and writes them
+// all to outputFile as one go-i2p YAML document keyed by tunnel name. A tunnel
+// name that appears in two inputs is an error naming both source files. An
+// existing outputFile is only replaced when force is set. In dry-run mode the
+// merged document is printed to stdout instead.
+func mergeTunnelFiles(inputFiles []string, outputFile, inputFormat string, dryRun, force bool, converter *Converter) error {
+	var configs []*TunnelConfig
+	sources := make(map[string]string)
+	for _, inputFile := range inputFiles {
+		inputData, err := readInputFile(inputFile)
+		if err != nil {
+			return fmt.Errorf("failed to read '%s': %w", inputFile, err)
+		}
+		format := inputFormat
+		if format == "" {
+			format, err = converter.DetectFormat(inputFile)
+			if err != nil {
+				return fmt.Errorf("failed to detect format for '%s': %w", inputFile, err)
+			}
+		}
+		tunnels, err := converter.SplitTunnels(inputData, format)
+		if err != nil {
+			return fmt.Errorf("failed to parse '%s': %w", inputFile, err)
+		}
+		for _, cfg := range tunnels {
+			warnings, err := converter.validateWithFormat(cfg, format)
+			if err != nil {
+				return fmt.Errorf("validation error in '%s': %w", inputFile, err)
+			}
+			printWarnings(warnings)
+			if prev, exists := sources[cfg.Name]; exists {
+				return fmt.Errorf("tunnel name '%s' is defined in both '%s' and '%s'", cfg.Name, prev, inputFile)
+			}
+			sources[cfg.Name] = inputFile
+			configs = append(configs, cfg)
+		}
+	}
+
+	outData, err := converter.generateMultiYAML(configs)
 	if err != nil {
-		return fmt.Errorf("failed to read '%s': %w", inputFile, err)
+		return fmt.Errorf("failed to generate merged yaml: %w", err)
 	}
-	if inputFormat == "" {
-		inputFormat, err = converter.DetectFormat(inputFile)
-		if err != nil {
-			return fmt.Errorf("failed to detect format for '%s': %w", inputFile, err)
+	if dryRun {
+		fmt.Printf("# Merged %d tunnel(s) from %d file(s):\n%s", len(configs), len(inputFiles), string(outData))
+		return nil
+	}
+	if !force {
+		if _, err := os.Stat(outputFile); err == nil {
+			return fmt.Errorf("output file '%s' already exists, use --force to overwrite", outputFile)
 		}
 	}
-	configs, err := converter.SplitTunnels(inputData, inputFormat)
+	if err := os.WriteFile(outputFile, outData, 0o644); err != nil {
+		return fmt.Errorf("failed to write '%s': %w", outputFile, err)
+	}
+	fmt.Printf("✓ Merged %d tunnel(s) from %d file(s) into '%s'\n", len(configs), len(inputFiles), outputFile)
+	return nil
+}
+
+// listTunnelNames reads inputFile, splits it into tunnels, and prints each tunnel's name.
+func listTunnelNames(inputFile, inputFormat string, converter *Converter) error {
+	configs, inputFormat, err := readAllTunnels(inputFile, inputFormat, converter)
 	if err != nil {
-		return fmt.Errorf("failed to split tunnels in '%s': %w", inputFile, err)
+		return err
 	}
 	fmt.Printf("Found %d tunnel(s) in '%s' (%s):\n", len(configs), inputFile, inputFormat)
 	for i, cfg := range configs {
@@ -485,25 +1573,119 @@ func listTunnelNames(inputFile, inputFormat string, converter *Converter) error
 	return nil
 }
 
-// writeSplitTunnels reads inputFile, splits it into tunnels, and writes one output
-// file per tunnel named {tunnel.Name}{ext}. In dry-run mode output is printed to stdout.
-func writeSplitTunnels(inputFile, inputFormat, outputFormat string, dryRun bool, converter *Converter) error {
-	inputData, err := os.ReadFile(inputFile)
+// ValidateCommand is the action of the validate subcommand. It runs
+// ConvertCommand with --validate set, so every convert flag applies.
+func ValidateCommand(c *cli.Context) error {
+	if err := c.Set("validate", "true"); err != nil {
+		return fmt.Errorf("failed to enable validation: %w", err)
+	}
+	return ConvertCommand(c)
+}
+
+// ListTypesCommand is the action of the list-types subcommand. It prints the
+// same table as --list-types.
+func ListTypesCommand(c *cli.Context) error {
+	listTunnelTypes()
+	return nil
+}
+
+// SchemaCommand is the action of the schema subcommand. It prints the same
+// JSON Schema as --schema.
+func SchemaCommand(c *cli.Context) error {
+	return printSchema(os.Stdout)
+}
+
+// listTunnelTypes prints every supported tunnel type in name order with its
+// description and the fields it requires.
+func listTunnelTypes() {
+	ctx := NewValidationContext(false, "")
+	types := ctx.GetSupportedTunnelTypes()
+	sort.Slice(types, func(i, j int) bool { return types[i] < types[j] })
+
+	fmt.Println("Supported tunnel types:")
+	for _, t := range types {
+		required := "none"
+		if fields := requiredFields(ctx.TunnelSpecs[t]); len(fields) > 0 {
+			required = strings.Join(fields, ", ")
+		}
+		fmt.Printf("  %-16s %s (requires: %s)\n", t, ctx.GetTunnelTypeDescription(t), required)
+	}
+}
+
+// requiredFields returns the fields spec marks as required, in rule order,
+// on top of the name and type every tunnel needs.
+func requiredFields(spec TunnelTypeSpec) []string {
+	var fields []string
+	seen := make(map[string]bool)
+	for _, rule := range spec.Rules {
+		if rule.Required && !seen[rule.Field] {
+			seen[rule.Field] = true
+			fields = append(fields, rule.Field)
+		}
+	}
+	return fields
+}
+
+// countTunnels reads inputFile, splits it into tunnels, and prints how many it
+// contains without converting anything. With bare, only the number is
+// printed, for scripts.
+func countTunnels(inputFile, inputFormat string, bare bool, converter *Converter) error {
+	configs, format, err := readAllTunnels(inputFile, inputFormat, converter)
+	if err != nil {
+		return err
+	}
+	if bare {
+		fmt.Println(len(configs))
+		return nil
+	}
+	fmt.Printf("%d tunnel(s) in '%s' (%s)\n", len(configs), inputFile, format)
+	return nil
+}
+
+// readAllTunnels reads inputFile and splits it into one config per tunnel,
+// detecting the format when inputFormat is empty. It also returns the format.
+func readAllTunnels(inputFile, inputFormat string, converter *Converter) ([]*TunnelConfig, string, error) {
+	inputData, err := readInputFile(inputFile)
 	if err != nil {
-		return fmt.Errorf("failed to read '%s': %w", inputFile, err)
+		return nil, "", fmt.Errorf("failed to read '%s': %w", inputFile, err)
 	}
 	if inputFormat == "" {
 		inputFormat, err = converter.DetectFormat(inputFile)
 		if err != nil {
-			return fmt.Errorf("failed to detect format for '%s': %w", inputFile, err)
+			return nil, "", fmt.Errorf("failed to detect format for '%s': %w", inputFile, err)
 		}
 	}
 	configs, err := converter.SplitTunnels(inputData, inputFormat)
 	if err != nil {
-		return fmt.Errorf("failed to split tunnels in '%s': %w", inputFile, err)
+		return nil, "", fmt.Errorf("failed to split tunnels in '%s': %w", inputFile, err)
+	}
+	return configs, inputFormat, nil
+}
+
+// writeSplitTunnels reads inputFile, splits it into tunnels, and writes one output
+// file per tunnel named {tunnel.Name}{ext} inside outputDir (the current directory
+// when empty). A tunnel whose name is not a usable file name, or whose file would
+// collide with another tunnel's or with an existing file (unless force is set), is
+// reported and skipped without aborting the rest. In dry-run mode output is
+// printed to stdout. With generateKeys, each persistent tunnel also gets a key
+// file next to its output (see writeTunnelKeys).
+func writeSplitTunnels(inputFile, inputFormat, outputFormat, outputDir string, dryRun, force, generateKeys bool, converter *Converter) error {
+	configs, _, err := readAllTunnels(inputFile, inputFormat, converter)
+	if err != nil {
+		return err
+	}
+	if outputDir != "" && !dryRun {
+		if err := os.MkdirAll(outputDir, 0o755); err != nil {
+			return fmt.Errorf("failed to create output directory '%s': %w", outputDir, err)
+		}
 	}
 	ext := extensionForFormat(outputFormat)
+	written := make(map[string]string, len(configs))
 	for _, cfg := range configs {
+		if nameErr := checkSplitFileName(cfg.Name); nameErr != nil {
+			fmt.Fprintf(os.Stderr, "✗ Skipping tunnel '%s': %v\n", cfg.Name, nameErr)
+			continue
+		}
 		outData, genErr := converter.generateOutput(cfg, outputFormat)
 		if genErr != nil {
 			fmt.Fprintf(os.Stderr, "✗ Failed to generate output for '%s': %v\n", cfg.Name, genErr)
@@ -511,14 +1693,57 @@ func writeSplitTunnels(inputFile, inputFormat, outputFormat string, dryRun bool,
 		}
 		if dryRun {
 			fmt.Printf("# Tunnel '%s' as %s:\n%s\n", cfg.Name, outputFormat, string(outData))
+			if generateKeys && cfg.PersistentKey {
+				fmt.Printf("# Would write keys for '%s' to '%s'\n", cfg.Name, filepath.Join(outputDir, tunnelKeyFileName(cfg, outputFormat)))
+			}
+			continue
+		}
+		outFile := filepath.Join(outputDir, cfg.Name+ext)
+		// Compare case-insensitively so "Web" and "web" cannot overwrite each
+		// other on case-insensitive filesystems.
+		key := strings.ToLower(outFile)
+		if other, dup := written[key]; dup {
+			fmt.Fprintf(os.Stderr, "✗ Skipping tunnel '%s': '%s' was already written for tunnel '%s'\n", cfg.Name, outFile, other)
 			continue
 		}
-		outFile := cfg.Name + ext
+		if !force {
+			if _, statErr := os.Stat(outFile); statErr == nil {
+				fmt.Fprintf(os.Stderr, "✗ Skipping tunnel '%s': output file '%s' already exists, use --force to overwrite\n", cfg.Name, outFile)
+				continue
+			}
+		}
 		if writeErr := os.WriteFile(outFile, outData, 0o644); writeErr != nil {
 			fmt.Fprintf(os.Stderr, "✗ Failed to write '%s': %v\n", outFile, writeErr)
 			continue
 		}
+		written[key] = cfg.Name
 		fmt.Printf("✓ Wrote '%s' (%s)\n", outFile, outputFormat)
+
+		if generateKeys && cfg.PersistentKey {
+			keyFile, created, keyErr := writeTunnelKeys(cfg, outputDir, outputFormat)
+			switch {
+			case keyErr != nil:
+				fmt.Fprintf(os.Stderr, "✗ %v\n", keyErr)
+			case created:
+				fmt.Printf("✓ Wrote keys '%s'\n", keyFile)
+			default:
+				fmt.Printf("ℹ Kept existing keys '%s'\n", keyFile)
+			}
+		}
+	}
+	return nil
+}
+
+// checkSplitFileName reports why a tunnel name cannot be used as the base name
+// of a split output file.
+func checkSplitFileName(name string) error {
+	switch {
+	case name == "":
+		return fmt.Errorf("tunnel has no name")
+	case name == "." || name == "..":
+		return fmt.Errorf("'%s' is not a valid file name", name)
+	case strings.ContainsAny(name, "/\\\x00"):
+		return fmt.Errorf("name contains a path separator or NUL byte")
 	}
 	return nil
 }
@@ -1,10 +1,15 @@
 package i2pconv
 
 import (
+	"encoding/json"
 	goflag "flag"
 	"fmt"
+	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"reflect"
+	"sort"
 	"strings"
 	"testing"
 
@@ -43,6 +48,12 @@ func TestGenerateOutputFilename(t *testing.T) {
 			format:    "yaml",
 			expected:  "tunnel.yaml",
 		},
+		{
+			name:      "gzipped input",
+			inputFile: "tunnel.config.gz",
+			format:    "yaml",
+			expected:  "tunnel.yaml",
+		},
 		{
 			name:      "file with multiple dots",
 			inputFile: "tunnel.backup.config",
@@ -693,8 +704,13 @@ type=invalid-type
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := processSingleFile(tt.inputFile, tt.outputFile, tt.inputFormat,
-				tt.outputFormat, tt.validateOnly, tt.dryRun, false, "", converter)
+			opts := processOptions{
+				inputFormat:  tt.inputFormat,
+				outputFormat: tt.outputFormat,
+				validateOnly: tt.validateOnly,
+				dryRun:       tt.dryRun,
+			}
+			_, err := processSingleFile(tt.inputFile, tt.outputFile, opts, converter)
 
 			if tt.expectError {
 				if err == nil {
@@ -1300,6 +1316,79 @@ func TestConvertCommand_StdinDryRun(t *testing.T) {
 	}
 }
 
+// TestConvertCommand_SplitToDirectory verifies that --split with --output writes
+// one file per tunnel into that directory, and that colliding or unusable tunnel
+// names are reported per tunnel while the remaining tunnels are still written.
+func TestConvertCommand_SplitToDirectory(t *testing.T) {
+	newApp := func() *cli.App {
+		app := makeSplitApp()
+		app.Flags = append(app.Flags, &cli.StringFlag{Name: "output"}, &cli.BoolFlag{Name: "force"})
+		return app
+	}
+
+	t.Run("two-tunnel YAML to properties", func(t *testing.T) {
+		dir := t.TempDir()
+		inputFile := filepath.Join(dir, "tunnels.yaml")
+		yamlContent := "tunnels:\n" +
+			"  web:\n    type: httpclient\n    interface: 127.0.0.1\n    port: 4444\n" +
+			"  site:\n    type: server\n    target: 127.0.0.1:8080\n"
+		if err := os.WriteFile(inputFile, []byte(yamlContent), 0o644); err != nil {
+			t.Fatalf("setup: %v", err)
+		}
+		outDir := filepath.Join(dir, "split")
+
+		err := newApp().Run([]string{"go-i2ptunnel-config", "--split", "--out-format", "properties", "--output", outDir, inputFile})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		for name, wantType := range map[string]string{"web": "httpclient", "site": "server"} {
+			data, readErr := os.ReadFile(filepath.Join(outDir, name+".properties"))
+			if readErr != nil {
+				t.Fatalf("expected %s.properties: %v", name, readErr)
+			}
+			if !strings.Contains(string(data), "name="+name+"\n") || !strings.Contains(string(data), "type="+wantType+"\n") {
+				t.Errorf("%s.properties has unexpected content:\n%s", name, data)
+			}
+		}
+	})
+
+	t.Run("collisions and invalid names are skipped per tunnel", func(t *testing.T) {
+		dir := t.TempDir()
+		inputFile := filepath.Join(dir, "tunnels.config")
+		props := "tunnel.0.name=web\ntunnel.0.type=httpclient\ntunnel.0.listenPort=4444\n" +
+			"tunnel.1.name=WEB\ntunnel.1.type=httpclient\ntunnel.1.listenPort=4445\n" +
+			"tunnel.2.name=../escape\ntunnel.2.type=httpclient\ntunnel.2.listenPort=4446\n" +
+			"tunnel.3.name=irc\ntunnel.3.type=ircclient\ntunnel.3.listenPort=6668\n"
+		if err := os.WriteFile(inputFile, []byte(props), 0o644); err != nil {
+			t.Fatalf("setup: %v", err)
+		}
+		outDir := filepath.Join(dir, "out")
+
+		var runErr error
+		stderr := captureOutput(t, &os.Stderr, func() {
+			runErr = newApp().Run([]string{"go-i2ptunnel-config", "--split", "--output", outDir, inputFile})
+		})
+		if runErr != nil {
+			t.Fatalf("unexpected error: %v", runErr)
+		}
+
+		if _, err := os.Stat(filepath.Join(outDir, "irc.yaml")); err != nil {
+			t.Errorf("irc.yaml should still be written: %v", err)
+		}
+		if _, err := os.Stat(filepath.Join(dir, "escape.yaml")); !os.IsNotExist(err) {
+			t.Errorf("tunnel name must not escape the output directory")
+		}
+		entries, _ := os.ReadDir(outDir)
+		if len(entries) != 2 {
+			t.Errorf("expected 2 files (one of web/WEB plus irc), got %v", entries)
+		}
+		if !strings.Contains(stderr, "../escape") || !strings.Contains(stderr, "already written") {
+			t.Errorf("expected per-tunnel errors on stderr, got:\n%s", stderr)
+		}
+	})
+}
+
 // TestWriteSplitTunnels_WriteError verifies that writeSplitTunnels continues
 // gracefully when os.WriteFile fails (e.g., read-only output directory) rather
 // than returning an error, and that it still returns nil.
@@ -1329,7 +1418,7 @@ func TestWriteSplitTunnels_WriteError(t *testing.T) {
 
 	converter := &Converter{}
 	// writeSplitTunnels logs errors per-tunnel but returns nil overall.
-	err := writeSplitTunnels(inputFile, "ini", "yaml", false, converter)
+	err := writeSplitTunnels(inputFile, "ini", "yaml", "", false, false, false, converter)
 	if err != nil {
 		t.Errorf("writeSplitTunnels should return nil even on write failures, got: %v", err)
 	}
@@ -1340,3 +1429,1421 @@ func TestWriteSplitTunnels_WriteError(t *testing.T) {
 		t.Errorf("expected no files in read-only dir, found: %v", entries)
 	}
 }
+
+// TestConvertCommand_MergeI2CPFrom verifies that --merge-i2cp-from imports the
+// i2cp block of another config and that the input's own options win on conflict.
+func TestConvertCommand_MergeI2CPFrom(t *testing.T) {
+	tunnelContent := "name=myTunnel\ntype=httpclient\ninterface=127.0.0.1\nlistenPort=4444\n" +
+		"option.i2cp.leaseSetEncType=4\n"
+
+	tests := []struct {
+		name       string
+		sharedFile string
+		shared     string
+	}{
+		{
+			name:       "bare i2cp yaml block",
+			sharedFile: "common-i2cp.yaml",
+			shared:     "i2cp:\n  reduceIdleTime: 900000\n  leaseSetEncType: \"0\"\n",
+		},
+		{
+			name:       "full properties config",
+			sharedFile: "common.properties",
+			shared:     "option.i2cp.reduceIdleTime=900000\noption.i2cp.leaseSetEncType=0\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			inputFile := filepath.Join(dir, "tunnel.properties")
+			sharedFile := filepath.Join(dir, tt.sharedFile)
+			outputFile := filepath.Join(dir, "out.properties")
+			if err := os.WriteFile(inputFile, []byte(tunnelContent), 0o644); err != nil {
+				t.Fatalf("setup: %v", err)
+			}
+			if err := os.WriteFile(sharedFile, []byte(tt.shared), 0o644); err != nil {
+				t.Fatalf("setup: %v", err)
+			}
+
+			app := makeStdinApp()
+			app.Flags = append(app.Flags, &cli.StringFlag{Name: "merge-i2cp-from"})
+			err := app.Run([]string{
+				"go-i2ptunnel-config",
+				"--out-format", "properties",
+				"--merge-i2cp-from", sharedFile,
+				"--output", outputFile,
+				inputFile,
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			out, err := os.ReadFile(outputFile)
+			if err != nil {
+				t.Fatalf("failed to read output: %v", err)
+			}
+			if !strings.Contains(string(out), "option.i2cp.reduceIdleTime=900000") {
+				t.Errorf("expected merged reduceIdleTime in output, got:\n%s", out)
+			}
+			if !strings.Contains(string(out), "option.i2cp.leaseSetEncType=4") {
+				t.Errorf("expected input's leaseSetEncType to win, got:\n%s", out)
+			}
+		})
+	}
+
+	t.Run("missing shared file", func(t *testing.T) {
+		dir := t.TempDir()
+		inputFile := filepath.Join(dir, "tunnel.properties")
+		if err := os.WriteFile(inputFile, []byte(tunnelContent), 0o644); err != nil {
+			t.Fatalf("setup: %v", err)
+		}
+		opts := processOptions{
+			outputFormat:  "yaml",
+			dryRun:        true,
+			mergeI2CPFrom: filepath.Join(dir, "missing.yaml"),
+		}
+		_, err := processSingleFile(inputFile, "", opts, &Converter{})
+		if err == nil || !strings.Contains(err.Error(), "failed to merge i2cp options") {
+			t.Errorf("expected merge error, got: %v", err)
+		}
+	})
+}
+
+// TestConvertCommand_InPlace verifies that --in-place overwrites the source file
+// with the converted content and rejects conflicting flags.
+func TestConvertCommand_InPlace(t *testing.T) {
+	content := "name=myTunnel\ntype=httpclient\ninterface=127.0.0.1\nlistenPort=4444\n"
+	makeApp := func() *cli.App {
+		app := makeStdinApp()
+		app.Flags = append(app.Flags, &cli.BoolFlag{Name: "in-place"})
+		return app
+	}
+
+	t.Run("converts properties to yaml in place", func(t *testing.T) {
+		dir := t.TempDir()
+		inputFile := filepath.Join(dir, "test.properties")
+		if err := os.WriteFile(inputFile, []byte(content), 0o644); err != nil {
+			t.Fatalf("setup: %v", err)
+		}
+
+		err := makeApp().Run([]string{"go-i2ptunnel-config", "--in-place", "--out-format", "yaml", inputFile})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		out, err := os.ReadFile(inputFile)
+		if err != nil {
+			t.Fatalf("failed to read converted file: %v", err)
+		}
+		if !strings.HasPrefix(string(out), "tunnels:\n") || !strings.Contains(string(out), "myTunnel:") {
+			t.Errorf("expected YAML content in %s, got:\n%s", inputFile, out)
+		}
+		if _, err := os.Stat(filepath.Join(dir, "test.yaml")); !os.IsNotExist(err) {
+			t.Error("--in-place must not create an auto-named output file")
+		}
+	})
+
+	for _, args := range [][]string{
+		{"--in-place", "--output", "other.yaml"},
+	} {
+		t.Run(strings.Join(args, " "), func(t *testing.T) {
+			dir := t.TempDir()
+			inputFile := filepath.Join(dir, "test.properties")
+			if err := os.WriteFile(inputFile, []byte(content), 0o644); err != nil {
+				t.Fatalf("setup: %v", err)
+			}
+			runArgs := append([]string{"go-i2ptunnel-config"}, args...)
+			err := makeApp().Run(append(runArgs, inputFile))
+			if err == nil || !strings.Contains(err.Error(), "--in-place cannot be combined") {
+				t.Errorf("expected --in-place conflict error, got: %v", err)
+			}
+			out, _ := os.ReadFile(inputFile)
+			if string(out) != content {
+				t.Error("input file must be untouched when flags conflict")
+			}
+		})
+	}
+}
+
+// TestConvertCommand_InPlaceDryRunDiff verifies that --in-place --dry-run
+// prints a unified diff of the would-be rewrite and leaves the file alone.
+func TestConvertCommand_InPlaceDryRunDiff(t *testing.T) {
+	content := "name=myTunnel\ntype=httpclient\ninterface = 127.0.0.1\nlistenPort=8080\n"
+	makeApp := func() *cli.App {
+		app := makeStdinApp()
+		app.Flags = append(app.Flags, &cli.BoolFlag{Name: "in-place"})
+		return app
+	}
+	dir := t.TempDir()
+	inputFile := filepath.Join(dir, "test.properties")
+	if err := os.WriteFile(inputFile, []byte(content), 0o644); err != nil {
+		t.Fatalf("setup: %v", err)
+	}
+	run := func() (string, error) {
+		var err error
+		stdout := captureOutput(t, &os.Stdout, func() {
+			err = makeApp().Run([]string{"go-i2ptunnel-config", "--in-place", "--dry-run", "--out-format", "properties", inputFile})
+		})
+		return stdout, err
+	}
+
+	stdout, err := run()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{"--- " + inputFile + "\n", "+++ " + inputFile + " (converted)\n", "@@ ", "\n-interface = 127.0.0.1\n", "\n+interface=127.0.0.1\n", "\n name=myTunnel\n"} {
+		if !strings.Contains(stdout, want) {
+			t.Errorf("diff output lacks %q:\n%s", want, stdout)
+		}
+	}
+	if strings.Contains(stdout, "\n-listenPort=8080\n") {
+		t.Errorf("unchanged line reported as removed:\n%s", stdout)
+	}
+	if out, _ := os.ReadFile(inputFile); string(out) != content {
+		t.Errorf("--dry-run modified the input file:\n%s", out)
+	}
+
+	// Once rewritten the file has nothing left to change.
+	if err := makeApp().Run([]string{"go-i2ptunnel-config", "--in-place", "--out-format", "properties", inputFile}); err != nil {
+		t.Fatalf("in-place rewrite error = %v", err)
+	}
+	if stdout, err = run(); err != nil || !strings.Contains(stdout, "would be unchanged") {
+		t.Errorf("second dry run = %q, %v; want no changes", stdout, err)
+	}
+}
+
+// TestConvertCommand_Backup verifies that --backup preserves an existing output
+// file as a timestamped .bak before writing the new content.
+func TestConvertCommand_Backup(t *testing.T) {
+	dir := t.TempDir()
+	inputFile := filepath.Join(dir, "test.properties")
+	outputFile := filepath.Join(dir, "test.yaml")
+	oldContent := "# previous conversion\n"
+	if err := os.WriteFile(inputFile, []byte("name=myTunnel\ntype=httpclient\nlistenPort=4444\n"), 0o644); err != nil {
+		t.Fatalf("setup: %v", err)
+	}
+	if err := os.WriteFile(outputFile, []byte(oldContent), 0o644); err != nil {
+		t.Fatalf("setup: %v", err)
+	}
+
+	app := makeStdinApp()
+	app.Flags = append(app.Flags, &cli.BoolFlag{Name: "backup"})
+	if err := app.Run([]string{"go-i2ptunnel-config", "--backup", inputFile}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	backups, _ := filepath.Glob(filepath.Join(dir, "test.yaml.*.bak"))
+	if len(backups) != 1 {
+		t.Fatalf("expected one backup file, found: %v", backups)
+	}
+	data, err := os.ReadFile(backups[0])
+	if err != nil {
+		t.Fatalf("failed to read backup: %v", err)
+	}
+	if string(data) != oldContent {
+		t.Errorf("backup content = %q, want %q", data, oldContent)
+	}
+	newData, _ := os.ReadFile(outputFile)
+	if !strings.Contains(string(newData), "myTunnel") {
+		t.Errorf("expected new conversion in %s, got:\n%s", outputFile, newData)
+	}
+}
+
+// TestBackupExistingFile_Missing verifies that no backup is made when the
+// target does not exist.
+func TestBackupExistingFile_Missing(t *testing.T) {
+	path, err := backupExistingFile(filepath.Join(t.TempDir(), "absent.yaml"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if path != "" {
+		t.Errorf("expected empty backup path, got %q", path)
+	}
+}
+
+// TestConvertCommand_Force verifies that an existing output file is only
+// overwritten when --force is given, and that --dry-run never touches it.
+func TestConvertCommand_Force(t *testing.T) {
+	existing := "# keep me\n"
+	setup := func(t *testing.T) (string, string) {
+		dir := t.TempDir()
+		inputFile := filepath.Join(dir, "test.properties")
+		outputFile := filepath.Join(dir, "test.yaml")
+		if err := os.WriteFile(inputFile, []byte("name=myTunnel\ntype=httpclient\nlistenPort=4444\n"), 0o644); err != nil {
+			t.Fatalf("setup: %v", err)
+		}
+		if err := os.WriteFile(outputFile, []byte(existing), 0o644); err != nil {
+			t.Fatalf("setup: %v", err)
+		}
+		return inputFile, outputFile
+	}
+	makeApp := func() *cli.App {
+		app := makeStdinApp()
+		app.Flags = append(app.Flags, &cli.BoolFlag{Name: "force"})
+		return app
+	}
+
+	t.Run("refuses to overwrite auto-generated name", func(t *testing.T) {
+		inputFile, outputFile := setup(t)
+		err := makeApp().Run([]string{"go-i2ptunnel-config", inputFile})
+		if err == nil || !strings.Contains(err.Error(), "already exists, use --force to overwrite") {
+			t.Fatalf("expected overwrite refusal, got: %v", err)
+		}
+		if data, _ := os.ReadFile(outputFile); string(data) != existing {
+			t.Error("existing output must be untouched")
+		}
+	})
+
+	t.Run("refuses to overwrite explicit output", func(t *testing.T) {
+		inputFile, outputFile := setup(t)
+		err := makeApp().Run([]string{"go-i2ptunnel-config", inputFile, outputFile})
+		if err == nil || !strings.Contains(err.Error(), "already exists") {
+			t.Fatalf("expected overwrite refusal, got: %v", err)
+		}
+	})
+
+	t.Run("--force overwrites", func(t *testing.T) {
+		inputFile, outputFile := setup(t)
+		if err := makeApp().Run([]string{"go-i2ptunnel-config", "--force", inputFile}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if data, _ := os.ReadFile(outputFile); !strings.Contains(string(data), "myTunnel") {
+			t.Errorf("expected converted content, got:\n%s", data)
+		}
+	})
+
+	t.Run("--dry-run leaves existing file alone", func(t *testing.T) {
+		inputFile, outputFile := setup(t)
+		if err := makeApp().Run([]string{"go-i2ptunnel-config", "--dry-run", inputFile}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if data, _ := os.ReadFile(outputFile); string(data) != existing {
+			t.Error("--dry-run must not modify the existing output")
+		}
+	})
+}
+
+// TestProcessBatch_Recursive verifies that --recursive finds files in nested
+// directories that a plain glob would miss.
+func TestProcessBatch_Recursive(t *testing.T) {
+	validContent := "name=test-tunnel\ntype=httpclient\ninterface=127.0.0.1\nlistenPort=8080\n"
+	dir := t.TempDir()
+	nested := filepath.Join(dir, "a", "b")
+	if err := os.MkdirAll(nested, 0o755); err != nil {
+		t.Fatalf("setup: %v", err)
+	}
+	for _, f := range []string{filepath.Join(dir, "top.properties"), filepath.Join(nested, "c.properties")} {
+		if err := os.WriteFile(f, []byte(validContent), 0o644); err != nil {
+			t.Fatalf("setup: %v", err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(nested, "notes.txt"), []byte("not a config"), 0o644); err != nil {
+		t.Fatalf("setup: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		pattern string
+		want    int
+	}{
+		{"root directory", dir, 2},
+		{"directory with name pattern", filepath.Join(dir, "c.*"), 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files, err := expandBatchPattern(tt.pattern, true)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(files) != tt.want {
+				t.Fatalf("expected %d files, got %v", tt.want, files)
+			}
+			found := false
+			for _, f := range files {
+				if f == filepath.Join(nested, "c.properties") {
+					found = true
+				}
+			}
+			if !found {
+				t.Errorf("expected deep file a/b/c.properties in %v", files)
+			}
+		})
+	}
+
+	t.Run("batch --recursive converts deep files", func(t *testing.T) {
+		app := makeStdinApp()
+		app.Flags = append(app.Flags, &cli.BoolFlag{Name: "recursive"})
+		if err := app.Run([]string{"go-i2ptunnel-config", "--batch", "--recursive", dir}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if _, err := os.Stat(filepath.Join(nested, "c.yaml")); err != nil {
+			t.Errorf("expected a/b/c.yaml to be written: %v", err)
+		}
+	})
+}
+
+// TestExpandBraces checks the expansion of brace alternatives, nested braces
+// included, and that unbalanced or single-entry braces are left alone.
+func TestExpandBraces(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    []string
+	}{
+		{"*.config", []string{"*.config"}},
+		{"{http,socks}*.config", []string{"http*.config", "socks*.config"}},
+		{"t/{a,b}/{x,y}.conf", []string{"t/a/x.conf", "t/a/y.conf", "t/b/x.conf", "t/b/y.conf"}},
+		{"{a,b{c,d}}.ini", []string{"a.ini", "bc.ini", "bd.ini"}},
+		{"{x}{a,b}", []string{"{x}a", "{x}b"}},
+		{"{a,b", []string{"{a,b"}},
+		{"a}{b,c}", []string{"a}b", "a}c"}},
+	}
+	for _, tt := range tests {
+		if got := expandBraces(tt.pattern); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("expandBraces(%q) = %q, want %q", tt.pattern, got, tt.want)
+		}
+	}
+}
+
+// TestProcessBatch_BracePattern checks that a batch pattern with braces matches
+// each file once, in order, even when alternatives overlap.
+func TestProcessBatch_BracePattern(t *testing.T) {
+	validContent := "name=test-tunnel\ntype=httpclient\ninterface=127.0.0.1\nlistenPort=8080\n"
+	dir := t.TempDir()
+	for _, name := range []string{"http-a.config", "http-b.config", "socks-a.config", "irc-a.config"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(validContent), 0o644); err != nil {
+			t.Fatalf("setup: %v", err)
+		}
+	}
+
+	// "*-a" overlaps both groups; every file must still be processed once
+	pattern := filepath.Join(dir, "{http,socks,*-a}*.config")
+	files, err := expandBatchPattern(pattern, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []string{"http-a.config", "http-b.config", "socks-a.config", "irc-a.config"}
+	for i := range want {
+		want[i] = filepath.Join(dir, want[i])
+	}
+	if !reflect.DeepEqual(files, want) {
+		t.Fatalf("expandBatchPattern() = %v, want %v", files, want)
+	}
+
+	if err := makeStdinApp().Run([]string{"go-i2ptunnel-config", "--batch", filepath.Join(dir, "{http,socks}*.config")}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, name := range []string{"http-a.yaml", "http-b.yaml", "socks-a.yaml"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("expected %s to be written: %v", name, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "irc-a.yaml")); !os.IsNotExist(err) {
+		t.Error("irc-a.config does not match the pattern and must not be converted")
+	}
+}
+
+// TestProcessBatch_FailFast checks that --fail-fast stops a batch at the first
+// failing file and that without it every file is processed.
+func TestProcessBatch_FailFast(t *testing.T) {
+	validContent := "name=test-tunnel\ntype=httpclient\ninterface=127.0.0.1\nlistenPort=8080\n"
+	newDir := func(t *testing.T) string {
+		dir := t.TempDir()
+		files := map[string]string{"a.config": validContent, "b.config": "type=httpclient\n", "c.config": validContent}
+		for name, content := range files {
+			if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+				t.Fatalf("setup: %v", err)
+			}
+		}
+		return dir
+	}
+	run := func(dir string, args ...string) (string, error) {
+		app := makeStdinApp()
+		app.Flags = append(app.Flags, &cli.BoolFlag{Name: "fail-fast"})
+		var err error
+		stdout := captureOutput(t, &os.Stdout, func() {
+			err = app.Run(append(append([]string{"go-i2ptunnel-config", "--batch"}, args...), filepath.Join(dir, "*.config")))
+		})
+		return stdout, err
+	}
+
+	t.Run("stops after the first bad file", func(t *testing.T) {
+		dir := newDir(t)
+		stdout, err := run(dir, "--fail-fast")
+		if err == nil || err.Error() != "1 of 2 files failed processing" {
+			t.Errorf("error = %v, want 1 of 2 files failed", err)
+		}
+		if _, err := os.Stat(filepath.Join(dir, "a.yaml")); err != nil {
+			t.Errorf("file before the failure was not converted: %v", err)
+		}
+		if _, err := os.Stat(filepath.Join(dir, "c.yaml")); !os.IsNotExist(err) {
+			t.Error("file after the failure was converted despite --fail-fast")
+		}
+		if strings.Contains(stdout, "c.config") {
+			t.Errorf("report mentions the unprocessed file:\n%s", stdout)
+		}
+	})
+
+	t.Run("continues without the flag", func(t *testing.T) {
+		dir := newDir(t)
+		if _, err := run(dir); err == nil || err.Error() != "1 of 3 files failed processing" {
+			t.Errorf("error = %v, want 1 of 3 files failed", err)
+		}
+		if _, err := os.Stat(filepath.Join(dir, "c.yaml")); err != nil {
+			t.Errorf("file after the failure was not converted: %v", err)
+		}
+	})
+}
+
+// TestProcessBatch_OutputDir checks that a batch with --output-dir writes every
+// converted file there, leaves the inputs alone, names the outputs in its
+// summary, and is refused outside batch mode.
+func TestProcessBatch_OutputDir(t *testing.T) {
+	content := "name=test-tunnel\ntype=httpclient\ninterface=127.0.0.1\nlistenPort=8080\n"
+	inputDir := t.TempDir()
+	inputs := []string{"web.config", "proxy.config"}
+	for _, name := range inputs {
+		if err := os.WriteFile(filepath.Join(inputDir, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("setup: %v", err)
+		}
+	}
+	outputDir := filepath.Join(t.TempDir(), "converted", "ini")
+
+	app := makeStdinApp()
+	app.Flags = append(app.Flags, &cli.StringFlag{Name: "output-dir"})
+	var err error
+	stdout := captureOutput(t, &os.Stdout, func() {
+		err = app.Run([]string{"go-i2ptunnel-config", "--batch", "--out-format", "ini", "--output-dir", outputDir, filepath.Join(inputDir, "*.config")})
+	})
+	if err != nil {
+		t.Fatalf("batch with --output-dir error = %v\n%s", err, stdout)
+	}
+
+	for _, name := range inputs {
+		data, err := os.ReadFile(filepath.Join(inputDir, name))
+		if err != nil || string(data) != content {
+			t.Errorf("original %s changed or removed: %q, %v", name, data, err)
+		}
+		base := strings.TrimSuffix(name, ".config") + ".conf"
+		if _, err := os.Stat(filepath.Join(outputDir, base)); err != nil {
+			t.Errorf("converted file %s not in the output directory: %v", base, err)
+		}
+		if _, err := os.Stat(filepath.Join(inputDir, base)); !os.IsNotExist(err) {
+			t.Errorf("converted file %s written next to the input", base)
+		}
+		if !strings.Contains(stdout, filepath.Join(outputDir, base)) {
+			t.Errorf("summary does not name the output path %s:\n%s", base, stdout)
+		}
+	}
+
+	app = makeStdinApp()
+	app.Flags = append(app.Flags, &cli.StringFlag{Name: "output-dir"})
+	if err := app.Run([]string{"go-i2ptunnel-config", "--output-dir", outputDir, filepath.Join(inputDir, "web.config")}); err == nil {
+		t.Error("--output-dir accepted outside batch mode")
+	}
+}
+
+// TestProcessBatch_RecursiveOutputDirKeepsTree checks that a recursive batch
+// with --output-dir mirrors the input directory tree, from a directory or a
+// glob, and skips files that are not tunnel configs.
+func TestProcessBatch_RecursiveOutputDirKeepsTree(t *testing.T) {
+	content := "name=test-tunnel\ntype=httpclient\ninterface=127.0.0.1\nlistenPort=8080\n"
+	root := t.TempDir()
+	inputs := map[string]string{
+		"top.config":          "top.yaml",
+		"a/b/c.config":        filepath.Join("a", "b", "c.yaml"),
+		"x/c.config":          filepath.Join("x", "c.yaml"),
+		"x/notes/readme.text": "",
+	}
+	for name := range inputs {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("setup: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("setup: %v", err)
+		}
+	}
+
+	for _, pattern := range []string{root, filepath.Join(root, "*.config")} {
+		t.Run(filepath.Base(pattern), func(t *testing.T) {
+			outputDir := filepath.Join(t.TempDir(), "out")
+			app := makeStdinApp()
+			app.Flags = append(app.Flags, &cli.StringFlag{Name: "output-dir"}, &cli.BoolFlag{Name: "recursive"})
+			var err error
+			stdout := captureOutput(t, &os.Stdout, func() {
+				err = app.Run([]string{"go-i2ptunnel-config", "--batch", "--recursive", "--output-dir", outputDir, pattern})
+			})
+			if err != nil {
+				t.Fatalf("recursive batch error = %v\n%s", err, stdout)
+			}
+
+			var got []string
+			_ = filepath.WalkDir(outputDir, func(path string, d fs.DirEntry, err error) error {
+				if err == nil && !d.IsDir() {
+					rel, _ := filepath.Rel(outputDir, path)
+					got = append(got, rel)
+				}
+				return nil
+			})
+			var want []string
+			for _, out := range inputs {
+				if out != "" {
+					want = append(want, out)
+				}
+			}
+			sort.Strings(got)
+			sort.Strings(want)
+			if strings.Join(got, ",") != strings.Join(want, ",") {
+				t.Errorf("output tree = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+// TestBatchRoot checks that the root a recursive batch mirrors is the directory
+// before the first glob element.
+func TestBatchRoot(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		pattern string
+		want    string
+	}{
+		{dir, dir},
+		{filepath.Join(dir, "*.config"), dir},
+		{filepath.Join(dir, "{a,b}"), dir},
+		{filepath.Join(dir, "a*", "x", "*.config"), dir},
+		{"*.config", "."},
+	}
+	for _, tt := range tests {
+		if got := batchRoot(tt.pattern); got != tt.want {
+			t.Errorf("batchRoot(%q) = %q, want %q", tt.pattern, got, tt.want)
+		}
+	}
+}
+
+// captureOutput redirects *target (os.Stdout or os.Stderr) to a pipe while fn
+// runs and returns everything written to it.
+func captureOutput(t *testing.T, target **os.File, fn func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("pipe: %v", err)
+	}
+	orig := *target
+	*target = w
+	done := make(chan string)
+	go func() {
+		data, _ := io.ReadAll(r)
+		done <- string(data)
+	}()
+	defer func() { *target = orig }()
+	fn()
+	w.Close()
+	out := <-done
+	r.Close()
+	return out
+}
+
+// TestConvertCommand_ReportJSON verifies that --report-json prints a parsable
+// JSON array whose success/failure counts match the batch outcome.
+func TestConvertCommand_ReportJSON(t *testing.T) {
+	dir := t.TempDir()
+	valid := "name=test-tunnel\ntype=httpclient\ninterface=127.0.0.1\nlistenPort=8080\n"
+	for _, name := range []string{"a.properties", "b.properties"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(valid), 0o644); err != nil {
+			t.Fatalf("setup: %v", err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dir, "bad.properties"), []byte("type=httpclient\n"), 0o644); err != nil {
+		t.Fatalf("setup: %v", err)
+	}
+
+	app := makeStdinApp()
+	app.Flags = append(app.Flags, &cli.BoolFlag{Name: "report-json"})
+	var runErr error
+	stdout := captureOutput(t, &os.Stdout, func() {
+		runErr = app.Run([]string{"go-i2ptunnel-config", "--batch", "--validate", "--report-json", filepath.Join(dir, "*.properties")})
+	})
+	if runErr == nil || !strings.Contains(runErr.Error(), "1 of 3 files failed") {
+		t.Errorf("expected failure count error, got: %v", runErr)
+	}
+
+	var results []struct {
+		Input   string `json:"input"`
+		Success bool   `json:"success"`
+		Error   string `json:"error"`
+	}
+	if err := json.Unmarshal([]byte(stdout), &results); err != nil {
+		t.Fatalf("output is not a JSON array: %v\n%s", err, stdout)
+	}
+	successes, failures := 0, 0
+	for _, r := range results {
+		if r.Success {
+			successes++
+			if r.Error != "" {
+				t.Errorf("successful result %s should have no error, got %q", r.Input, r.Error)
+			}
+		} else {
+			failures++
+			if !strings.Contains(r.Error, "tunnel name is required") {
+				t.Errorf("failed result %s should carry its error message, got %q", r.Input, r.Error)
+			}
+		}
+	}
+	if successes != 2 || failures != 1 {
+		t.Errorf("got %d successes and %d failures, want 2 and 1", successes, failures)
+	}
+}
+
+// TestConvertCommand_ReportJSONDryRun verifies that --dry-run with
+// --report-json prints the converted previews on stderr, so stdout holds
+// only the JSON array.
+func TestConvertCommand_ReportJSONDryRun(t *testing.T) {
+	dir := t.TempDir()
+	for name, port := range map[string]string{"a": "8080", "b": "8081"} {
+		content := "name=" + name + "\ntype=httpclient\ninterface=127.0.0.1\nlistenPort=" + port + "\n"
+		if err := os.WriteFile(filepath.Join(dir, name+".properties"), []byte(content), 0o644); err != nil {
+			t.Fatalf("setup: %v", err)
+		}
+	}
+
+	app := makeStdinApp()
+	app.Flags = append(app.Flags, &cli.BoolFlag{Name: "report-json"})
+	var runErr error
+	var stdout string
+	stderr := captureOutput(t, &os.Stderr, func() {
+		stdout = captureOutput(t, &os.Stdout, func() {
+			runErr = app.Run([]string{"go-i2ptunnel-config", "--batch", "--dry-run", "--out-format", "yaml", "--report-json", filepath.Join(dir, "*.properties")})
+		})
+	})
+	if runErr != nil {
+		t.Fatalf("unexpected error: %v", runErr)
+	}
+
+	var results []struct {
+		Success bool `json:"success"`
+	}
+	if err := json.Unmarshal([]byte(stdout), &results); err != nil {
+		t.Fatalf("output is not a JSON array: %v\n%s", err, stdout)
+	}
+	if len(results) != 2 {
+		t.Errorf("got %d results, want 2", len(results))
+	}
+	if strings.Count(stderr, "# Converted") != 2 {
+		t.Errorf("stderr should hold both previews, got:\n%s", stderr)
+	}
+}
+
+// TestConvertCommand_ReportFile verifies that --report-file writes the JSON
+// results to the named file while stdout keeps the text summary.
+func TestConvertCommand_ReportFile(t *testing.T) {
+	dir := t.TempDir()
+	valid := "name=test-tunnel\ntype=httpclient\ninterface=127.0.0.1\nlistenPort=8080\n"
+	if err := os.WriteFile(filepath.Join(dir, "a.properties"), []byte(valid), 0o644); err != nil {
+		t.Fatalf("setup: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "bad.properties"), []byte("type=httpclient\n"), 0o644); err != nil {
+		t.Fatalf("setup: %v", err)
+	}
+	reportFile := filepath.Join(t.TempDir(), "results.json")
+
+	app := makeStdinApp()
+	app.Flags = append(app.Flags, &cli.StringFlag{Name: "report-file"})
+	var runErr error
+	stdout := captureOutput(t, &os.Stdout, func() {
+		runErr = app.Run([]string{"go-i2ptunnel-config", "--batch", "--validate", "--report-file", reportFile, filepath.Join(dir, "*.properties")})
+	})
+	if runErr == nil {
+		t.Error("expected an error for the failed file")
+	}
+	if !strings.Contains(stdout, "Validation summary") {
+		t.Errorf("stdout should keep the text summary, got:\n%s", stdout)
+	}
+
+	data, err := os.ReadFile(reportFile)
+	if err != nil {
+		t.Fatalf("report file not written: %v", err)
+	}
+	var results []struct {
+		Input   string `json:"input"`
+		Success bool   `json:"success"`
+		Error   string `json:"error"`
+	}
+	if err := json.Unmarshal(data, &results); err != nil {
+		t.Fatalf("report file is not a JSON array: %v\n%s", err, data)
+	}
+	if len(results) != 2 {
+		t.Fatalf("got %d results, want 2", len(results))
+	}
+	for _, r := range results {
+		wantSuccess := filepath.Base(r.Input) == "a.properties"
+		if r.Success != wantSuccess {
+			t.Errorf("%s: success = %v, want %v (error %q)", r.Input, r.Success, wantSuccess, r.Error)
+		}
+	}
+
+	t.Run("unwritable path", func(t *testing.T) {
+		app := makeStdinApp()
+		app.Flags = append(app.Flags, &cli.StringFlag{Name: "report-file"})
+		bad := filepath.Join(dir, "missing", "results.json")
+		err := app.Run([]string{"go-i2ptunnel-config", "--batch", "--validate", "--report-file", bad, filepath.Join(dir, "a.properties")})
+		if err == nil || !strings.Contains(err.Error(), "report file") {
+			t.Errorf("expected report file error, got %v", err)
+		}
+	})
+}
+
+// TestConvertCommand_Merge verifies that --merge combines tunnels from files in
+// different formats and rejects duplicate tunnel names.
+func TestConvertCommand_Merge(t *testing.T) {
+	makeApp := func() *cli.App {
+		app := makeStdinApp()
+		app.Flags = append(app.Flags, &cli.BoolFlag{Name: "merge"}, &cli.BoolFlag{Name: "force"})
+		return app
+	}
+
+	t.Run("merges properties and ini examples", func(t *testing.T) {
+		outputFile := filepath.Join(t.TempDir(), "tunnels.yaml")
+		err := makeApp().Run([]string{
+			"go-i2ptunnel-config", "--merge", "-o", outputFile,
+			"../examples/httpclient.properties", "../examples/server.conf",
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		data, err := os.ReadFile(outputFile)
+		if err != nil {
+			t.Fatalf("failed to read merged output: %v", err)
+		}
+		configs, err := (&Converter{}).SplitTunnels(data, "yaml")
+		if err != nil {
+			t.Fatalf("merged output does not parse: %v", err)
+		}
+		names := map[string]string{}
+		for _, cfg := range configs {
+			names[cfg.Name] = cfg.Type
+		}
+		if names["MyHTTPProxy"] != "httpclient" || names["MyServerTunnel"] != "server" || len(names) != 2 {
+			t.Errorf("expected MyHTTPProxy and MyServerTunnel as distinct keys, got %v", names)
+		}
+	})
+
+	t.Run("duplicate names name both files", func(t *testing.T) {
+		outputFile := filepath.Join(t.TempDir(), "tunnels.yaml")
+		err := makeApp().Run([]string{
+			"go-i2ptunnel-config", "--merge", "-o", outputFile,
+			"../examples/server.properties", "../examples/server.conf",
+		})
+		if err == nil {
+			t.Fatal("expected duplicate name error, got nil")
+		}
+		for _, want := range []string{"MyServerTunnel", "server.properties", "server.conf"} {
+			if !strings.Contains(err.Error(), want) {
+				t.Errorf("error %q should mention %q", err, want)
+			}
+		}
+	})
+
+	t.Run("existing output needs force", func(t *testing.T) {
+		outputFile := filepath.Join(t.TempDir(), "tunnels.yaml")
+		if err := os.WriteFile(outputFile, []byte("keep me\n"), 0o644); err != nil {
+			t.Fatalf("setup: %v", err)
+		}
+		args := []string{"go-i2ptunnel-config", "--merge", "-o", outputFile, "../examples/server.conf"}
+		err := makeApp().Run(args)
+		if err == nil || !strings.Contains(err.Error(), "already exists") {
+			t.Errorf("expected existing output error, got: %v", err)
+		}
+		if data, _ := os.ReadFile(outputFile); string(data) != "keep me\n" {
+			t.Errorf("--merge without --force replaced the output:\n%s", data)
+		}
+		if err := makeApp().Run(append(args[:2:2], append([]string{"--force"}, args[2:]...)...)); err != nil {
+			t.Fatalf("--merge --force error = %v", err)
+		}
+		if data, _ := os.ReadFile(outputFile); !strings.Contains(string(data), "MyServerTunnel") {
+			t.Errorf("--merge --force did not write the merged tunnels:\n%s", data)
+		}
+	})
+
+	t.Run("requires output unless dry-run", func(t *testing.T) {
+		err := makeApp().Run([]string{"go-i2ptunnel-config", "--merge", "../examples/server.conf"})
+		if err == nil || !strings.Contains(err.Error(), "--merge requires --output") {
+			t.Errorf("expected missing output error, got: %v", err)
+		}
+	})
+}
+
+// TestVerifyOutput checks that --verify accepts faithful output in every format
+// and catches output from a generator that drops or rewrites a field.
+func TestVerifyOutput(t *testing.T) {
+	newConfig := func() *TunnelConfig {
+		return &TunnelConfig{
+			Name:      "proxy",
+			Type:      "httpclient",
+			Interface: "127.0.0.1",
+			Port:      4444,
+			I2CP:      map[string]interface{}{"leaseSetEncType": []interface{}{"4", "0"}},
+			Inbound:   map[string]interface{}{"length": 2},
+		}
+	}
+	conv := &Converter{}
+
+	for _, format := range []string{"yaml", "ini", "properties"} {
+		t.Run("faithful "+format, func(t *testing.T) {
+			config := newConfig()
+			out, err := conv.generateOutput(config, format)
+			if err != nil {
+				t.Fatalf("generateOutput() error = %v", err)
+			}
+			if err := verifyOutput(config, out, format, conv); err != nil {
+				t.Errorf("verifyOutput() error = %v", err)
+			}
+		})
+	}
+
+	buggy := []struct {
+		name   string
+		format string
+		mangle func(string) string
+		want   string
+	}{
+		{
+			name:   "dropped inbound option",
+			format: "properties",
+			mangle: func(s string) string { return strings.Replace(s, "option.inbound.length=2\n", "", 1) },
+			want:   "- inbound.length: 2",
+		},
+		{
+			name:   "wrong port",
+			format: "ini",
+			mangle: func(s string) string { return strings.Replace(s, "port = 4444", "port = 4445", 1) },
+			want:   "~ port: 4444 -> 4445",
+		},
+		{
+			name:   "unparseable output",
+			format: "yaml",
+			mangle: func(s string) string { return s + "\t: [" },
+			want:   "does not parse",
+		},
+	}
+	for _, tt := range buggy {
+		t.Run(tt.name, func(t *testing.T) {
+			config := newConfig()
+			out, err := conv.generateOutput(config, tt.format)
+			if err != nil {
+				t.Fatalf("generateOutput() error = %v", err)
+			}
+			err = verifyOutput(config, []byte(tt.mangle(string(out))), tt.format, conv)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("verifyOutput() error = %v, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+}
+
+// TestConvertCommand_Verify runs a real conversion with --verify.
+func TestConvertCommand_Verify(t *testing.T) {
+	dir := t.TempDir()
+	inputFile := filepath.Join(dir, "proxy.yaml")
+	yamlContent := "tunnels:\n  proxy:\n    type: httpclient\n    interface: 127.0.0.1\n    port: 4444\n    i2cp:\n      leaseSetEncType: [4, 0]\n"
+	if err := os.WriteFile(inputFile, []byte(yamlContent), 0o644); err != nil {
+		t.Fatalf("setup: %v", err)
+	}
+
+	app := makeStdinApp()
+	app.Flags = append(app.Flags, &cli.BoolFlag{Name: "verify"})
+	outputFile := filepath.Join(dir, "proxy.conf")
+	if err := app.Run([]string{"go-i2ptunnel-config", "--verify", "--out-format", "ini", "-o", outputFile, inputFile}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	data, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatalf("output not written: %v", err)
+	}
+	if !strings.Contains(string(data), "i2cp.leaseSetEncType = 4, 0") {
+		t.Errorf("YAML list not written as an INI list:\n%s", data)
+	}
+}
+
+// TestConvertCommand_LineEndings checks that --line-endings crlf writes CRLF
+// newlines in every output format and that the result parses back unchanged.
+func TestConvertCommand_LineEndings(t *testing.T) {
+	dir := t.TempDir()
+	inputFile := filepath.Join(dir, "proxy.yaml")
+	yamlContent := "tunnels:\n  proxy:\n    type: httpclient\n    interface: 127.0.0.1\n    port: 4444\n    inbound:\n      length: 2\n"
+	if err := os.WriteFile(inputFile, []byte(yamlContent), 0o644); err != nil {
+		t.Fatalf("setup: %v", err)
+	}
+	original, err := (&Converter{}).ParseInput([]byte(yamlContent), "yaml")
+	if err != nil {
+		t.Fatalf("setup parse: %v", err)
+	}
+
+	for _, format := range []string{"yaml", "ini", "properties"} {
+		t.Run(format, func(t *testing.T) {
+			app := makeStdinApp()
+			app.Flags = append(app.Flags, &cli.StringFlag{Name: "line-endings", Value: "lf"})
+			outputFile := filepath.Join(dir, "out-"+format)
+			err := app.Run([]string{"go-i2ptunnel-config", "--line-endings", "crlf", "--out-format", format, "-o", outputFile, inputFile})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			data, err := os.ReadFile(outputFile)
+			if err != nil {
+				t.Fatalf("output not written: %v", err)
+			}
+			if strings.Count(string(data), "\n") != strings.Count(string(data), "\r\n") {
+				t.Errorf("expected only CRLF newlines, got %q", data)
+			}
+			reparsed, err := (&Converter{}).ParseInput(data, format)
+			if err != nil {
+				t.Fatalf("CRLF output does not parse: %v", err)
+			}
+			if diffs := Diff(original, reparsed); len(diffs) > 0 {
+				t.Errorf("CRLF output changed the config: %v", diffs)
+			}
+		})
+	}
+
+	t.Run("invalid style", func(t *testing.T) {
+		app := makeStdinApp()
+		app.Flags = append(app.Flags, &cli.StringFlag{Name: "line-endings", Value: "lf"})
+		err := app.Run([]string{"go-i2ptunnel-config", "--line-endings", "cr", "--dry-run", inputFile})
+		if err == nil || !strings.Contains(err.Error(), "unsupported line ending") {
+			t.Errorf("expected unsupported line ending error, got %v", err)
+		}
+	})
+}
+
+// TestConvertCommand_GroupByType verifies that --group-by-type tallies the
+// converted files of a mixed-type batch per tunnel type.
+func TestConvertCommand_GroupByType(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"a.properties": "name=a\ntype=httpclient\ninterface=127.0.0.1\nlistenPort=8080\n",
+		"b.properties": "name=b\ntype=httpclient\ninterface=127.0.0.1\nlistenPort=8081\n",
+		"c.properties": "name=c\ntype=httpserver\ntargetHost=127.0.0.1\ntargetPort=80\n",
+		"d.properties": "name=d\ntype=sockstunnel\ninterface=127.0.0.1\nlistenPort=1080\n",
+		"e.properties": "name=e\ntype=httpclient\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("setup: %v", err)
+		}
+	}
+
+	app := makeStdinApp()
+	app.Flags = append(app.Flags, &cli.BoolFlag{Name: "group-by-type"})
+	out := captureOutput(t, &os.Stdout, func() {
+		// e.properties has no port, so the batch reports one failure.
+		if err := app.Run([]string{"go-i2ptunnel-config", "--batch", "--dry-run", "--group-by-type", filepath.Join(dir, "*.properties")}); err == nil {
+			t.Error("expected an error for the invalid file")
+		}
+	})
+
+	want := "By type: 2 httpclient, 1 httpserver, 1 sockstunnel\n"
+	if !strings.Contains(out, want) {
+		t.Errorf("expected %q in output:\n%s", want, out)
+	}
+}
+
+// TestSplitFileList checks that a --files-from list is split on newlines, or on
+// NUL bytes when it has any, with blank entries dropped.
+func TestSplitFileList(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []string
+	}{
+		{"newline", "a.config\nb.config\n", []string{"a.config", "b.config"}},
+		{"crlf and blank lines", "a.config\r\n\r\nb.config", []string{"a.config", "b.config"}},
+		{"nul", "my tunnel.config\x00b.config\x00", []string{"my tunnel.config", "b.config"}},
+		{"nul keeps newlines in names", "odd\nname.config\x00", []string{"odd\nname.config"}},
+		{"empty", "", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := splitFileList([]byte(tt.input)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("splitFileList(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestConvertCommand_FilesFrom pipes a list of paths through stdin and checks
+// that every listed file is converted.
+func TestConvertCommand_FilesFrom(t *testing.T) {
+	valid := "name=test-tunnel\ntype=httpclient\ninterface=127.0.0.1\nlistenPort=8080\n"
+
+	tests := []struct {
+		name  string
+		files []string
+		sep   string
+	}{
+		{"newline delimited", []string{"a.properties", "b.properties"}, "\n"},
+		{"nul delimited with spaces", []string{"my tunnel.properties", "other tunnel.properties"}, "\x00"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			var list strings.Builder
+			for _, name := range tt.files {
+				path := filepath.Join(dir, name)
+				if err := os.WriteFile(path, []byte(valid), 0o644); err != nil {
+					t.Fatalf("setup: %v", err)
+				}
+				list.WriteString(path + tt.sep)
+			}
+
+			pr, pw, err := os.Pipe()
+			if err != nil {
+				t.Fatalf("pipe: %v", err)
+			}
+			go func() {
+				pw.WriteString(list.String())
+				pw.Close()
+			}()
+			origStdin := os.Stdin
+			os.Stdin = pr
+			t.Cleanup(func() { os.Stdin = origStdin; pr.Close() })
+
+			app := makeStdinApp()
+			app.Flags = append(app.Flags, &cli.StringFlag{Name: "files-from"})
+			if err := app.Run([]string{"go-i2ptunnel-config", "--files-from", "-"}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for _, name := range tt.files {
+				out := filepath.Join(dir, strings.TrimSuffix(name, ".properties")+".yaml")
+				if _, err := os.Stat(out); err != nil {
+					t.Errorf("expected %s to be written: %v", out, err)
+				}
+			}
+		})
+	}
+
+	t.Run("empty list", func(t *testing.T) {
+		list := filepath.Join(t.TempDir(), "list.txt")
+		if err := os.WriteFile(list, []byte("\n"), 0o644); err != nil {
+			t.Fatalf("setup: %v", err)
+		}
+		app := makeStdinApp()
+		app.Flags = append(app.Flags, &cli.StringFlag{Name: "files-from"})
+		err := app.Run([]string{"go-i2ptunnel-config", "--files-from", list})
+		if err == nil || !strings.Contains(err.Error(), "is empty") {
+			t.Errorf("expected empty list error, got %v", err)
+		}
+	})
+}
+
+// TestConvertCommand_Normalize checks that --normalize rewrites an INI file in
+// place with sorted keys, true/false booleans, and comma lists without spaces
+// but no Java I2P defaults, that a second run changes nothing, and that it
+// refuses --minimal.
+func TestConvertCommand_Normalize(t *testing.T) {
+	input := "[proxy]\ntype = http\nport = 4480\ni2cp.reduceOnIdle = yes\ninbound.quantity = 3\ni2cp.delayOpen = no\naccesslist = b.b32.i2p , a.b32.i2p\noutbound.length = 2\n"
+	makeApp := func() *cli.App {
+		app := makeStdinApp()
+		app.Flags = append(app.Flags,
+			&cli.BoolFlag{Name: "normalize"},
+			&cli.BoolFlag{Name: "minimal"},
+			&cli.BoolFlag{Name: "in-place"},
+		)
+		return app
+	}
+	dir := t.TempDir()
+	inputFile := filepath.Join(dir, "proxy.conf")
+	if err := os.WriteFile(inputFile, []byte(input), 0o644); err != nil {
+		t.Fatalf("setup: %v", err)
+	}
+
+	if err := makeApp().Run([]string{"go-i2ptunnel-config", "--normalize", inputFile}); err != nil {
+		t.Fatalf("normalize error = %v", err)
+	}
+	out, err := os.ReadFile(inputFile)
+	if err != nil {
+		t.Fatalf("failed to read normalized file: %v", err)
+	}
+	// Lines that must appear in this order: sorted within each group, with
+	// true/false booleans and comma lists without spaces
+	want := []string{
+		"[proxy]",
+		"i2cp.delayOpen = false",
+		"i2cp.reduceOnIdle = true",
+		"accesslist = b.b32.i2p,a.b32.i2p",
+		"inbound.quantity = 3",
+		"outbound.length = 2",
+	}
+	rest := string(out)
+	for _, line := range want {
+		i := strings.Index(rest, line+"\n")
+		if i < 0 {
+			t.Fatalf("normalized output is missing %q in order:\n%s", line, out)
+		}
+		rest = rest[i+len(line):]
+	}
+	// The defaults table is Java I2P's; i2pd's differ (five tunnels per pool),
+	// so normalizing INI must not invent pool or I2CP options
+	for _, key := range []string{"inbound.length", "outbound.quantity", "i2cp.reduceIdleTime"} {
+		if strings.Contains(string(out), key) {
+			t.Errorf("normalized INI invented %s:\n%s", key, out)
+		}
+	}
+	if strings.Contains(string(out), "yes") || strings.Contains(string(out), "no\n") {
+		t.Errorf("normalized output still has yes/no booleans:\n%s", out)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "proxy.yaml")); !os.IsNotExist(err) {
+		t.Error("--normalize must rewrite the input instead of creating a YAML file")
+	}
+
+	// Normalizing a normalized file changes nothing
+	if err := makeApp().Run([]string{"go-i2ptunnel-config", "--normalize", inputFile}); err != nil {
+		t.Fatalf("second normalize error = %v", err)
+	}
+	again, err := os.ReadFile(inputFile)
+	if err != nil {
+		t.Fatalf("failed to read normalized file: %v", err)
+	}
+	if string(again) != string(out) {
+		t.Errorf("normalize is not idempotent:\nfirst:\n%s\nsecond:\n%s", out, again)
+	}
+
+	err = makeApp().Run([]string{"go-i2ptunnel-config", "--normalize", "--minimal", inputFile})
+	if err == nil || !strings.Contains(err.Error(), "--minimal") {
+		t.Errorf("--normalize with --minimal error = %v, want a conflict", err)
+	}
+}
+
+// TestConvertCommand_MinimalINI checks that --minimal keeps INI pool options
+// that only look like defaults: i2pd's pools default to five tunnels, so an
+// explicit "quantity = 2" is a real setting there.
+func TestConvertCommand_MinimalINI(t *testing.T) {
+	input := "[proxy]\ntype = http\nport = 4480\ninbound.quantity = 2\noutbound.quantity = 2\n"
+	app := makeStdinApp()
+	app.Flags = append(app.Flags, &cli.BoolFlag{Name: "minimal"})
+	dir := t.TempDir()
+	inputFile := filepath.Join(dir, "proxy.conf")
+	outputFile := filepath.Join(dir, "minimal.conf")
+	if err := os.WriteFile(inputFile, []byte(input), 0o644); err != nil {
+		t.Fatalf("setup: %v", err)
+	}
+
+	err := app.Run([]string{"go-i2ptunnel-config", "--minimal", "--out-format", "ini", "-o", outputFile, inputFile})
+	if err != nil {
+		t.Fatalf("--minimal error = %v", err)
+	}
+	out, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatalf("failed to read output: %v", err)
+	}
+	for _, line := range []string{"inbound.quantity = 2", "outbound.quantity = 2"} {
+		if !strings.Contains(string(out), line) {
+			t.Errorf("--minimal dropped %q from INI output:\n%s", line, out)
+		}
+	}
+}
+
+// TestConvertCommand_CanonicalCheck checks that --canonical-check accepts a
+// file in canonical form, with or without the generated section headers, and
+// names the first differing line of any other file without changing it.
+func TestConvertCommand_CanonicalCheck(t *testing.T) {
+	canonical := `name=web
+type=httpclient
+interface=127.0.0.1
+listenPort=4480
+
+# I2CP options
+option.i2cp.closeIdleTime=1800000
+option.i2cp.closeOnIdle=false
+option.i2cp.delayOpen=false
+option.i2cp.newDestOnResume=false
+option.i2cp.reduceIdleTime=1200000
+option.i2cp.reduceOnIdle=true
+option.i2cp.reduceQuantity=1
+
+# Tunnel options
+startOnLoad=true
+
+# Inbound tunnel options
+option.inbound.backupQuantity=0
+option.inbound.length=3
+option.inbound.lengthVariance=0
+option.inbound.quantity=2
+
+# Outbound tunnel options
+option.outbound.backupQuantity=0
+option.outbound.length=3
+option.outbound.lengthVariance=0
+option.outbound.quantity=2
+`
+	var headerless strings.Builder
+	for _, line := range strings.SplitAfter(canonical, "\n") {
+		if line != "\n" && !strings.HasPrefix(line, "#") {
+			headerless.WriteString(line)
+		}
+	}
+	makeApp := func() *cli.App {
+		app := makeStdinApp()
+		app.Flags = append(app.Flags, &cli.BoolFlag{Name: "canonical-check"})
+		return app
+	}
+
+	tests := []struct {
+		name    string
+		input   string
+		wantErr string // Empty when the input is canonical
+	}{
+		{name: "canonical", input: canonical},
+		{name: "yes boolean", input: strings.Replace(canonical, "reduceOnIdle=true", "reduceOnIdle=yes", 1), wantErr: `line 12 is "option.i2cp.reduceOnIdle=yes", want "option.i2cp.reduceOnIdle=true"`},
+		{name: "unsorted keys", input: strings.Replace(canonical, "name=web\ntype=httpclient\n", "type=httpclient\nname=web\n", 1), wantErr: `line 1 is "type=httpclient", want "name=web"`},
+		{name: "no section headers", input: headerless.String()},
+		{name: "missing default", input: strings.Replace(canonical, "option.i2cp.closeIdleTime=1800000\n", "", 1), wantErr: `line 7 is "option.i2cp.closeOnIdle=false", want "option.i2cp.closeIdleTime=1800000"`},
+		{name: "missing final newline", input: strings.TrimSuffix(canonical, "\n"), wantErr: "line 28"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inputFile := filepath.Join(t.TempDir(), "web.properties")
+			if err := os.WriteFile(inputFile, []byte(tt.input), 0o644); err != nil {
+				t.Fatalf("setup: %v", err)
+			}
+			err := makeApp().Run([]string{"go-i2ptunnel-config", "--canonical-check", inputFile})
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("canonical input rejected: %v", err)
+				}
+			} else if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want it to contain %s", err, tt.wantErr)
+			}
+			if data, _ := os.ReadFile(inputFile); string(data) != tt.input {
+				t.Errorf("--canonical-check modified the input:\n%s", data)
+			}
+			if _, err := os.Stat(filepath.Join(filepath.Dir(inputFile), "web.yaml")); !os.IsNotExist(err) {
+				t.Error("--canonical-check must not write an output file")
+			}
+		})
+	}
+}
+
+// TestConvertCommand_NormalizeThenCanonicalCheck checks that a file written
+// by --normalize passes --canonical-check in each format.
+func TestConvertCommand_NormalizeThenCanonicalCheck(t *testing.T) {
+	inputs := map[string]string{
+		"web.properties": "type=httpclient\nname=web\nlistenPort=4480\noption.i2cp.reduceOnIdle=yes\noption.inbound.length=2\n",
+		"web.conf":       "[web]\ntype = http\nport = 4480\ni2cp.reduceOnIdle = yes\ninbound.length = 2\n",
+		"web.yaml":       "tunnels:\n  web:\n    type: httpclient\n    port: 4480\n    i2cp:\n      reduceOnIdle: yes\n",
+	}
+	for name, content := range inputs {
+		t.Run(name, func(t *testing.T) {
+			inputFile := filepath.Join(t.TempDir(), name)
+			if err := os.WriteFile(inputFile, []byte(content), 0o644); err != nil {
+				t.Fatalf("setup: %v", err)
+			}
+			app := makeStdinApp()
+			app.Flags = append(app.Flags, &cli.BoolFlag{Name: "normalize"}, &cli.BoolFlag{Name: "canonical-check"})
+			if err := app.Run([]string{"go-i2ptunnel-config", "--canonical-check", inputFile}); err == nil {
+				t.Fatal("--canonical-check accepted the input before --normalize")
+			}
+			if err := app.Run([]string{"go-i2ptunnel-config", "--normalize", inputFile}); err != nil {
+				t.Fatalf("--normalize error = %v", err)
+			}
+			if err := app.Run([]string{"go-i2ptunnel-config", "--canonical-check", inputFile}); err != nil {
+				t.Errorf("--canonical-check rejected the normalized file: %v", err)
+			}
+		})
+	}
+}
+
+// TestConvertCommand_Name checks that --name renames the tunnel in the
+// output without touching the input, and is refused where it does not apply.
+func TestConvertCommand_Name(t *testing.T) {
+	content := "name=Old Tunnel\ntype=httpclient\nlistenPort=4444\n"
+	dir := t.TempDir()
+	inputFile := filepath.Join(dir, "web.properties")
+	if err := os.WriteFile(inputFile, []byte(content), 0o644); err != nil {
+		t.Fatalf("setup: %v", err)
+	}
+	makeApp := func() *cli.App {
+		app := makeStdinApp()
+		app.Flags = append(app.Flags, &cli.StringFlag{Name: "name"}, &cli.BoolFlag{Name: "merge"})
+		return app
+	}
+
+	outputFile := filepath.Join(dir, "web.ini")
+	if err := makeApp().Run([]string{"go-i2ptunnel-config", "--name", "web-proxy", "--out-format", "ini", "-o", outputFile, inputFile}); err != nil {
+		t.Fatalf("convert with --name error = %v", err)
+	}
+	out, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if !strings.HasPrefix(string(out), "[web-proxy]\n") || strings.Contains(string(out), "Old Tunnel") {
+		t.Errorf("output not renamed to web-proxy:\n%s", out)
+	}
+	if in, _ := os.ReadFile(inputFile); string(in) != content {
+		t.Errorf("--name modified the input file:\n%s", in)
+	}
+
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{"invalid characters", []string{"--name", "web proxy", "--dry-run", inputFile}, "invalid characters"},
+		{"empty", []string{"--name", "", "--dry-run", inputFile}, "must not be empty"},
+		{"batch", []string{"--name", "web", "--batch", "--dry-run", filepath.Join(dir, "*.properties")}, "--name"},
+		{"split", []string{"--name", "web", "--split", "--dry-run", inputFile}, "--name"},
+		{"merge", []string{"--name", "web", "--merge", "--dry-run", inputFile}, "--name"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := makeApp().Run(append([]string{"go-i2ptunnel-config"}, tt.args...))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want it to mention %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestConvertCommand_INIKeyfileWarning checks that converting a persistent
+// tunnel without a keyfile to INI warns in strict mode whatever the input
+// format, and fails with --warnings-as-errors.
+func TestConvertCommand_INIKeyfileWarning(t *testing.T) {
+	inputs := map[string]string{
+		"web.properties": "name=web\ntype=httpclient\nlistenPort=8118\noption.persistentClientKey=true\n",
+		"web.yaml":       "tunnels:\n  web:\n    type: httpclient\n    port: 8118\n    persistentKey: true\n",
+	}
+	for name, content := range inputs {
+		t.Run(name, func(t *testing.T) {
+			inputFile := filepath.Join(t.TempDir(), name)
+			if err := os.WriteFile(inputFile, []byte(content), 0o644); err != nil {
+				t.Fatalf("setup: %v", err)
+			}
+			run := func(extra ...string) (string, error) {
+				app := makeStdinApp()
+				app.Flags = append(app.Flags, &cli.BoolFlag{Name: "warnings-as-errors"})
+				args := append([]string{"go-i2ptunnel-config", "--out-format", "ini", "--dry-run"}, extra...)
+				var err error
+				stderr := captureOutput(t, &os.Stderr, func() {
+					captureOutput(t, &os.Stdout, func() {
+						err = app.Run(append(args, inputFile))
+					})
+				})
+				return stderr, err
+			}
+
+			stderr, err := run("--strict")
+			if err != nil {
+				t.Fatalf("strict conversion error = %v", err)
+			}
+			if !strings.Contains(stderr, "persistent tunnel 'web' names no keyfile") {
+				t.Errorf("no keyfile warning on stderr: %q", stderr)
+			}
+			if stderr, _ := run(); strings.Contains(stderr, "names no keyfile") {
+				t.Errorf("keyfile warning without --strict: %q", stderr)
+			}
+			if _, err := run("--strict", "--warnings-as-errors"); err == nil || !strings.Contains(err.Error(), "names no keyfile") {
+				t.Errorf("--warnings-as-errors error = %v, want the keyfile warning", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,317 @@
+package i2pconv_test
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	i2pconv "github.com/go-i2p/go-i2ptunnel-config/i2pconv"
+)
+
+// TestConverter_Validate exercises the exported validation API the way an
+// embedding application would, without generating any output.
+func TestConverter_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		strict  bool
+		config  *i2pconv.TunnelConfig
+		format  string
+		wantErr bool
+	}{
+		{
+			name:   "valid client",
+			config: &i2pconv.TunnelConfig{Name: "proxy", Type: "httpclient", Port: 4444},
+			format: "yaml",
+		},
+		{
+			name:    "client without port",
+			config:  &i2pconv.TunnelConfig{Name: "proxy", Type: "httpclient"},
+			format:  "yaml",
+			wantErr: true,
+		},
+		{
+			name:   "privileged port allowed when not strict",
+			config: &i2pconv.TunnelConfig{Name: "proxy", Type: "httpclient", Port: 80},
+		},
+		{
+			name:   "privileged port only warns when strict",
+			strict: true,
+			config: &i2pconv.TunnelConfig{Name: "proxy", Type: "httpclient", Port: 80},
+		},
+		{
+			name:    "unknown type rejected when strict",
+			strict:  true,
+			config:  &i2pconv.TunnelConfig{Name: "proxy", Type: "bogus", Port: 4444},
+			wantErr: true,
+		},
+		{
+			name:    "format rule applied",
+			strict:  true,
+			config:  &i2pconv.TunnelConfig{Name: "my.proxy", Type: "httpclient", Port: 4444},
+			format:  "properties",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := i2pconv.NewConverter(tt.strict).Validate(tt.config, tt.format)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestNewConverter_Strict checks that a strict converter only warns about a
+// privileged port unless WarningsAsErrors is set.
+func TestNewConverter_Strict(t *testing.T) {
+	config := &i2pconv.TunnelConfig{Name: "web", Type: "httpclient", Port: 80}
+
+	if err := i2pconv.NewConverter(false).Validate(config, ""); err != nil {
+		t.Errorf("non-strict converter rejected privileged port: %v", err)
+	}
+	if err := i2pconv.NewConverter(true).Validate(config, ""); err != nil {
+		t.Errorf("strict converter should only warn about a privileged port: %v", err)
+	}
+	strict := i2pconv.ConverterOptions{Strict: true, WarningsAsErrors: true}
+	if err := i2pconv.NewConverterWithOptions(strict).Validate(config, ""); err == nil {
+		t.Error("converter with WarningsAsErrors accepted privileged port")
+	}
+	if err := i2pconv.NewConverterWithOptions(i2pconv.ConverterOptions{}).Validate(config, ""); err != nil {
+		t.Errorf("zero-value options rejected privileged port: %v", err)
+	}
+}
+
+// TestConverter_ValidateWithWarnings checks that strict-mode warnings are
+// returned to the caller and that validation prints nothing to stderr.
+func TestConverter_ValidateWithWarnings(t *testing.T) {
+	config := &i2pconv.TunnelConfig{Name: "web", Type: "httpclient", Port: 80}
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("pipe: %v", err)
+	}
+	stderr := os.Stderr
+	os.Stderr = w
+	warnings, err := i2pconv.NewConverter(true).ValidateWithWarnings(config, "")
+	validateErr := i2pconv.NewConverter(true).Validate(config, "")
+	os.Stderr = stderr
+	w.Close()
+	printed, _ := io.ReadAll(r)
+
+	if err != nil || validateErr != nil {
+		t.Fatalf("ValidateWithWarnings() error = %v, Validate() error = %v", err, validateErr)
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "privileged") {
+		t.Errorf("warnings = %q, want the privileged port warning", warnings)
+	}
+	if len(printed) != 0 {
+		t.Errorf("validation printed to stderr: %q", printed)
+	}
+	if warnings, _ := i2pconv.NewConverter(false).ValidateWithWarnings(config, ""); len(warnings) != 0 {
+		t.Errorf("non-strict warnings = %q, want none", warnings)
+	}
+}
+
+// TestConverter_WriteConfig writes a config to a file whose format is taken
+// from its extension and checks it reads back unchanged.
+func TestConverter_WriteConfig(t *testing.T) {
+	conv := i2pconv.NewConverter(false)
+	config := &i2pconv.TunnelConfig{
+		Name:    "web",
+		Type:    "httpclient",
+		Port:    4444,
+		Inbound: map[string]interface{}{"length": 2},
+	}
+
+	path := filepath.Join(t.TempDir(), "web.yaml")
+	if err := conv.WriteConfig(config, path, ""); err != nil {
+		t.Fatalf("WriteConfig() error = %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	got, err := conv.ParseInput(data, "yaml")
+	if err != nil {
+		t.Fatalf("ParseInput() error = %v", err)
+	}
+	if diffs := i2pconv.Diff(config, got); len(diffs) != 0 {
+		t.Errorf("round trip through %s changed the config: %v", path, diffs)
+	}
+
+	if err := conv.WriteConfig(config, filepath.Join(t.TempDir(), "web.txt"), ""); err == nil {
+		t.Error("WriteConfig() with unknown extension and no format should fail")
+	}
+	if _, err := conv.Marshal(config, "toml"); err == nil {
+		t.Error("Marshal() with unsupported format should fail")
+	}
+}
+
+// TestConvertFile converts a properties file on disk to YAML with the
+// file-to-file API and checks the written tunnel.
+func TestConvertFile(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "web.properties")
+	content := "name=web\ntype=httpclient\nlistenPort=4444\noption.inbound.length=2\n"
+	if err := os.WriteFile(input, []byte(content), 0o644); err != nil {
+		t.Fatalf("setup: %v", err)
+	}
+
+	output := filepath.Join(dir, "web.yaml")
+	if err := i2pconv.ConvertFile(input, output, "", "", false); err != nil {
+		t.Fatalf("ConvertFile() error = %v", err)
+	}
+	data, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	got, err := i2pconv.NewConverter(false).ParseInput(data, "yaml")
+	if err != nil {
+		t.Fatalf("ParseInput() error = %v\n%s", err, data)
+	}
+	if got.Name != "web" || got.Type != "httpclient" || got.Port != 4444 {
+		t.Errorf("converted config = %+v, want web/httpclient/4444", got)
+	}
+	if fmt.Sprint(got.Inbound["length"]) != "2" {
+		t.Errorf("inbound.length = %v, want 2", got.Inbound["length"])
+	}
+
+	bad := filepath.Join(dir, "bad.properties")
+	if err := os.WriteFile(bad, []byte("name=bad\ntype=httpclient\n"), 0o644); err != nil {
+		t.Fatalf("setup: %v", err)
+	}
+	err = i2pconv.ConvertFile(bad, filepath.Join(dir, "bad.yaml"), "properties", "yaml", false)
+	var verr *i2pconv.ValidationError
+	if !errors.As(err, &verr) {
+		t.Errorf("ConvertFile() of a client without a port: got %v, want a ValidationError", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "bad.yaml")); !os.IsNotExist(err) {
+		t.Error("ConvertFile() wrote output for an invalid config")
+	}
+	if err := i2pconv.ConvertFile(input, filepath.Join(dir, "web.txt"), "", "", false); err == nil {
+		t.Error("ConvertFile() with unknown output extension and no format should fail")
+	}
+}
+
+// TestConverter_ConvertFileWithWarnings checks that the file-to-file API
+// returns the conversion warnings the command-line tool prints, and that
+// strict mode rejects a tunnel type the output router does not support.
+func TestConverter_ConvertFileWithWarnings(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "web.properties")
+	content := "name=web\ntype=httpclient\nlistenPort=4444\noption.i2cp.leaseSetPrivateKey=secret\n"
+	if err := os.WriteFile(input, []byte(content), 0o644); err != nil {
+		t.Fatalf("setup: %v", err)
+	}
+	warnings, err := i2pconv.NewConverter(false).ConvertFileWithWarnings(input, filepath.Join(dir, "web.yaml"), "", "")
+	if err != nil {
+		t.Fatalf("ConvertFileWithWarnings() error = %v", err)
+	}
+	if !strings.Contains(strings.Join(warnings, "\n"), "private key material") {
+		t.Errorf("warnings = %q, want the private key warning", warnings)
+	}
+
+	bidir := filepath.Join(dir, "bidir.properties")
+	content = "name=bidir\ntype=httpbidirserver\ntargetHost=127.0.0.1\ntargetPort=8080\nlistenPort=8081\n"
+	if err := os.WriteFile(bidir, []byte(content), 0o644); err != nil {
+		t.Fatalf("setup: %v", err)
+	}
+	output := filepath.Join(dir, "bidir.conf")
+	warnings, err = i2pconv.NewConverter(false).ConvertFileWithWarnings(bidir, output, "", "ini")
+	if err != nil {
+		t.Fatalf("ConvertFileWithWarnings() error = %v", err)
+	}
+	if !strings.Contains(strings.Join(warnings, "\n"), "not supported") {
+		t.Errorf("warnings = %q, want the unsupported type warning", warnings)
+	}
+	if err := os.Remove(output); err != nil {
+		t.Fatalf("Remove() error = %v", err)
+	}
+	if _, err := i2pconv.NewConverter(true).ConvertFileWithWarnings(bidir, output, "", "ini"); err == nil {
+		t.Error("strict ConvertFileWithWarnings() of an httpbidirserver to INI should fail")
+	}
+	if _, err := os.Stat(output); !os.IsNotExist(err) {
+		t.Error("strict ConvertFileWithWarnings() wrote output for an unsupported type")
+	}
+}
+
+// TestParseError_Accessors checks the line, format, content, reason, and
+// context accessors of a ParseError, and that ContextLines returns a copy.
+func TestParseError_Accessors(t *testing.T) {
+	input := "tunnels:\n  web:\n    name: web\n   type: httpclient\n    port: 4444\n"
+	_, err := i2pconv.NewConverter(false).ParseInput([]byte(input), "yaml")
+
+	var pe *i2pconv.ParseError
+	if !errors.As(err, &pe) {
+		t.Fatalf("ParseInput() error = %v, want a *ParseError", err)
+	}
+	if got := pe.LineNumber(); got != 3 {
+		t.Errorf("LineNumber() = %d, want 3", got)
+	}
+	if got := pe.FormatName(); got != "yaml" {
+		t.Errorf("FormatName() = %q, want \"yaml\"", got)
+	}
+	if got := pe.LineContent(); got != "    name: web" {
+		t.Errorf("LineContent() = %q", got)
+	}
+	if pe.Reason() == "" || strings.Contains(pe.Reason(), "line 3 (yaml format)") {
+		t.Errorf("Reason() = %q, want the bare message", pe.Reason())
+	}
+
+	lines := pe.ContextLines()
+	if len(lines) == 0 || !strings.Contains(strings.Join(lines, "\n"), "name: web") {
+		t.Fatalf("ContextLines() = %q, want the lines around line 3", lines)
+	}
+	lines[0] = "changed"
+	if pe.ContextLines()[0] == "changed" {
+		t.Error("ContextLines() returned the error's own slice")
+	}
+}
+
+// TestConverterOptions_Validate checks that unsupported CanonicalTypes and
+// INIDialect values are reported and that both are matched without regard to
+// case.
+func TestConverterOptions_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    i2pconv.ConverterOptions
+		wantErr string
+	}{
+		{name: "zero value", opts: i2pconv.ConverterOptions{}},
+		{name: "upper case", opts: i2pconv.ConverterOptions{CanonicalTypes: "I2PD", INIDialect: "Prefixed"}},
+		{name: "bad canonical types", opts: i2pconv.ConverterOptions{CanonicalTypes: "router"}, wantErr: "CanonicalTypes"},
+		{name: "bad ini dialect", opts: i2pconv.ConverterOptions{INIDialect: "tunnels"}, wantErr: "INIDialect"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.opts.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Validate() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate() error = %v, want one naming %s", err, tt.wantErr)
+			}
+		})
+	}
+
+	conv := i2pconv.NewConverterWithOptions(i2pconv.ConverterOptions{CanonicalTypes: "I2PD", INIDialect: "PREFIXED"})
+	out, err := conv.Convert([]byte("name=web\ntype=httpclient\nlistenPort=4444\noption.inbound.length=2\n"), "properties", "ini")
+	if err != nil {
+		t.Fatalf("Convert() error = %v", err)
+	}
+	for _, want := range []string{"type = http\n", "tunnels.inbound.length = 2\n"} {
+		if !strings.Contains(string(out), want) {
+			t.Errorf("upper-case options not applied, output lacks %q:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package i2pconv
+
+import (
+	"fmt"
+	"strings"
+)
+
+// cryptoTypeMapping describes how an i2pd cryptotype carries over to Java
+// I2P. mapped is the leaseSetEncType value to write, or "" when the option is
+// dropped; lossy explains why nothing, or less than the original, survives.
+type cryptoTypeMapping struct {
+	mapped string
+	lossy  string
+}
+
+// mapCryptoType maps the i2pd cryptotype option to Java I2P's
+// i2cp.leaseSetEncType. i2pd sets the destination's encryption type with
+// cryptotype, while Java I2P only selects the lease set encryption, so the
+// lease set type is the closest equivalent. An explicit leaseSetEncType in
+// the config always wins.
+func mapCryptoType(config *TunnelConfig) (cryptoTypeMapping, bool) {
+	v, ok := config.Tunnel["cryptotype"]
+	if !ok {
+		return cryptoTypeMapping{}, false
+	}
+	value := strings.TrimSpace(formatPropertyValue(v))
+	if existing, ok := config.I2CP["leaseSetEncType"]; ok {
+		for _, t := range optionListValues(existing) {
+			if t == value {
+				return cryptoTypeMapping{}, true
+			}
+		}
+		return cryptoTypeMapping{lossy: fmt.Sprintf("cryptotype %s was dropped because i2cp.leaseSetEncType is already %s", value, formatPropertyValue(existing))}, true
+	}
+	if !isKnownLeaseSetEncType(value) {
+		return cryptoTypeMapping{lossy: fmt.Sprintf("cryptotype %s has no Java I2P equivalent and was dropped", value)}, true
+	}
+	return cryptoTypeMapping{mapped: value}, true
+}
+
+// optionListValues returns the entries of a comma-separated option value.
+func optionListValues(v interface{}) []string {
+	var values []string
+	for _, s := range strings.Split(formatPropertyValue(v), ",") {
+		values = append(values, strings.TrimSpace(s))
+	}
+	return values
+}
@@ -0,0 +1,112 @@
+package i2pconv
+
+// defaultClientInterface is the bind address routers use for client tunnels
+// when no interface is configured.
+const defaultClientInterface = "127.0.0.1"
+
+// i2cpDefaults holds the router default for common I2CP options (keys without
+// the "i2cp." prefix). Values match the Java I2P router defaults.
+var i2cpDefaults = map[string]interface{}{
+	"reduceOnIdle":    false,
+	"reduceIdleTime":  1200000,
+	"reduceQuantity":  1,
+	"closeOnIdle":     false,
+	"closeIdleTime":   1800000,
+	"delayOpen":       false,
+	"newDestOnResume": false,
+}
+
+// tunnelPoolDefaults holds the router default for inbound and outbound tunnel
+// pool options. The same defaults apply to both directions.
+var tunnelPoolDefaults = map[string]interface{}{
+	"length":         3,
+	"lengthVariance": 0,
+	"quantity":       2,
+	"backupQuantity": 0,
+}
+
+// tunnelOptionDefaults holds the default for options stored in the Tunnel map.
+var tunnelOptionDefaults = map[string]interface{}{
+	"startOnLoad": true,
+}
+
+// routerDefaults holds the option defaults of the router that reads a format.
+type routerDefaults struct {
+	i2cp   map[string]interface{}
+	tunnel map[string]interface{}
+	pool   map[string]interface{}
+}
+
+// formatDefaults returns the option defaults of the router behind format. The
+// tables above are Java I2P's, which reads properties and yaml. i2pd (ini)
+// has defaults of its own, five tunnels per pool instead of two among them,
+// so no ini option is treated as a default.
+func formatDefaults(format string) routerDefaults {
+	if format == "ini" {
+		return routerDefaults{}
+	}
+	return routerDefaults{i2cp: i2cpDefaults, tunnel: tunnelOptionDefaults, pool: tunnelPoolDefaults}
+}
+
+// stripDefaults removes every option whose value equals the default of the
+// router behind format, along with a client interface that matches the
+// default bind address. Name, type, and the fields validation requires (port,
+// target) are always kept, so the result is the smallest config that still
+// describes the same tunnel.
+func stripDefaults(config *TunnelConfig, format string) {
+	if TunnelType(config.Type).IsClient() && config.Interface == defaultClientInterface {
+		config.Interface = ""
+	}
+	defaults := formatDefaults(format)
+	stripDefaultOptions(config.I2CP, defaults.i2cp)
+	stripDefaultOptions(config.Tunnel, defaults.tunnel)
+	stripDefaultOptions(config.Inbound, defaults.pool)
+	stripDefaultOptions(config.Outbound, defaults.pool)
+}
+
+// stripDefaultOptions deletes the entries of m whose value matches defaults.
+func stripDefaultOptions(m, defaults map[string]interface{}) {
+	for k, v := range m {
+		if def, ok := defaults[k]; ok && optionValuesEqual(v, def) {
+			delete(m, k)
+		}
+	}
+}
+
+// optionValuesEqual compares two option values by their serialised form, so
+// that 3 and "3", or false and "false", are treated as the same value.
+func optionValuesEqual(a, b interface{}) bool {
+	return formatPropertyValue(a) == formatPropertyValue(b)
+}
+
+// fillDefaults is the inverse of stripDefaults: every option with a known
+// default in the router behind format that config leaves unset is set to that
+// default, and a client tunnel without an interface gets the default bind
+// address.
+func fillDefaults(config *TunnelConfig, format string) {
+	if TunnelType(config.Type).IsClient() && config.Interface == "" {
+		config.Interface = defaultClientInterface
+	}
+	defaults := formatDefaults(format)
+	config.I2CP = fillDefaultOptions(config.I2CP, defaults.i2cp)
+	config.Tunnel = fillDefaultOptions(config.Tunnel, defaults.tunnel)
+	config.Inbound = fillDefaultOptions(config.Inbound, defaults.pool)
+	config.Outbound = fillDefaultOptions(config.Outbound, defaults.pool)
+}
+
+// fillDefaultOptions adds the entries of defaults missing from m, allocating
+// m when it is nil and there is anything to add, and returns it.
+func fillDefaultOptions(m, defaults map[string]interface{}) map[string]interface{} {
+	if len(defaults) == 0 {
+		return m
+	}
+	if m == nil {
+		m = make(map[string]interface{}, len(defaults))
+	}
+	for k, def := range defaults {
+		if _, ok := m[k]; !ok {
+			m[k] = def
+		}
+	}
+	return m
+}
@@ -0,0 +1,81 @@
+package i2pconv
+
+import (
+	"testing"
+)
+
+// TestStripDefaults verifies that default-valued options are removed while
+// name, type, required fields, and non-default values are kept.
+func TestStripDefaults(t *testing.T) {
+	input := `name=bloated
+type=httpclient
+interface=127.0.0.1
+listenPort=4444
+option.i2cp.reduceOnIdle=false
+option.i2cp.closeIdleTime=1800000
+option.i2cp.reduceIdleTime=600000
+option.i2ptunnel.startOnLoad=true
+option.inbound.length=3
+option.inbound.quantity=2
+option.outbound.length=2
+option.outbound.quantity=2
+`
+	conv := &Converter{}
+	config, err := conv.ParseInput([]byte(input), "properties")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+
+	stripDefaults(config, "properties")
+
+	if config.Name != "bloated" || config.Type != "httpclient" || config.Port != 4444 {
+		t.Errorf("name/type/port must be kept, got %q %q %d", config.Name, config.Type, config.Port)
+	}
+	if config.Interface != "" {
+		t.Errorf("default client interface should be dropped, got %q", config.Interface)
+	}
+	if len(config.I2CP) != 1 || config.I2CP["reduceIdleTime"] != 600000 {
+		t.Errorf("expected only non-default reduceIdleTime in I2CP, got %v", config.I2CP)
+	}
+	if len(config.Tunnel) != 0 {
+		t.Errorf("expected empty Tunnel map, got %v", config.Tunnel)
+	}
+	if len(config.Inbound) != 0 {
+		t.Errorf("expected empty Inbound map, got %v", config.Inbound)
+	}
+	if len(config.Outbound) != 1 || config.Outbound["length"] != 2 {
+		t.Errorf("expected only outbound.length=2, got %v", config.Outbound)
+	}
+
+	out, err := conv.generateOutput(config, "properties")
+	if err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	want := "name=bloated\ntype=httpclient\nlistenPort=4444\n\n# I2CP options\noption.i2cp.reduceIdleTime=600000\n\n# Outbound tunnel options\noption.outbound.length=2\n"
+	if string(out) != want {
+		t.Errorf("minimal output:\n%s\nwant:\n%s", out, want)
+	}
+	if err := conv.validate(config); err != nil {
+		t.Errorf("minimal config should still validate: %v", err)
+	}
+}
+
+// TestStripDefaults_ServerKeepsInterface verifies that the default-interface
+// rule only applies to client tunnels.
+func TestStripDefaults_ServerKeepsInterface(t *testing.T) {
+	config := &TunnelConfig{Name: "srv", Type: "server", Interface: "127.0.0.1", Target: "127.0.0.1:80"}
+	stripDefaults(config, "properties")
+	if config.Interface != "127.0.0.1" {
+		t.Errorf("server interface should be kept, got %q", config.Interface)
+	}
+}
+
+// TestOptionValuesEqual checks the string-normalised comparison.
+func TestOptionValuesEqual(t *testing.T) {
+	if !optionValuesEqual(3, "3") || !optionValuesEqual(false, "false") {
+		t.Error("expected int/string and bool/string forms to compare equal")
+	}
+	if optionValuesEqual(3, 2) || optionValuesEqual("a", "A") {
+		t.Error("expected distinct values to compare unequal")
+	}
+}
@@ -0,0 +1,98 @@
+package i2pconv
+
+import (
+	"net"
+	"strconv"
+	"strings"
+)
+
+// b32AddressLength is the length of the base32 label of a standard .b32.i2p
+// address (a SHA-256 hash). Encrypted leaseset addresses are longer, at least
+// b33AddressMinLength characters.
+const (
+	b32AddressLength    = 52
+	b33AddressMinLength = 56
+)
+
+// minBase64DestinationLength is the length of the shortest base64-encoded
+// destination: 387 bytes of public keys and a null certificate.
+const minBase64DestinationLength = 516
+
+// isI2PDestination reports whether s names an I2P destination: a .b32.i2p
+// address, an .i2p hostname, or a full base64 destination. An optional
+// ":port" suffix is allowed.
+func isI2PDestination(s string) bool {
+	host := stripDestinationPort(s)
+	lower := strings.ToLower(host)
+	switch {
+	case strings.HasSuffix(lower, ".b32.i2p"):
+		return isBase32Label(strings.TrimSuffix(lower, ".b32.i2p"))
+	case strings.HasSuffix(lower, ".i2p"):
+		return isHostnameLabels(strings.TrimSuffix(lower, ".i2p"))
+	default:
+		return isBase64Destination(host)
+	}
+}
+
+// isLocalAddress reports whether s is an IP address or localhost, with an
+// optional ":port" suffix.
+func isLocalAddress(s string) bool {
+	host := stripDestinationPort(s)
+	return host == "localhost" || net.ParseIP(host) != nil
+}
+
+// stripDestinationPort removes a trailing ":port" from s.
+func stripDestinationPort(s string) string {
+	if host, port, ok := strings.Cut(s, ":"); ok {
+		if _, err := strconv.Atoi(port); err == nil {
+			return host
+		}
+	}
+	return s
+}
+
+// isBase32Label reports whether label is a valid .b32.i2p label.
+func isBase32Label(label string) bool {
+	if len(label) != b32AddressLength && len(label) < b33AddressMinLength {
+		return false
+	}
+	for _, r := range label {
+		if !(r >= 'a' && r <= 'z') && !(r >= '2' && r <= '7') {
+			return false
+		}
+	}
+	return true
+}
+
+// isHostnameLabels reports whether name is one or more dot-separated
+// hostname labels.
+func isHostnameLabels(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, label := range strings.Split(name, ".") {
+		if label == "" || strings.HasPrefix(label, "-") || strings.HasSuffix(label, "-") {
+			return false
+		}
+		for _, r := range label {
+			if !(r >= 'a' && r <= 'z') && !(r >= '0' && r <= '9') && r != '-' {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// isBase64Destination reports whether s looks like a base64 destination in
+// I2P's alphabet, which uses '-' and '~' instead of '+' and '/'.
+func isBase64Destination(s string) bool {
+	if len(s) < minBase64DestinationLength {
+		return false
+	}
+	for _, r := range strings.TrimRight(s, "=") {
+		if !(r >= 'A' && r <= 'Z') && !(r >= 'a' && r <= 'z') && !(r >= '0' && r <= '9') && r != '-' && r != '~' {
+			return false
+		}
+	}
+	return true
+}
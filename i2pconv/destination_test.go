@@ -0,0 +1,99 @@
+package i2pconv
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestIsI2PDestination checks which b32, b33, .i2p hostname, and base64
+// destinations are recognised as I2P addresses.
+func TestIsI2PDestination(t *testing.T) {
+	b32 := strings.Repeat("a", 52) + ".b32.i2p"
+	base64Dest := strings.Repeat("A", 514) + "~-AAAA"
+
+	tests := []struct {
+		name string
+		dest string
+		want bool
+	}{
+		{"b32", b32, true},
+		{"b32 with port", b32 + ":80", true},
+		{"b33", strings.Repeat("b", 56) + ".b32.i2p", true},
+		{"b32 wrong length", strings.Repeat("a", 40) + ".b32.i2p", false},
+		{"b32 bad characters", strings.Repeat("1", 52) + ".b32.i2p", false},
+		{"hostname", "example.i2p", true},
+		{"subdomain with port", "irc.postman.i2p:6667", true},
+		{"bare tld", ".i2p", false},
+		{"base64", base64Dest, true},
+		{"short base64", "AAAA", false},
+		{"plain word", "not-an-address", false},
+		{"clearnet host", "example.com", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isI2PDestination(tt.dest); got != tt.want {
+				t.Errorf("isI2PDestination(%q) = %v, want %v", tt.dest, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestValidationContext_ClientDestination checks the strict-mode check that a
+// client's target is an I2P destination, a local address, or a list of them.
+func TestValidationContext_ClientDestination(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  *TunnelConfig
+		strict  bool
+		wantErr bool
+	}{
+		{
+			name:   "b32 address",
+			config: &TunnelConfig{Name: "c", Type: "client", Port: 4444, Target: strings.Repeat("a", 52) + ".b32.i2p"},
+			strict: true,
+		},
+		{
+			name:   "i2p hostname",
+			config: &TunnelConfig{Name: "c", Type: "client", Port: 4444, Target: "example.i2p"},
+			strict: true,
+		},
+		{
+			name:   "local address",
+			config: &TunnelConfig{Name: "c", Type: "client", Port: 4444, Target: "127.0.0.1:7656"},
+			strict: true,
+		},
+		{
+			name:   "irc destination list",
+			config: &TunnelConfig{Name: "irc", Type: "ircclient", Port: 6668, Target: "irc.postman.i2p:6667, irc.echelon.i2p:6667"},
+			strict: true,
+		},
+		{
+			name:    "invalid address",
+			config:  &TunnelConfig{Name: "c", Type: "client", Port: 4444, Target: "not-an-address"},
+			strict:  true,
+			wantErr: true,
+		},
+		{
+			name:    "invalid httpclient target",
+			config:  &TunnelConfig{Name: "web", Type: "httpclient", Port: 4444, Target: "proxy.example.com"},
+			strict:  true,
+			wantErr: true,
+		},
+		{
+			name:   "allowed when not strict",
+			config: &TunnelConfig{Name: "c", Type: "client", Port: 4444, Target: "not-an-address"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := NewValidationContext(tt.strict, "").Validate(tt.config)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && !strings.Contains(err.Error(), "not an I2P destination") {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,193 @@
+package i2pconv
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/urfave/cli/v2"
+)
+
+// FieldDiff describes one field that differs between two tunnel configs.
+// Path uses the same names as TunnelConfig.Get ("port", "i2cp.reduceOnIdle").
+// A and B hold the two values; a nil value means the field is unset on that side.
+type FieldDiff struct {
+	Path string
+	A    interface{}
+	B    interface{}
+}
+
+// String renders the difference as "- path: a", "+ path: b", or "~ path: a -> b".
+func (d FieldDiff) String() string {
+	switch {
+	case d.B == nil:
+		return fmt.Sprintf("- %s: %s", d.Path, formatPropertyValue(d.A))
+	case d.A == nil:
+		return fmt.Sprintf("+ %s: %s", d.Path, formatPropertyValue(d.B))
+	default:
+		return fmt.Sprintf("~ %s: %s -> %s", d.Path, formatPropertyValue(d.A), formatPropertyValue(d.B))
+	}
+}
+
+// Diff returns the field-level differences between a and b, sorted by path.
+// Values are compared by their serialised form, so 3 and "3", or a []string
+// and the equivalent []interface{}, are equal. Unset and zero-valued
+// top-level fields are treated as the same. Unknown keys are compared as part
+// of "options", because whether a key is recognised depends on the format.
+func Diff(a, b *TunnelConfig) []FieldDiff {
+	var diffs []FieldDiff
+
+	scalars := []struct {
+		path string
+		a, b interface{}
+	}{
+		{"name", a.Name, b.Name},
+		{"type", a.Type, b.Type},
+		{"interface", a.Interface, b.Interface},
+		{"port", a.Port, b.Port},
+		{"target", a.Target, b.Target},
+		{"persistentKey", a.PersistentKey, b.PersistentKey},
+		{"description", a.Description, b.Description},
+		{"enabled", enabledValue(a.Enabled), enabledValue(b.Enabled)},
+		{"style", styleValue(a), styleValue(b)},
+	}
+	for _, s := range scalars {
+		if !optionValuesEqual(s.a, s.b) {
+			diffs = append(diffs, FieldDiff{Path: s.path, A: s.a, B: s.b})
+		}
+	}
+
+	sections := []struct {
+		name string
+		a, b map[string]interface{}
+	}{
+		{"i2cp", a.I2CP, b.I2CP},
+		{"options", tunnelAndUnknown(a), tunnelAndUnknown(b)},
+		{"inbound", a.Inbound, b.Inbound},
+		{"outbound", a.Outbound, b.Outbound},
+	}
+	for _, s := range sections {
+		diffs = append(diffs, diffOptionMaps(s.name, s.a, s.b)...)
+	}
+
+	sort.Slice(diffs, func(i, j int) bool { return diffs[i].Path < diffs[j].Path })
+	return diffs
+}
+
+// Equal reports whether t and other describe the same tunnel. It uses the same
+// rules as Diff:
+//   - Values are compared by their serialised form, so 3 and "3", true and
+//     "true", or []string{"4", "0"} and []interface{}{4, 0} are equal.
+//   - Lists are order-sensitive: "4,0" and "0,4" differ.
+//   - Map keys are compared as sets, so insertion order never matters.
+//   - Unset and zero-valued top-level fields are equal.
+//   - Unknown keys are compared together with the Tunnel options.
+//   - Comments are ignored.
+func (t *TunnelConfig) Equal(other *TunnelConfig) bool {
+	if t == nil || other == nil {
+		return t == other
+	}
+	return len(Diff(t, other)) == 0
+}
+
+// enabledValue returns the value of an Enabled pointer for comparison. An
+// unset state and an explicit true both mean the tunnel runs.
+func enabledValue(enabled *bool) bool {
+	return enabled == nil || *enabled
+}
+
+// styleValue returns the SAM style of c for comparison. An explicit style
+// that matches the one its type implies is the same as no style.
+func styleValue(c *TunnelConfig) string {
+	if c.Style == "" || c.SAMStyle() == samSessionStyle(c.Type) {
+		return ""
+	}
+	return c.SAMStyle()
+}
+
+// diffOptionMaps compares two option maps, prefixing each key with section.
+func diffOptionMaps(section string, a, b map[string]interface{}) []FieldDiff {
+	var diffs []FieldDiff
+	for k, av := range a {
+		bv, ok := b[k]
+		if !ok {
+			diffs = append(diffs, FieldDiff{Path: section + "." + k, A: av})
+		} else if !optionValuesEqual(av, bv) {
+			diffs = append(diffs, FieldDiff{Path: section + "." + k, A: av, B: bv})
+		}
+	}
+	for k, bv := range b {
+		if _, ok := a[k]; !ok {
+			diffs = append(diffs, FieldDiff{Path: section + "." + k, B: bv})
+		}
+	}
+	return diffs
+}
+
+// tunnelAndUnknown returns the Tunnel options of c merged with its Unknown
+// keys. Recognised options win if a key appears in both.
+func tunnelAndUnknown(c *TunnelConfig) map[string]interface{} {
+	m := make(map[string]interface{}, len(c.Tunnel)+len(c.Unknown))
+	for k, v := range c.Unknown {
+		m[k] = v
+	}
+	for k, v := range c.Tunnel {
+		m[k] = v
+	}
+	return m
+}
+
+// loadTunnelConfig reads and parses the config file at path. When format is
+// empty it is detected from the file extension; the format used is returned
+// with the config.
+func loadTunnelConfig(path, format string, converter *Converter) (*TunnelConfig, string, error) {
+	data, err := readInputFile(path)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read '%s': %w", path, err)
+	}
+	if format == "" {
+		format, err = converter.DetectFormat(path)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to detect format for '%s': %w (try specifying --in-format)", path, err)
+		}
+	}
+	config, err := converter.ParseInput(data, format)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to parse %s input from '%s': %w", format, path, err)
+	}
+	return config, format, nil
+}
+
+// DiffCommand parses two tunnel configs, possibly in different formats, and
+// prints their field-level differences. It returns an exit code of 1 when the
+// configs differ and nil when they are equivalent.
+//
+// Flags:
+//   - in-format: Format of the first file - auto-detected if not specified
+//   - other-format: Format of the second file - auto-detected if not specified
+func DiffCommand(c *cli.Context) error {
+	if c.NArg() != 2 {
+		return fmt.Errorf("diff requires exactly two files, got %d", c.NArg())
+	}
+	fileA, fileB := c.Args().Get(0), c.Args().Get(1)
+	converter := &Converter{}
+
+	a, _, err := loadTunnelConfig(fileA, c.String("in-format"), converter)
+	if err != nil {
+		return err
+	}
+	b, _, err := loadTunnelConfig(fileB, c.String("other-format"), converter)
+	if err != nil {
+		return err
+	}
+
+	diffs := Diff(a, b)
+	if len(diffs) == 0 {
+		fmt.Printf("✓ '%s' and '%s' are equivalent\n", fileA, fileB)
+		return nil
+	}
+	fmt.Printf("--- %s\n+++ %s\n", fileA, fileB)
+	for _, d := range diffs {
+		fmt.Println(d)
+	}
+	return cli.Exit(fmt.Sprintf("%d field(s) differ", len(diffs)), 1)
+}
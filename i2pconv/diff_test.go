@@ -0,0 +1,173 @@
+package i2pconv
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/urfave/cli/v2"
+)
+
+// TestDiff checks the changes Diff reports between two configs, ignoring values
+// that differ only in type.
+func TestDiff(t *testing.T) {
+	base := func() *TunnelConfig {
+		return &TunnelConfig{
+			Name:    "web",
+			Type:    "httpclient",
+			Port:    4444,
+			I2CP:    map[string]interface{}{"leaseSetEncType": []string{"4", "0"}},
+			Inbound: map[string]interface{}{"length": 3},
+		}
+	}
+
+	tests := []struct {
+		name   string
+		modify func(*TunnelConfig)
+		want   []string
+	}{
+		{name: "identical", modify: func(*TunnelConfig) {}},
+		{
+			name: "equivalent values of different types",
+			modify: func(c *TunnelConfig) {
+				c.I2CP["leaseSetEncType"] = []interface{}{"4", "0"}
+				c.Inbound["length"] = "3"
+			},
+		},
+		{name: "changed port", modify: func(c *TunnelConfig) { c.Port = 8080 }, want: []string{"~ port: 4444 -> 8080"}},
+		{name: "changed style", modify: func(c *TunnelConfig) { c.Style = "raw" }, want: []string{"~ style:  -> RAW"}},
+		{name: "style implied by the type", modify: func(c *TunnelConfig) { c.Style = "stream" }},
+		{
+			name: "added and removed map keys",
+			modify: func(c *TunnelConfig) {
+				delete(c.Inbound, "length")
+				c.Outbound = map[string]interface{}{"quantity": 2}
+			},
+			want: []string{"- inbound.length: 3", "+ outbound.quantity: 2"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := base()
+			tt.modify(b)
+			diffs := Diff(base(), b)
+			var got []string
+			for _, d := range diffs {
+				got = append(got, d.String())
+			}
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("Diff() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestTunnelConfig_Equal checks that Equal ignores representation details
+// such as option value types and reports real field changes.
+func TestTunnelConfig_Equal(t *testing.T) {
+	base := func() *TunnelConfig {
+		return &TunnelConfig{
+			Name:    "web",
+			Type:    "httpclient",
+			Port:    4444,
+			I2CP:    map[string]interface{}{"leaseSetEncType": []string{"4", "0"}, "reduceOnIdle": true},
+			Inbound: map[string]interface{}{"length": 3},
+		}
+	}
+
+	tests := []struct {
+		name   string
+		modify func(c *TunnelConfig)
+		want   bool
+	}{
+		{name: "identical", modify: func(c *TunnelConfig) {}, want: true},
+		{name: "int and string", modify: func(c *TunnelConfig) { c.Inbound["length"] = "3" }, want: true},
+		{name: "bool and string", modify: func(c *TunnelConfig) { c.I2CP["reduceOnIdle"] = "true" }, want: true},
+		{
+			name:   "string slice and interface slice",
+			modify: func(c *TunnelConfig) { c.I2CP["leaseSetEncType"] = []interface{}{4, 0} },
+			want:   true,
+		},
+		{
+			name:   "slice order matters",
+			modify: func(c *TunnelConfig) { c.I2CP["leaseSetEncType"] = []string{"0", "4"} },
+			want:   false,
+		},
+		{name: "different value", modify: func(c *TunnelConfig) { c.Inbound["length"] = 2 }, want: false},
+		{name: "extra key", modify: func(c *TunnelConfig) { c.Inbound["quantity"] = 2 }, want: false},
+		{name: "different scalar", modify: func(c *TunnelConfig) { c.Port = 4445 }, want: false},
+		{
+			name:   "comments ignored",
+			modify: func(c *TunnelConfig) { c.Comments = &Comments{Format: "ini", Header: []string{"; x"}} },
+			want:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, b := base(), base()
+			tt.modify(b)
+			if got := a.Equal(b); got != tt.want {
+				t.Errorf("Equal() = %v, want %v (diffs: %v)", got, tt.want, Diff(a, b))
+			}
+			if got := b.Equal(a); got != tt.want {
+				t.Errorf("Equal() is not symmetric: got %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	var nilConfig *TunnelConfig
+	if !nilConfig.Equal(nil) || nilConfig.Equal(base()) || base().Equal(nil) {
+		t.Error("nil configs should only equal nil")
+	}
+}
+
+// TestDiffCommand compares a properties file with YAML files in the same
+// directory and checks the exit status and the reported field.
+func TestDiffCommand(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("setup: %v", err)
+		}
+		return path
+	}
+	props := write("web.properties", "name=web\ntype=httpclient\ninterface=127.0.0.1\nlistenPort=4444\noption.inbound.length=2\n")
+	same := write("same.yaml", "tunnels:\n  web:\n    type: httpclient\n    interface: 127.0.0.1\n    port: 4444\n    inbound:\n      length: 2\n")
+	changed := write("changed.yaml", "tunnels:\n  web:\n    type: httpclient\n    interface: 127.0.0.1\n    port: 4444\n    inbound:\n      length: 1\n")
+
+	run := func(a, b string) (string, error) {
+		app := &cli.App{
+			Name:            "go-i2ptunnel-config",
+			ExitErrHandler:  func(*cli.Context, error) {},
+			HideHelpCommand: true,
+			Commands: []*cli.Command{{
+				Name:   "diff",
+				Flags:  []cli.Flag{&cli.StringFlag{Name: "in-format"}, &cli.StringFlag{Name: "other-format"}},
+				Action: DiffCommand,
+			}},
+		}
+		var err error
+		out := captureOutput(t, &os.Stdout, func() {
+			err = app.Run([]string{"go-i2ptunnel-config", "diff", a, b})
+		})
+		return out, err
+	}
+
+	out, err := run(props, same)
+	if err != nil {
+		t.Fatalf("equivalent configs should not differ, got %v\n%s", err, out)
+	}
+
+	out, err = run(props, changed)
+	exitErr, ok := err.(cli.ExitCoder)
+	if !ok || exitErr.ExitCode() != 1 {
+		t.Fatalf("expected exit code 1 for differing configs, got %v", err)
+	}
+	if !strings.Contains(out, "~ inbound.length: 2 -> 1") {
+		t.Errorf("expected changed inbound.length in output, got:\n%s", out)
+	}
+}
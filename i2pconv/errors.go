@@ -20,6 +20,10 @@ type ValidationError struct {
 // ParseError represents a parsing error with line context information.
 // It provides detailed error reporting including the line number, context lines,
 // and a clear error message to help users identify and fix configuration issues.
+//
+// Its fields are part of the public API and keep their meaning; the accessor
+// methods expose the same values for callers that render their own error
+// output after finding the error with errors.As.
 type ParseError struct {
 	Line    int      // Line number where error occurred (1-indexed)
 	Column  int      // Column number where error occurred (1-indexed, 0 if unknown)
@@ -39,6 +43,15 @@ func (e *ValidationError) Unwrap() error {
 	return e.Err
 }
 
+// Errors returns the individual validation failures. Validate joins every
+// failing check into one error; Errors splits it back into a list.
+func (e *ValidationError) Errors() []error {
+	if joined, ok := e.Err.(interface{ Unwrap() []error }); ok {
+		return joined.Unwrap()
+	}
+	return []error{e.Err}
+}
+
 // Error returns a formatted string that includes the operation name and cause.
 func (e *ConversionError) Error() string {
 	return e.Op + ": " + e.Err.Error()
@@ -95,6 +108,39 @@ func (e *ParseError) Error() string {
 	return sb.String()
 }
 
+// LineNumber returns the 1-indexed line the error occurred on.
+func (e *ParseError) LineNumber() int {
+	return e.Line
+}
+
+// ColumnNumber returns the 1-indexed column the error occurred at, or 0 when
+// the parser could not tell.
+func (e *ParseError) ColumnNumber() int {
+	return e.Column
+}
+
+// FormatName returns the format being parsed: "properties", "ini", or "yaml".
+func (e *ParseError) FormatName() string {
+	return e.Format
+}
+
+// LineContent returns the text of the line the error occurred on.
+func (e *ParseError) LineContent() string {
+	return e.Content
+}
+
+// ContextLines returns a copy of the lines around the error, up to two before
+// and after it, in file order.
+func (e *ParseError) ContextLines() []string {
+	return append([]string(nil), e.Context...)
+}
+
+// Reason returns the error message without the location and context that
+// Error adds.
+func (e *ParseError) Reason() string {
+	return e.Message
+}
+
 // Unwrap returns the underlying error if this ParseError wraps another error.
 // This enables error chain inspection using errors.Is and errors.As.
 func (e *ParseError) Unwrap() error {
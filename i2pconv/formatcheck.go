@@ -0,0 +1,54 @@
+package i2pconv
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// sniffFormat guesses the format of data from its first line that is not
+// blank or a comment. It returns "" when the line does not settle the
+// question:
+//   - "[section]" means ini (i2pd files always start with a section)
+//   - "key=value" without a colon before the '=' means properties
+//   - "key:" or "key: value" means yaml
+func sniffFormat(data []byte) string {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == '#' || line[0] == ';' || line[0] == '!' {
+			continue
+		}
+		if line == "---" {
+			return "yaml"
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			return "ini"
+		}
+		eq, colon := strings.Index(line, "="), strings.Index(line, ":")
+		switch {
+		case eq > 0 && (colon < 0 || eq < colon):
+			return "properties"
+		case colon > 0 && (colon == len(line)-1 || line[colon+1] == ' '):
+			return "yaml"
+		}
+		return ""
+	}
+	return ""
+}
+
+// confirmDeclaredFormat checks a format given with --in-format against the
+// format implied by the file extension and by the content. Either check is
+// skipped when it gives no answer, e.g. for stdin or an unknown extension.
+func confirmDeclaredFormat(inputFile string, data []byte, declared string, converter *Converter) error {
+	if inputFile != "-" {
+		if detected, err := converter.DetectFormat(inputFile); err == nil && detected != declared {
+			return fmt.Errorf("declared format '%s' conflicts with detected '%s' (from the file extension of '%s')", declared, detected, inputFile)
+		}
+	}
+	if detected := sniffFormat(data); detected != "" && detected != declared {
+		return fmt.Errorf("declared format '%s' conflicts with detected '%s' (from the content of '%s')", declared, detected, inputFile)
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package i2pconv
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/urfave/cli/v2"
+)
+
+// TestSniffFormat checks the format guessed from a file's content, and that
+// unclear input gives no guess.
+func TestSniffFormat(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"ini section", "; i2pd tunnels\n\n[proxy]\ntype = http\n", "ini"},
+		{"properties", "# comment\nname=proxy\ntype=httpclient\n", "properties"},
+		{"numbered properties", "tunnel.0.name=proxy\n", "properties"},
+		{"yaml map", "tunnels:\n  proxy:\n    type: httpclient\n", "yaml"},
+		{"yaml document marker", "---\ntunnels: {}\n", "yaml"},
+		{"properties value with colon", "targetHost=127.0.0.1:80\n", "properties"},
+		{"empty", "\n# only comments\n", ""},
+		{"unclear", "just some words\n", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sniffFormat([]byte(tt.input)); got != tt.want {
+				t.Errorf("sniffFormat() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestConvertCommand_ConfirmFormat checks that --confirm-format rejects an
+// --in-format the file's extension or content contradicts.
+func TestConvertCommand_ConfirmFormat(t *testing.T) {
+	dir := t.TempDir()
+	properties := "name=proxy\ntype=httpclient\ninterface=127.0.0.1\nlistenPort=4444\n"
+	files := map[string]string{
+		"proxy.properties": properties,
+		"proxy.txt":        properties,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("setup: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		file    string
+		format  string
+		wantErr string
+	}{
+		{"extension mismatch", "proxy.properties", "ini", "declared format 'ini' conflicts with detected 'properties' (from the file extension"},
+		{"content mismatch", "proxy.txt", "yaml", "declared format 'yaml' conflicts with detected 'properties' (from the content"},
+		{"matching declaration", "proxy.txt", "properties", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := makeStdinApp()
+			app.Flags = append(app.Flags, &cli.BoolFlag{Name: "confirm-format"})
+			err := app.Run([]string{"go-i2ptunnel-config", "--dry-run", "--confirm-format", "--in-format", tt.format, filepath.Join(dir, tt.file)})
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
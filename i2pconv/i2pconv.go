@@ -28,10 +28,14 @@ import (
 // - Target: The target of the tunnel (string, optional).
 // - PersistentKey: Indicates if the key should be persistent (bool, optional).
 // - Description: A description of the tunnel (string, optional).
+// - Enabled: The go-i2p enabled state; nil means unset (*bool, optional).
+// - Style: The SAM session style, STREAM, DATAGRAM, or RAW; empty derives it from Type (string, optional).
 // - I2CP: A map of I2CP (I2P Control Protocol) options (map[string]interface{}, optional).
 // - Tunnel: A map of tunnel-specific options (map[string]interface{}, optional).
 // - Inbound: A map of inbound tunnel options (map[string]interface{}, optional).
 // - Outbound: A map of outbound tunnel options (map[string]interface{}, optional).
+// - Unknown: Keys the parser does not recognise, kept verbatim (map[string]string, optional).
+// - Comments: Comments read from an INI or properties file, not serialised to YAML (*Comments, optional).
 type TunnelConfig struct {
 	Name          string                 `yaml:"name"`
 	Type          string                 `yaml:"type"`
@@ -40,22 +44,26 @@ type TunnelConfig struct {
 	Target        string                 `yaml:"target,omitempty"`
 	PersistentKey bool                   `yaml:"persistentKey,omitempty"`
 	Description   string                 `yaml:"description,omitempty"`
+	Enabled       *bool                  `yaml:"enabled,omitempty"`
+	Style         string                 `yaml:"style,omitempty"`
 	I2CP          map[string]interface{} `yaml:"i2cp,omitempty"`
 	Tunnel        map[string]interface{} `yaml:"options,omitempty"`
 	Inbound       map[string]interface{} `yaml:"inbound,omitempty"`
 	Outbound      map[string]interface{} `yaml:"outbound,omitempty"`
+	Unknown       map[string]string      `yaml:"unknown,omitempty"`
+	Comments      *Comments              `yaml:"-"`
 }
 
 // LoadConfig reads a tunnel configuration file from disk and parses it into
 // this TunnelConfig. The format is detected from the file extension.
 func (t *TunnelConfig) LoadConfig(path string) error {
 	conv := &Converter{}
-	data, err := os.ReadFile(path)
+	data, err := readInputFile(path)
 	if err != nil {
 		return fmt.Errorf("failed to read config file: %w", err)
 	}
 
-	format := strings.ToLower(filepath.Ext(path)[1:])
+	format := strings.ToLower(strings.TrimPrefix(filepath.Ext(trimGzipExt(path)), "."))
 	if format == "properties" || format == "prop" || format == "config" {
 		format = "properties"
 	} else if format == "yml" || format == "yaml" {
@@ -106,25 +114,174 @@ func (c *Converter) generateOutput(config *TunnelConfig, format string) ([]byte,
 func (c *Converter) validate(config *TunnelConfig) error {
 	// Use the comprehensive validation framework
 	validationCtx := NewValidationContext(c.strict, "")
+	validationCtx.WarningsAsErrors = c.warningsAsErrors
+	validationCtx.AddRules(c.rules)
 	return validationCtx.Validate(config)
 }
 
 // validateWithFormat checks the tunnel configuration using both generic rules
 // and rules specific to the given input format (properties, ini, or yaml).
-func (c *Converter) validateWithFormat(config *TunnelConfig, format string) error {
+// It returns the warnings from strict mode, which fail validation only when
+// the converter treats them as errors; printing them is up to the caller.
+func (c *Converter) validateWithFormat(config *TunnelConfig, format string) ([]string, error) {
 	// Use the comprehensive validation framework with format-specific rules
 	validationCtx := NewValidationContext(c.strict, format)
-	return validationCtx.Validate(config)
+	validationCtx.WarningsAsErrors = c.warningsAsErrors
+	validationCtx.AddRules(c.rules)
+	if err := validationCtx.Validate(config); err != nil {
+		return nil, err
+	}
+	return validationCtx.Warnings(), nil
+}
+
+// validateOutputFormat applies the format-specific checks of outFormat, such
+// as the INI keyfile warning, to a config read from inFormat. validateWithFormat
+// only applies those of the input format, so without this a tunnel converted
+// to INI would never be checked for what INI output needs. Like
+// validateWithFormat it returns the warnings instead of printing them.
+func (c *Converter) validateOutputFormat(config *TunnelConfig, inFormat, outFormat string) ([]string, error) {
+	if outFormat == inFormat {
+		return nil, nil
+	}
+	validationCtx := NewValidationContext(c.strict, outFormat)
+	validationCtx.WarningsAsErrors = c.warningsAsErrors
+	if err := validationCtx.validateFormatSpecific(config); err != nil {
+		return nil, err
+	}
+	return validationCtx.Warnings(), nil
+}
+
+// checkConversion runs the checks for converting config from inFormat to
+// outFormat that go beyond validating the input: the output format's own
+// rules, whether its router supports the tunnel type, and what the output
+// cannot carry. An unsupported type is an error only in strict mode; the rest
+// are returned as warnings.
+func (c *Converter) checkConversion(config *TunnelConfig, inFormat, outFormat string) ([]string, error) {
+	warnings, err := c.validateOutputFormat(config, inFormat, outFormat)
+	if err != nil {
+		return nil, fmt.Errorf("invalid for %s output: %w", outFormat, err)
+	}
+	if err := checkTypeSupported(config, outFormat); err != nil {
+		if c.strict {
+			return nil, err
+		}
+		warnings = append(warnings, fmt.Sprintf("%v; the converted tunnel may not load", err))
+	}
+	for _, err := range []error{
+		checkUnknownPassthrough(config, inFormat, outFormat),
+		checkSharedClient(config, outFormat),
+		checkEnabledState(config, outFormat),
+		checkStyle(config, outFormat),
+		checkCryptoType(config, outFormat),
+	} {
+		if err != nil {
+			warnings = append(warnings, err.Error())
+		}
+	}
+	return warnings, nil
 }
 
 // Converter handles configuration format conversions
 type Converter struct {
-	strict bool
+	strict            bool
+	warningsAsErrors  bool
+	rules             map[TunnelType][]ValidationRule
+	stats             *converterStats // nil unless ConverterOptions.CollectStats
+	preserveEmptyMaps bool
+	canonicalTypes    string // "", "java", "i2pd", or "none"; see ConverterOptions
+	iniDialect        string // "", "standard", or "prefixed"; see ConverterOptions
+}
+
+// NewConverter returns a Converter. When strict is true, validation applies the
+// additional strict-mode checks and Convert rejects tunnel types the target
+// router does not support.
+func NewConverter(strict bool) *Converter {
+	return NewConverterWithOptions(ConverterOptions{Strict: strict})
+}
+
+// ConverterOptions configures a Converter created with NewConverterWithOptions.
+// The zero value gives the same converter as NewConverter(false).
+type ConverterOptions struct {
+	// Strict enables the strict-mode validation rules.
+	Strict bool
+	// WarningsAsErrors makes validation fail on warnings such as a
+	// privileged port, which are otherwise only reported.
+	WarningsAsErrors bool
+	// Rules are extra validation rules by tunnel type, as returned by
+	// LoadValidationRules.
+	Rules map[TunnelType][]ValidationRule
+	// CollectStats makes Convert count its conversions for Stats.
+	CollectStats bool
+	// PreserveEmptyMaps makes YAML output write the i2cp, options, inbound,
+	// and outbound maps even when they are empty, as "i2cp: {}".
+	PreserveEmptyMaps bool
+	// CanonicalTypes overrides the tunnel type names written to every output
+	// format: "java" writes Java I2P names (httpclient), "i2pd" writes i2pd
+	// names (http), and "none" writes the type as it was parsed. Empty uses
+	// each format's own names. Case is ignored; Validate reports any other
+	// value.
+	CanonicalTypes string
+	// INIDialect selects how INI output names the tunnel pool options:
+	// "standard" (the default) writes "inbound.length", "prefixed" writes
+	// "tunnels.inbound.length". INI input is read in either dialect. Case is
+	// ignored; Validate reports any other value.
+	INIDialect string
+}
+
+// Validate reports an unsupported CanonicalTypes or INIDialect value, which
+// NewConverterWithOptions would otherwise ignore.
+func (o ConverterOptions) Validate() error {
+	if !canonicalTypeModes[strings.ToLower(o.CanonicalTypes)] {
+		return fmt.Errorf("unsupported CanonicalTypes value '%s' (use java, i2pd, or none)", o.CanonicalTypes)
+	}
+	if _, ok := iniDialects[strings.ToLower(o.INIDialect)]; !ok {
+		return fmt.Errorf("unsupported INIDialect value '%s' (use standard or prefixed)", o.INIDialect)
+	}
+	return nil
+}
+
+// NewConverterWithOptions returns a Converter configured by opts. Unsupported
+// option values fall back to the defaults; check opts with Validate first to
+// reject them instead.
+func NewConverterWithOptions(opts ConverterOptions) *Converter {
+	c := &Converter{strict: opts.Strict, warningsAsErrors: opts.WarningsAsErrors, rules: opts.Rules, preserveEmptyMaps: opts.PreserveEmptyMaps}
+	if mode := strings.ToLower(opts.CanonicalTypes); canonicalTypeModes[mode] {
+		c.canonicalTypes = mode
+	}
+	dialect := strings.ToLower(opts.INIDialect)
+	if _, ok := iniDialects[dialect]; ok {
+		c.iniDialect = dialect
+	}
+	if opts.CollectStats {
+		c.stats = &converterStats{}
+	}
+	return c
+}
+
+// Validate checks an in-memory tunnel configuration against the generic rules
+// and the rules of format ("properties", "ini", or "yaml"). An empty format
+// applies only the generic rules. Strict-mode warnings, such as a privileged
+// port, do not fail validation unless ConverterOptions.WarningsAsErrors is
+// set; use ValidateWithWarnings to get them.
+func (c *Converter) Validate(config *TunnelConfig, format string) error {
+	_, err := c.validateWithFormat(config, format)
+	return err
+}
+
+// ValidateWithWarnings is Validate that also returns the warnings found when
+// the configuration is valid. Nothing is printed.
+func (c *Converter) ValidateWithWarnings(config *TunnelConfig, format string) ([]string, error) {
+	return c.validateWithFormat(config, format)
 }
 
 // Convert parses input bytes in inFormat and serialises the result as outFormat.
-// It validates the configuration between the two steps.
-func (c *Converter) Convert(input []byte, inFormat, outFormat string) ([]byte, error) {
+// It validates the configuration between the two steps. In strict mode it also
+// rejects tunnel types that the router behind outFormat does not support.
+// Converters created with ConverterOptions.CollectStats count each call.
+func (c *Converter) Convert(input []byte, inFormat, outFormat string) (out []byte, err error) {
+	record := c.stats.begin(inFormat, outFormat)
+	defer func() { record.finish(err) }()
+
 	config, err := c.ParseInput(input, inFormat)
 	if err != nil {
 		return nil, &ConversionError{Op: "parse", Err: err}
@@ -134,12 +291,20 @@ func (c *Converter) Convert(input []byte, inFormat, outFormat string) ([]byte, e
 		return nil, &ValidationError{Config: config, Err: err}
 	}
 
+	if c.strict {
+		if err := checkTypeSupported(config, outFormat); err != nil {
+			return nil, &ConversionError{Op: "generate", Err: err}
+		}
+	}
+
 	return c.generateOutput(config, outFormat)
 }
 
 // ParseInput parses raw configuration bytes in the given format (properties,
-// yaml, or ini) and returns the resulting TunnelConfig.
+// yaml, or ini) and returns the resulting TunnelConfig. A leading UTF-8 byte
+// order mark is ignored and CRLF or CR line endings are read as LF.
 func (c *Converter) ParseInput(input []byte, format string) (*TunnelConfig, error) {
+	input = normalizeInput(input)
 	switch format {
 	case "properties":
 		return c.parseJavaProperties(input)
@@ -154,9 +319,10 @@ func (c *Converter) ParseInput(input []byte, format string) (*TunnelConfig, erro
 
 // DetectFormat infers the configuration format from the file extension of path.
 // Recognised extensions: .properties/.prop/.config → "properties",
-// .yml/.yaml → "yaml", .ini/.conf → "ini".
+// .yml/.yaml → "yaml", .ini/.conf → "ini". A trailing .gz is skipped, so
+// "tunnel.config.gz" is properties.
 func (c *Converter) DetectFormat(path string) (string, error) {
-	ext := strings.ToLower(filepath.Ext(path))
+	ext := strings.ToLower(filepath.Ext(trimGzipExt(path)))
 	if ext == ".properties" || ext == ".prop" || ext == ".config" {
 		return "properties", nil
 	} else if ext == ".yml" || ext == ".yaml" {
@@ -167,6 +333,74 @@ func (c *Converter) DetectFormat(path string) (string, error) {
 	return "", fmt.Errorf("unsupported file extension: %s", ext)
 }
 
+// Marshal serialises config in the given format ("properties", "yaml", or
+// "ini"). It does not validate the configuration; call Validate first when the
+// config was built by hand.
+func (c *Converter) Marshal(config *TunnelConfig, format string) ([]byte, error) {
+	return c.generateOutput(config, format)
+}
+
+// WriteConfig serialises config and writes it to path. When format is empty
+// it is inferred from the file extension as in DetectFormat.
+func (c *Converter) WriteConfig(config *TunnelConfig, path, format string) error {
+	if format == "" {
+		var err error
+		if format, err = c.DetectFormat(path); err != nil {
+			return fmt.Errorf("failed to detect format for '%s': %w", path, err)
+		}
+	}
+	data, err := c.Marshal(config, format)
+	if err != nil {
+		return fmt.Errorf("failed to generate %s output: %w", format, err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write '%s': %w", path, err)
+	}
+	return nil
+}
+
+// ConvertFile converts the tunnel configuration at inputPath and writes it to
+// outputPath, overwriting any existing file. An input ending in .gz is
+// decompressed first. An empty inFormat or outFormat is inferred from the
+// file's extension as in DetectFormat. The configuration
+// is validated with the rules of its input format first; when strict is true
+// the strict-mode rules apply and tunnel types the output format's router does
+// not support are rejected. Warnings do not stop the conversion and are not
+// printed; use Converter.ConvertFileWithWarnings to get them.
+func ConvertFile(inputPath, outputPath, inFormat, outFormat string, strict bool) error {
+	_, err := NewConverter(strict).ConvertFileWithWarnings(inputPath, outputPath, inFormat, outFormat)
+	return err
+}
+
+// ConvertFileWithWarnings is ConvertFile using c's options. It also returns
+// the warnings the command-line tool would print: strict-mode validation
+// warnings, options the output format drops, and embedded private keys.
+func (c *Converter) ConvertFileWithWarnings(inputPath, outputPath, inFormat, outFormat string) ([]string, error) {
+	config, inFormat, err := loadTunnelConfig(inputPath, inFormat, c)
+	if err != nil {
+		return nil, err
+	}
+	if outFormat == "" {
+		if outFormat, err = c.DetectFormat(outputPath); err != nil {
+			return nil, fmt.Errorf("failed to detect output format for '%s': %w", outputPath, err)
+		}
+	}
+
+	warnings, err := c.validateWithFormat(config, inFormat)
+	if err != nil {
+		return nil, &ValidationError{Config: config, Err: err}
+	}
+	conversionWarnings, err := c.checkConversion(config, inFormat, outFormat)
+	if err != nil {
+		return nil, fmt.Errorf("cannot convert '%s': %w", inputPath, err)
+	}
+	warnings = append(warnings, conversionWarnings...)
+	if err := checkPrivateKeys(config); err != nil {
+		warnings = append(warnings, err.Error())
+	}
+	return warnings, c.WriteConfig(config, outputPath, outFormat)
+}
+
 // SplitTunnels parses all tunnel definitions from input and returns each as a
 // separate TunnelConfig. Unlike ParseInput, which returns only the first tunnel,
 // SplitTunnels handles multi-definition files (multi-section INI, multi-key YAML,
@@ -174,6 +408,7 @@ func (c *Converter) DetectFormat(path string) (string, error) {
 //
 // Supported formats: "properties", "ini", "yaml".
 func (c *Converter) SplitTunnels(input []byte, format string) ([]*TunnelConfig, error) {
+	input = normalizeInput(input)
 	switch format {
 	case "ini":
 		return c.splitINITunnels(input)
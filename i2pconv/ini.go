@@ -2,23 +2,12 @@ package i2pconv
 
 import (
 	"fmt"
+	"net"
 	"strconv"
 	"strings"
+	"unicode"
 )
 
-// isClientTunnelType returns true when t names a tunnel type that acts as a
-// client (outbound) rather than a server (inbound).  For client types the
-// i2pd "address" key is the local bind address, not the remote destination.
-func isClientTunnelType(t string) bool {
-	normalized := NormalizeTypeName(t)
-	switch normalized {
-	case string(TunnelTypeClient), string(TunnelTypeHTTPClient), string(TunnelTypeSOCKS),
-		string(TunnelTypeIRCClient), string(TunnelTypeStreamClient):
-		return true
-	}
-	return false
-}
-
 // looksLikeI2PDestination returns true when s appears to be an I2P
 // destination – either a .i2p / .b32.i2p hostname or a long Base64 blob.
 // Bare IPv4/IPv6 addresses and plain hostnames return false.
@@ -45,6 +34,35 @@ func countINISections(input []byte) int {
 	return count
 }
 
+// findDuplicateINIKeys returns every key defined more than once within the
+// same section of INI input, in input order. A key may repeat across sections,
+// since each section is a separate tunnel.
+func findDuplicateINIKeys(input []byte) []duplicateKey {
+	var dups []duplicateKey
+	seen := make(map[string]int)
+	for i, line := range strings.Split(string(input), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, ";") || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") {
+			seen = make(map[string]int)
+			continue
+		}
+		key, _, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		key = strings.TrimSpace(key)
+		if first, ok := seen[key]; ok {
+			dups = append(dups, duplicateKey{Key: key, FirstLine: first, Line: i + 1})
+			continue
+		}
+		seen[key] = i + 1
+	}
+	return dups
+}
+
 // splitINITunnels returns one TunnelConfig per [section] found in input.
 // Each section's lines are fed to parseINI so all existing parsing logic applies.
 func (c *Converter) splitINITunnels(input []byte) ([]*TunnelConfig, error) {
@@ -143,7 +161,7 @@ func parseINIBooleanValue(s string) bool {
 //   - gzip, multicast, maptoloopback, enableuniquelocal (boolean)
 //   - accesslist, explicitpeers (comma-separated lists)
 //   - hostoverride, webircpassword (strings)
-//   - signaturetype (integer)
+//   - signaturetype, cryptotype (integer)
 //
 // Advanced Options:
 //   - crypto.* options (e.g., crypto.tagsToSend)
@@ -161,6 +179,16 @@ func parseINIBooleanValue(s string) bool {
 //   - Comma-separated values become string arrays
 //   - Context-aware parsing for known boolean properties
 func (c *Converter) parseINI(input []byte) (*TunnelConfig, error) {
+	// A repeated key silently replaces the earlier value; in strict mode a
+	// duplicate within a section is treated as a parse error.
+	if c.strict {
+		if dups := findDuplicateINIKeys(input); len(dups) > 0 {
+			d := dups[0]
+			return nil, newParseError(input, d.Line, 0, "ini",
+				fmt.Sprintf("duplicate key '%s' (first defined on line %d)", d.Key, d.FirstLine))
+		}
+	}
+
 	config := &TunnelConfig{
 		I2CP:     make(map[string]interface{}),
 		Tunnel:   make(map[string]interface{}),
@@ -170,31 +198,38 @@ func (c *Converter) parseINI(input []byte) (*TunnelConfig, error) {
 
 	lines := strings.Split(string(input), "\n")
 	currentSection := ""
+	comments := newCommentCollector("ini")
 
 	for lineNum, line := range lines {
 		originalLine := line
 		line = strings.TrimSpace(line)
 
-		// Skip empty lines and comments
-		if line == "" || strings.HasPrefix(line, ";") || strings.HasPrefix(line, "#") {
+		// Skip empty lines and comments, remembering comments for regeneration
+		if line == "" {
+			comments.blank()
+			continue
+		}
+		if strings.HasPrefix(line, ";") || strings.HasPrefix(line, "#") {
+			comments.comment(line)
 			continue
 		}
 
 		// Handle INI sections [section-name]
 		if strings.HasPrefix(line, "[") {
 			if !strings.HasSuffix(line, "]") {
-				return nil, newParseError(input, lineNum+1, 0, "ini",
+				return nil, newParseError(input, lineNum+1, iniColumn(originalLine, len(line)), "ini",
 					"unclosed section bracket - expected ']'")
 			}
 			currentSection = strings.TrimSuffix(strings.TrimPrefix(line, "["), "]")
 			if currentSection == "" {
-				return nil, newParseError(input, lineNum+1, 0, "ini",
+				return nil, newParseError(input, lineNum+1, iniColumn(originalLine, 0), "ini",
 					"empty section name - sections must have a name")
 			}
 			// For single tunnel config, use section name as tunnel name
 			if config.Name == "" {
 				config.Name = currentSection
 			}
+			comments.flushHeader()
 			continue
 		}
 
@@ -203,21 +238,32 @@ func (c *Converter) parseINI(input []byte) (*TunnelConfig, error) {
 		if len(parts) != 2 {
 			// Check if line looks like it should be a key=value pair but is malformed
 			if strings.Contains(originalLine, "=") {
-				return nil, newParseError(input, lineNum+1, 0, "ini",
+				return nil, newParseError(input, lineNum+1, strings.Index(originalLine, "=")+1, "ini",
 					"malformed key=value pair - check for extra '=' characters")
 			}
-			return nil, newParseError(input, lineNum+1, 0, "ini",
+			// Point at the end of the key, where the '=' was expected
+			keyEnd := strings.IndexFunc(line, unicode.IsSpace)
+			if keyEnd < 0 {
+				keyEnd = len(line)
+			}
+			return nil, newParseError(input, lineNum+1, iniColumn(originalLine, keyEnd), "ini",
 				"expected key=value pair or section header [name]")
 		}
 
-		key := strings.TrimSpace(parts[0])
+		key := trimINIPoolPrefix(strings.TrimSpace(parts[0]))
 		value := strings.TrimSpace(parts[1])
 
 		if key == "" {
-			return nil, newParseError(input, lineNum+1, 0, "ini",
+			return nil, newParseError(input, lineNum+1, iniColumn(originalLine, strings.Index(line, "=")), "ini",
 				"empty key name - key=value pairs must have a key")
 		}
 
+		inline := ""
+		if !iniFreeTextKeys[key] {
+			value, inline = splitINIInlineComment(value)
+		}
+		comments.key(key, inline)
+
 		// Parse key-value pair with i2pd-specific handling
 		c.parseINIKeyValue(key, value, config)
 	}
@@ -228,7 +274,7 @@ func (c *Converter) parseINI(input []byte) (*TunnelConfig, error) {
 	if rawAddr, ok := config.Tunnel["_rawAddress"]; ok {
 		addr := rawAddr.(string)
 		delete(config.Tunnel, "_rawAddress")
-		if isClientTunnelType(config.Type) && !looksLikeI2PDestination(addr) {
+		if TunnelType(config.Type).IsClient() && !looksLikeI2PDestination(addr) {
 			if config.Interface == "" {
 				config.Interface = addr
 			}
@@ -239,9 +285,19 @@ func (c *Converter) parseINI(input []byte) (*TunnelConfig, error) {
 		}
 	}
 
+	config.Comments = comments.result()
+	normalizeOptionTypes(config)
 	return config, nil
 }
 
+// iniColumn maps a byte offset within the trimmed form of originalLine to the
+// 1-indexed column in originalLine, accounting for the leading whitespace
+// parseINI strips before matching.
+func iniColumn(originalLine string, offset int) int {
+	indent := len(originalLine) - len(strings.TrimLeftFunc(originalLine, unicode.IsSpace))
+	return indent + offset + 1
+}
+
 // parseINIKeyValue handles individual key-value pairs with i2pd-specific mappings.
 // It delegates to three focused helpers in order: core fields, advanced tunnel
 // fields, and prefixed/unknown fields.
@@ -266,6 +322,10 @@ func parseCoreINIField(key, value string, config *TunnelConfig) bool {
 		}
 	case "type":
 		config.Type = NormalizeTypeName(value)
+		// The udptunnel alias becomes a client type, so keep its datagram style
+		if strings.EqualFold(value, "udptunnel") {
+			config.Style = "DATAGRAM"
+		}
 	case "host", "interface":
 		config.Interface = value
 	case "port":
@@ -319,6 +379,8 @@ func parseTunnelAdvancedField(key, value string, config *TunnelConfig) bool {
 		config.Tunnel["accesslist"] = parseINIValue(value)
 	case "signaturetype":
 		config.Tunnel["signaturetype"] = parseINIValue(value)
+	case "cryptotype":
+		config.Tunnel["cryptotype"] = parseINIValue(value)
 	case "explicitpeers":
 		config.Tunnel["explicitpeers"] = parseINIValue(value)
 	case "multicast":
@@ -336,8 +398,9 @@ func parseTunnelAdvancedField(key, value string, config *TunnelConfig) bool {
 }
 
 // parsePrefixedINIField stores options whose keys carry a recognised prefix
-// (i2cp.*, crypto.*, streamr.*, inbound.*, outbound.*) into the appropriate
-// sub-maps, and falls back to the generic Tunnel map for all other keys.
+// (i2cp.*, crypto.*, streamr.*, i2p.streaming.*, inbound.*, outbound.*) into the appropriate
+// sub-maps. Any other key is kept verbatim in the Unknown map so it can be
+// written back unchanged.
 func parsePrefixedINIField(key, value string, config *TunnelConfig) {
 	switch {
 	case strings.HasPrefix(key, "i2cp."):
@@ -345,7 +408,7 @@ func parsePrefixedINIField(key, value string, config *TunnelConfig) {
 			config.I2CP = make(map[string]interface{})
 		}
 		config.I2CP[strings.TrimPrefix(key, "i2cp.")] = parseINIValue(value)
-	case strings.HasPrefix(key, "crypto."), strings.HasPrefix(key, "streamr."):
+	case strings.HasPrefix(key, "crypto."), strings.HasPrefix(key, "streamr."), strings.HasPrefix(key, "i2p.streaming."):
 		if config.Tunnel == nil {
 			config.Tunnel = make(map[string]interface{})
 		}
@@ -361,13 +424,49 @@ func parsePrefixedINIField(key, value string, config *TunnelConfig) {
 		}
 		config.Outbound[strings.TrimPrefix(key, "outbound.")] = parseINIValue(value)
 	default:
-		if config.Tunnel == nil {
-			config.Tunnel = make(map[string]interface{})
+		if config.Unknown == nil {
+			config.Unknown = make(map[string]string)
 		}
-		config.Tunnel[key] = parseINIValue(value)
+		config.Unknown[key] = value
 	}
 }
 
+// iniDialects maps each INI dialect to the prefix its tunnel pool options
+// carry: "standard" writes "inbound.length", "prefixed" writes
+// "tunnels.inbound.length" for i2pd builds that expect it. The empty string
+// is the default, standard. parseINI reads both.
+var iniDialects = map[string]string{"": "", "standard": "", "prefixed": "tunnels."}
+
+// trimINIPoolPrefix returns key without the "tunnels." prefix of the
+// prefixed dialect when it precedes an inbound. or outbound. option, so both
+// dialects parse to the same key. Other keys are returned unchanged.
+func trimINIPoolPrefix(key string) string {
+	if rest, ok := strings.CutPrefix(key, iniDialects["prefixed"]); ok &&
+		(strings.HasPrefix(rest, "inbound.") || strings.HasPrefix(rest, "outbound.")) {
+		return rest
+	}
+	return key
+}
+
+// iniServerAddress returns the address a server tunnel's target is written
+// as. Java I2P keeps the service host and port apart (targetHost, and
+// targetPort in the Tunnel map); i2pd wants one "host:port" address, so a
+// target without a port is combined with targetPort and the second result is
+// true. Any other target is returned unchanged.
+func iniServerAddress(config *TunnelConfig) (string, bool) {
+	port, ok := config.Tunnel["targetPort"]
+	if !ok || config.Target == "" {
+		return config.Target, false
+	}
+	if _, _, err := net.SplitHostPort(config.Target); err == nil {
+		return config.Target, false
+	}
+	if !TunnelType(config.Type).IsServer() {
+		return config.Target, false
+	}
+	return net.JoinHostPort(config.Target, formatINIValue(port)), true
+}
+
 // generateINI generates i2pd-compatible INI configuration
 // Outputs proper INI sections and i2pd-specific properties
 func (c *Converter) generateINI(config *TunnelConfig) ([]byte, error) {
@@ -380,7 +479,12 @@ func (c *Converter) generateINI(config *TunnelConfig) ([]byte, error) {
 
 	// Core tunnel properties
 	if config.Type != "" {
-		sb.WriteString(fmt.Sprintf("type = %s\n", config.Type))
+		typeName := c.typeName(config.Type, "ini")
+		// A datagram client is read back from the udptunnel alias
+		if typeName == "client" && config.SAMStyle() == "DATAGRAM" {
+			typeName = "udptunnel"
+		}
+		sb.WriteString(fmt.Sprintf("type = %s\n", typeName))
 	}
 	if config.Interface != "" {
 		sb.WriteString(fmt.Sprintf("host = %s\n", config.Interface))
@@ -390,9 +494,10 @@ func (c *Converter) generateINI(config *TunnelConfig) ([]byte, error) {
 	}
 
 	// Handle target based on tunnel type (destination for client, address for server)
+	serverAddress, combinedPort := iniServerAddress(config)
 	if config.Target != "" {
-		if config.Type == "server" || config.Type == "httpserver" || config.Type == "ircserver" {
-			sb.WriteString(fmt.Sprintf("address = %s\n", config.Target))
+		if TunnelType(config.Type).IsServer() {
+			sb.WriteString(fmt.Sprintf("address = %s\n", serverAddress))
 		} else {
 			sb.WriteString(fmt.Sprintf("destination = %s\n", config.Target))
 		}
@@ -404,36 +509,28 @@ func (c *Converter) generateINI(config *TunnelConfig) ([]byte, error) {
 
 	// Key management
 	if config.PersistentKey {
-		// Check if keyfile is specified in Tunnel options
-		if keyfile, ok := config.Tunnel["keyfile"]; ok {
-			sb.WriteString(fmt.Sprintf("keys = %s\n", keyfile))
-		} else {
-			// Generate default keyfile name
-			keyName := strings.ReplaceAll(config.Name, " ", "_")
-			if keyName == "" {
-				keyName = "tunnel"
-			}
-			sb.WriteString(fmt.Sprintf("keys = %s.dat\n", keyName))
-		}
+		sb.WriteString(fmt.Sprintf("keys = %s\n", iniKeyFile(config)))
 	} else {
 		sb.WriteString("keys = transient\n")
 	}
 
 	// I2CP options
-	for k, v := range config.I2CP {
+	for _, k := range sortedKeys(config.I2CP) {
+		v := config.I2CP[k]
 		sb.WriteString(fmt.Sprintf("i2cp.%s = %s\n", k, formatINIValue(v)))
 	}
 
 	// Tunnel options with i2pd-specific handling
-	for k, v := range config.Tunnel {
-		// Skip keyfile as it's handled above
-		if k == "keyfile" {
+	for _, k := range sortedKeys(config.Tunnel) {
+		v := config.Tunnel[k]
+		// Skip keyfile as it's handled above; i2pd has no sharedClient option
+		if k == "keyfile" || k == "sharedClient" || (k == "targetPort" && combinedPort) {
 			continue
 		}
 
 		// Handle special i2pd properties
 		switch k {
-		case "hostoverride", "gzip", "accesslist", "signaturetype", "explicitpeers",
+		case "hostoverride", "gzip", "accesslist", "signaturetype", "cryptotype", "explicitpeers",
 			"multicast", "webircpassword", "maptoloopback", "enableuniquelocal":
 			sb.WriteString(fmt.Sprintf("%s = %s\n", k, formatINIValue(v)))
 		default:
@@ -447,16 +544,23 @@ func (c *Converter) generateINI(config *TunnelConfig) ([]byte, error) {
 		}
 	}
 
-	// Inbound/Outbound options
-	for k, v := range config.Inbound {
-		sb.WriteString(fmt.Sprintf("inbound.%s = %s\n", k, formatINIValue(v)))
+	// Inbound/Outbound options, prefixed as the converter's INI dialect asks
+	poolPrefix := iniDialects[c.iniDialect]
+	for _, k := range sortedKeys(config.Inbound) {
+		v := config.Inbound[k]
+		sb.WriteString(fmt.Sprintf("%sinbound.%s = %s\n", poolPrefix, k, formatINIValue(v)))
 	}
 
-	for k, v := range config.Outbound {
-		sb.WriteString(fmt.Sprintf("outbound.%s = %s\n", k, formatINIValue(v)))
+	for _, k := range sortedKeys(config.Outbound) {
+		v := config.Outbound[k]
+		sb.WriteString(fmt.Sprintf("%soutbound.%s = %s\n", poolPrefix, k, formatINIValue(v)))
 	}
 
-	return []byte(sb.String()), nil
+	for _, k := range sortedKeys(config.Unknown) {
+		sb.WriteString(fmt.Sprintf("%s = %s\n", k, config.Unknown[k]))
+	}
+
+	return applyComments([]byte(sb.String()), "ini", config.Comments, iniLineKey), nil
 }
 
 // formatINIValue formats a value for INI output
@@ -465,6 +569,12 @@ func formatINIValue(v interface{}) string {
 	switch val := v.(type) {
 	case []string:
 		return strings.Join(val, ", ")
+	case []interface{}:
+		parts := make([]string, len(val))
+		for i, item := range val {
+			parts[i] = fmt.Sprint(item)
+		}
+		return strings.Join(parts, ", ")
 	case bool:
 		if val {
 			return "true"
@@ -1,6 +1,8 @@
 package i2pconv
 
 import (
+	"errors"
+	"os"
 	"reflect"
 	"strings"
 	"testing"
@@ -360,10 +362,15 @@ option10 = disabled`
 		"option10": false, // "disabled"
 	}
 
+	// Unrecognised keys are kept verbatim in Unknown; the typed value is what
+	// parseINIValue produces for a recognised key with the same text.
 	for k, expected := range expectedValues {
-		if actual, ok := config2.Tunnel[k]; !ok {
+		raw, ok := config2.Unknown[k]
+		if !ok {
 			t.Errorf("Option %q missing", k)
-		} else if actual != expected {
+			continue
+		}
+		if actual := parseINIValue(raw); actual != expected {
 			t.Errorf("Option %q: expected %v (%T), got %v (%T)", k, expected, expected, actual, actual)
 		}
 	}
@@ -570,11 +577,11 @@ func TestINIKeyValueCoverage(t *testing.T) {
 		}
 	})
 
-	t.Run("unknown key with nil Tunnel initialises map", func(t *testing.T) {
+	t.Run("unknown key with nil Unknown initialises map", func(t *testing.T) {
 		c := nilConfig()
 		conv.parseINIKeyValue("customoption", "customvalue", c)
-		if got, ok := c.Tunnel["customoption"]; !ok || got != "customvalue" {
-			t.Errorf("expected Tunnel[customoption]='customvalue', got %v", got)
+		if got, ok := c.Unknown["customoption"]; !ok || got != "customvalue" {
+			t.Errorf("expected Unknown[customoption]='customvalue', got %v", got)
 		}
 	})
 
@@ -737,3 +744,469 @@ func TestINITypeNormalizationRoundTrip(t *testing.T) {
 		t.Errorf("properties output missing type=httpclient:\n%s", props)
 	}
 }
+
+// TestINIUnknownKeysRoundTrip verifies that vendor-specific keys the parser does
+// not understand survive an INI→INI conversion unchanged, and that converting
+// them to another format is reported.
+func TestINIUnknownKeysRoundTrip(t *testing.T) {
+	input := "[proxy]\ntype = client\nhost = 127.0.0.1\nport = 4444\ndestination = example.i2p\nfoobar = baz\nx-vendor.flag = On, Off\n"
+
+	conv := &Converter{}
+	config, err := conv.ParseInput([]byte(input), "ini")
+	if err != nil {
+		t.Fatalf("ParseInput() error = %v", err)
+	}
+	if config.Unknown["foobar"] != "baz" {
+		t.Errorf("Unknown[foobar] = %q, want %q", config.Unknown["foobar"], "baz")
+	}
+	if _, leaked := config.Tunnel["foobar"]; leaked {
+		t.Error("unknown keys must not be stored in the Tunnel map")
+	}
+
+	out, err := conv.Convert([]byte(input), "ini", "ini")
+	if err != nil {
+		t.Fatalf("Convert() error = %v", err)
+	}
+	for _, line := range []string{"foobar = baz\n", "x-vendor.flag = On, Off\n"} {
+		if !strings.Contains(string(out), line) {
+			t.Errorf("INI output missing %q:\n%s", line, out)
+		}
+	}
+
+	if err := checkUnknownPassthrough(config, "ini", "ini"); err != nil {
+		t.Errorf("same-format conversion should not warn, got %v", err)
+	}
+	if err := checkUnknownPassthrough(config, "ini", "properties"); err == nil || !strings.Contains(err.Error(), "foobar") {
+		t.Errorf("cross-format conversion should warn about foobar, got %v", err)
+	}
+}
+
+// TestINICommentsRoundTrip verifies that header, leading, inline, and trailing
+// comments survive an INI→INI conversion and are dropped for other formats.
+func TestINICommentsRoundTrip(t *testing.T) {
+	input := `; Tunnels for the web proxy
+; maintained by hand
+
+[proxy]
+; local bind address
+host = 127.0.0.1
+port = 4444 ; change if 4444 is taken
+type = http
+webircpassword = pass#word
+description = Tunnel #1 proxy ; the first one
+; end of proxy
+`
+	conv := &Converter{}
+	config, err := conv.ParseInput([]byte(input), "ini")
+	if err != nil {
+		t.Fatalf("ParseInput() error = %v", err)
+	}
+	if config.Port != 4444 {
+		t.Errorf("inline comment should be stripped from the value, port = %d", config.Port)
+	}
+	if config.Tunnel["webircpassword"] != "pass#word" {
+		t.Errorf("'#' without preceding whitespace is part of the value, got %v", config.Tunnel["webircpassword"])
+	}
+	if config.Description != "Tunnel #1 proxy ; the first one" {
+		t.Errorf("comment markers in a description are part of the value, got %q", config.Description)
+	}
+
+	out, err := conv.generateINI(config)
+	if err != nil {
+		t.Fatalf("generateINI() error = %v", err)
+	}
+	got := string(out)
+	for _, want := range []string{
+		"; Tunnels for the web proxy\n; maintained by hand\n\n[proxy]\n",
+		"; local bind address\nhost = 127.0.0.1\n",
+		"port = 4444 ; change if 4444 is taken\n",
+		"; end of proxy\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("regenerated INI missing %q:\n%s", want, got)
+		}
+	}
+
+	yamlOut, err := conv.generateOutput(config, "yaml")
+	if err != nil {
+		t.Fatalf("generateOutput(yaml) error = %v", err)
+	}
+	if strings.Contains(string(yamlOut), "local bind address") {
+		t.Errorf("comments must not leak into other formats:\n%s", yamlOut)
+	}
+}
+
+// TestSharedClientToINI checks that sharedClient, which i2pd has no equivalent
+// for, is left out of INI output with a warning and kept for other formats.
+func TestSharedClientToINI(t *testing.T) {
+	input := []byte(`tunnel.0.name=shared
+tunnel.0.type=httpclient
+tunnel.0.listenPort=4444
+tunnel.0.sharedClient=true
+`)
+	conv := &Converter{}
+	config, err := conv.ParseInput(input, "properties")
+	if err != nil {
+		t.Fatalf("ParseInput() error = %v", err)
+	}
+
+	out, err := conv.generateINI(config)
+	if err != nil {
+		t.Fatalf("generateINI() error = %v", err)
+	}
+	if strings.Contains(string(out), "sharedClient") {
+		t.Errorf("INI output should not contain sharedClient:\n%s", out)
+	}
+	if err := checkSharedClient(config, "ini"); err == nil || !strings.Contains(err.Error(), "shared client") {
+		t.Errorf("checkSharedClient(ini) = %v, want shared client warning", err)
+	}
+
+	// Other targets understand sharedClient and keep it.
+	if err := checkSharedClient(config, "yaml"); err != nil {
+		t.Errorf("checkSharedClient(yaml) = %v, want nil", err)
+	}
+	config.Tunnel["sharedClient"] = false
+	if err := checkSharedClient(config, "ini"); err != nil {
+		t.Errorf("checkSharedClient(ini) with sharedClient=false = %v, want nil", err)
+	}
+}
+
+// TestParseINI_DuplicateKey checks that a key repeated within an INI section
+// keeps its last value, and is a ParseError naming both lines in strict mode.
+func TestParseINI_DuplicateKey(t *testing.T) {
+	input := "[dup]\ntype = httpclient\nport = 4444\ntype = server\n"
+
+	config, err := (&Converter{}).parseINI([]byte(input))
+	if err != nil {
+		t.Fatalf("non-strict parse should succeed, got: %v", err)
+	}
+	if config.Type != "server" {
+		t.Errorf("non-strict parse should keep the last value, got %q", config.Type)
+	}
+
+	_, err = (&Converter{strict: true}).parseINI([]byte(input))
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) {
+		t.Fatalf("strict parse should return *ParseError, got: %v", err)
+	}
+	if parseErr.Line != 4 || !strings.Contains(parseErr.Message, "duplicate key 'type' (first defined on line 2)") {
+		t.Errorf("unexpected ParseError: line %d, message %q", parseErr.Line, parseErr.Message)
+	}
+
+	// The same key in different sections belongs to different tunnels.
+	if dups := findDuplicateINIKeys([]byte("[a]\ntype = client\n[b]\ntype = server\n")); len(dups) != 0 {
+		t.Errorf("keys in separate sections reported as duplicates: %v", dups)
+	}
+}
+
+// TestWarnDuplicateKeys checks the warning printed for a repeated key in INI
+// and properties input.
+func TestWarnDuplicateKeys(t *testing.T) {
+	tests := []struct {
+		format string
+		input  string
+	}{
+		{"ini", "[dup]\ntype = httpclient\nport = 4444\ntype = server\n"},
+		{"properties", "name=dup\ntype=httpclient\nlistenPort=4444\ntype=server\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			out := captureOutput(t, &os.Stderr, func() {
+				warnDuplicateKeys([]byte(tt.input), tt.format, "dup."+tt.format)
+			})
+			want := "line 4: duplicate key 'type' (first defined on line 2)"
+			if !strings.Contains(out, want) {
+				t.Errorf("expected %q in warning, got %q", want, out)
+			}
+		})
+	}
+}
+
+// TestCryptoTypeRoundTrip checks that i2pd's cryptotype becomes Java I2P's
+// leaseSetEncType in properties output and comes back as i2cp.leaseSetEncType
+// in INI.
+func TestCryptoTypeRoundTrip(t *testing.T) {
+	conv := &Converter{}
+	config, err := conv.parseINI([]byte("[web]\ntype = client\nport = 4444\ndestination = example.i2p\ncryptotype = 4\n"))
+	if err != nil {
+		t.Fatalf("parseINI() error = %v", err)
+	}
+	if got := config.Tunnel["cryptotype"]; got != 4 {
+		t.Fatalf("Tunnel[cryptotype] = %#v, want 4", got)
+	}
+	if _, ok := config.Unknown["cryptotype"]; ok {
+		t.Error("cryptotype should be recognised, not kept as unknown")
+	}
+
+	ini, err := conv.generateINI(config)
+	if err != nil {
+		t.Fatalf("generateINI() error = %v", err)
+	}
+	if !strings.Contains(string(ini), "cryptotype = 4\n") {
+		t.Errorf("INI output missing cryptotype:\n%s", ini)
+	}
+
+	props, err := conv.generateJavaProperties(config)
+	if err != nil {
+		t.Fatalf("generateJavaProperties() error = %v", err)
+	}
+	if !strings.Contains(string(props), "option.i2cp.leaseSetEncType=4\n") {
+		t.Errorf("properties output missing leaseSetEncType:\n%s", props)
+	}
+	if strings.Contains(string(props), "cryptotype") {
+		t.Errorf("properties output should not contain cryptotype:\n%s", props)
+	}
+	if err := checkCryptoType(config, "properties"); err != nil {
+		t.Errorf("checkCryptoType() = %v, want nil for a mapped type", err)
+	}
+
+	back, err := conv.parseJavaProperties(props)
+	if err != nil {
+		t.Fatalf("parseJavaProperties() error = %v", err)
+	}
+	ini, err = conv.generateINI(back)
+	if err != nil {
+		t.Fatalf("generateINI() error = %v", err)
+	}
+	if !strings.Contains(string(ini), "i2cp.leaseSetEncType = 4\n") {
+		t.Errorf("INI from properties missing i2cp.leaseSetEncType:\n%s", ini)
+	}
+}
+
+// TestCryptoTypeLossy checks the warning for a cryptotype that conflicts with
+// the tunnel's leaseSetEncType or has no Java I2P equivalent.
+func TestCryptoTypeLossy(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   *TunnelConfig
+		wantEnc  string
+		wantWarn string
+	}{
+		{
+			name:    "matches existing list",
+			config:  &TunnelConfig{Name: "a", Tunnel: map[string]interface{}{"cryptotype": 4}, I2CP: map[string]interface{}{"leaseSetEncType": "4,0"}},
+			wantEnc: "option.i2cp.leaseSetEncType=4,0\n",
+		},
+		{
+			name:     "conflicts with existing",
+			config:   &TunnelConfig{Name: "b", Tunnel: map[string]interface{}{"cryptotype": 4}, I2CP: map[string]interface{}{"leaseSetEncType": 0}},
+			wantEnc:  "option.i2cp.leaseSetEncType=0\n",
+			wantWarn: "already 0",
+		},
+		{
+			name:     "unsupported type",
+			config:   &TunnelConfig{Name: "c", Tunnel: map[string]interface{}{"cryptotype": 9}},
+			wantWarn: "no Java I2P equivalent",
+		},
+	}
+
+	conv := &Converter{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			props, err := conv.generateJavaProperties(tt.config)
+			if err != nil {
+				t.Fatalf("generateJavaProperties() error = %v", err)
+			}
+			if tt.wantEnc != "" && !strings.Contains(string(props), tt.wantEnc) {
+				t.Errorf("properties output missing %q:\n%s", tt.wantEnc, props)
+			}
+			if tt.wantEnc == "" && strings.Contains(string(props), "leaseSetEncType") {
+				t.Errorf("properties output should not set leaseSetEncType:\n%s", props)
+			}
+			err = checkCryptoType(tt.config, "properties")
+			if tt.wantWarn == "" {
+				if err != nil {
+					t.Errorf("checkCryptoType() = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantWarn) {
+				t.Errorf("checkCryptoType() = %v, want warning containing %q", err, tt.wantWarn)
+			}
+			if err := checkCryptoType(tt.config, "ini"); err != nil {
+				t.Errorf("checkCryptoType(ini) = %v, want nil", err)
+			}
+		})
+	}
+}
+
+// TestINITunnelPoolKeys checks that i2pd's lower-case tunnel pool keys are
+// read under their canonical names and written as Java I2P options.
+func TestINITunnelPoolKeys(t *testing.T) {
+	input := "[web]\ntype = http\nport = 8118\ninbound.quantity = 4\ninbound.backupquantity = 1\noutbound.LENGTHVARIANCE = 1\n"
+	conv := &Converter{}
+	config, err := conv.ParseInput([]byte(input), "ini")
+	if err != nil {
+		t.Fatalf("ParseInput() error = %v", err)
+	}
+	if !reflect.DeepEqual(config.Inbound, map[string]interface{}{"quantity": 4, "backupQuantity": 1}) {
+		t.Errorf("Inbound = %#v", config.Inbound)
+	}
+	if !reflect.DeepEqual(config.Outbound, map[string]interface{}{"lengthVariance": 1}) {
+		t.Errorf("Outbound = %#v", config.Outbound)
+	}
+	if err := NewValidationContext(true, "ini").Validate(config); err != nil {
+		t.Errorf("strict Validate() error = %v", err)
+	}
+
+	out, err := conv.generateOutput(config, "properties")
+	if err != nil {
+		t.Fatalf("generateOutput() error = %v", err)
+	}
+	for _, line := range []string{"option.inbound.backupQuantity=1\n", "option.inbound.quantity=4\n", "option.outbound.lengthVariance=1\n"} {
+		if !strings.Contains(string(out), line) {
+			t.Errorf("properties output lacks %q:\n%s", line, out)
+		}
+	}
+}
+
+// TestINIDialect checks that each INI dialect writes its own tunnel pool key
+// prefix and that the parser reads both back to the same options.
+func TestINIDialect(t *testing.T) {
+	config := &TunnelConfig{
+		Name:     "web",
+		Type:     "httpclient",
+		Port:     4444,
+		Inbound:  map[string]interface{}{"length": 2},
+		Outbound: map[string]interface{}{"length": 3},
+		Comments: &Comments{Format: "ini", Leading: map[string][]string{"inbound.length": {"# shorter inbound"}}},
+	}
+	tests := []struct {
+		dialect string
+		want    []string
+		notWant string
+	}{
+		{"", []string{"\ninbound.length = 2\n", "\noutbound.length = 3\n"}, "tunnels."},
+		{"standard", []string{"\ninbound.length = 2\n", "\noutbound.length = 3\n"}, "tunnels."},
+		{"prefixed", []string{"\ntunnels.inbound.length = 2\n", "\ntunnels.outbound.length = 3\n"}, "\ninbound.length"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.dialect, func(t *testing.T) {
+			conv := NewConverterWithOptions(ConverterOptions{INIDialect: tt.dialect})
+			out, err := conv.generateOutput(config, "ini")
+			if err != nil {
+				t.Fatalf("generateOutput() error = %v", err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(string(out), want) {
+					t.Errorf("output lacks %q:\n%s", want, out)
+				}
+			}
+			if strings.Contains(string(out), tt.notWant) {
+				t.Errorf("output should not contain %q:\n%s", tt.notWant, out)
+			}
+			if !strings.Contains(string(out), "# shorter inbound\n") {
+				t.Errorf("comment on inbound.length lost:\n%s", out)
+			}
+
+			parsed, err := (&Converter{}).ParseInput(out, "ini")
+			if err != nil {
+				t.Fatalf("ParseInput() error = %v", err)
+			}
+			if !reflect.DeepEqual(parsed.Inbound, config.Inbound) || !reflect.DeepEqual(parsed.Outbound, config.Outbound) {
+				t.Errorf("parsed Inbound = %#v, Outbound = %#v", parsed.Inbound, parsed.Outbound)
+			}
+			if len(parsed.Unknown) != 0 {
+				t.Errorf("pool keys parsed as unknown: %#v", parsed.Unknown)
+			}
+		})
+	}
+}
+
+// TestGenerateINI_CombinesTargetHostAndPort checks that a Java I2P server's
+// separate targetHost and targetPort become one i2pd address.
+func TestGenerateINI_CombinesTargetHostAndPort(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{name: "host and port", input: "name=web\ntype=httpserver\ntargetHost=10.0.0.5\ntargetPort=8080\n", want: "address = 10.0.0.5:8080\n"},
+		{name: "ipv6 host", input: "name=web\ntype=server\ntargetHost=::1\ntargetPort=8080\n", want: "address = [::1]:8080\n"},
+		{name: "target with port", input: "name=web\ntype=server\ntargetHost=10.0.0.5:9090\ntargetPort=8080\n", want: "address = 10.0.0.5:9090\n"},
+		{name: "no port", input: "name=web\ntype=server\ntargetHost=10.0.0.5\n", want: "address = 10.0.0.5\n"},
+	}
+	conv := &Converter{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config, err := conv.ParseInput([]byte(tt.input), "properties")
+			if err != nil {
+				t.Fatalf("ParseInput() error = %v", err)
+			}
+			out, err := conv.generateOutput(config, "ini")
+			if err != nil {
+				t.Fatalf("generateOutput() error = %v", err)
+			}
+			if !strings.Contains(string(out), tt.want) {
+				t.Errorf("INI output lacks %q:\n%s", tt.want, out)
+			}
+			if strings.HasSuffix(tt.want, ":8080\n") && strings.Contains(string(out), "targetPort") {
+				t.Errorf("targetPort written besides the combined address:\n%s", out)
+			}
+			if err := verifyOutput(config, out, "ini", conv); err != nil {
+				t.Errorf("verifyOutput() error = %v", err)
+			}
+		})
+	}
+}
+
+// TestGenerateTarget_ServerTypes checks that the target of every server type is
+// written as the address it forwards to, and that of a client type as its
+// destination, in INI and properties output.
+func TestGenerateTarget_ServerTypes(t *testing.T) {
+	tests := []struct {
+		tunnelType string
+		server     bool
+	}{
+		{"server", true},
+		{"httpserver", true},
+		{"ircserver", true},
+		{"httpbidirserver", true},
+		{"streamrserver", true},
+		{"socksserver", true},
+		{"client", false},
+		{"httpclient", false},
+		{"streamrclient", false},
+	}
+	conv := &Converter{}
+	for _, tt := range tests {
+		t.Run(tt.tunnelType, func(t *testing.T) {
+			config := &TunnelConfig{Name: "t", Type: tt.tunnelType, Port: 8888, Target: "127.0.0.1"}
+			wantINI, wantProperties := "destination = 127.0.0.1\n", "targetDestination=127.0.0.1\n"
+			if tt.server {
+				wantINI, wantProperties = "address = 127.0.0.1\n", "targetHost=127.0.0.1\n"
+			}
+			for format, want := range map[string]string{"ini": wantINI, "properties": wantProperties} {
+				out, err := conv.generateOutput(config, format)
+				if err != nil {
+					t.Fatalf("generateOutput(%s) error = %v", format, err)
+				}
+				if !strings.Contains(string(out), want) {
+					t.Errorf("%s output lacks %q:\n%s", format, want, out)
+				}
+			}
+		})
+	}
+}
+
+// TestGenerateINI_HTTPBidirServerAddress checks that an httpbidirserver's
+// target host and port become its INI address and survive verification.
+func TestGenerateINI_HTTPBidirServerAddress(t *testing.T) {
+	input := "name=bidir\ntype=httpbidirserver\ntargetHost=127.0.0.1\ntargetPort=8080\nlistenPort=8888\n"
+	conv := &Converter{}
+	config, err := conv.ParseInput([]byte(input), "properties")
+	if err != nil {
+		t.Fatalf("ParseInput() error = %v", err)
+	}
+	out, err := conv.generateOutput(config, "ini")
+	if err != nil {
+		t.Fatalf("generateOutput() error = %v", err)
+	}
+	if !strings.Contains(string(out), "address = 127.0.0.1:8080\n") || strings.Contains(string(out), "destination =") {
+		t.Errorf("httpbidirserver target not written as the server address:\n%s", out)
+	}
+	if err := verifyOutput(config, out, "ini", conv); err != nil {
+		t.Errorf("verifyOutput() error = %v", err)
+	}
+}
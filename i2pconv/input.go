@@ -0,0 +1,64 @@
+package i2pconv
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// gzipExt is the extension of compressed inputs. The extension before it
+// names the format, as in "tunnel.config.gz".
+const gzipExt = ".gz"
+
+// utf8BOM is the byte order mark some Windows editors write at the start of a
+// UTF-8 file.
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// normalizeInput strips a leading UTF-8 byte order mark and converts CRLF and
+// lone CR line endings to LF, so the parsers see the same bytes regardless of
+// the platform the file was written on. Without it the BOM becomes part of the
+// first key and values keep a trailing carriage return.
+func normalizeInput(input []byte) []byte {
+	input = bytes.TrimPrefix(input, utf8BOM)
+	if bytes.IndexByte(input, '\r') < 0 {
+		return input
+	}
+	input = bytes.ReplaceAll(input, []byte("\r\n"), []byte("\n"))
+	return bytes.ReplaceAll(input, []byte("\r"), []byte("\n"))
+}
+
+// readInputFile reads the config file at path, decompressing it first when
+// its name ends in .gz.
+func readInputFile(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil || !isGzipPath(path) {
+		return data, err
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress gzip input: %w", err)
+	}
+	defer zr.Close()
+	if data, err = io.ReadAll(zr); err != nil {
+		return nil, fmt.Errorf("failed to decompress gzip input: %w", err)
+	}
+	return data, nil
+}
+
+// isGzipPath reports whether path names a gzip-compressed input.
+func isGzipPath(path string) bool {
+	return strings.EqualFold(filepath.Ext(path), gzipExt)
+}
+
+// trimGzipExt returns path without a trailing .gz, so the extension left
+// names the format of the compressed file.
+func trimGzipExt(path string) string {
+	if isGzipPath(path) {
+		return path[:len(path)-len(gzipExt)]
+	}
+	return path
+}
@@ -0,0 +1,148 @@
+package i2pconv
+
+import (
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestNormalizeInput checks that a leading byte order mark is dropped and CRLF
+// and lone CR line endings become LF.
+func TestNormalizeInput(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{name: "unchanged", input: "a=1\nb=2\n", want: "a=1\nb=2\n"},
+		{name: "bom", input: "\ufeffa=1\n", want: "a=1\n"},
+		{name: "crlf", input: "a=1\r\nb=2\r\n", want: "a=1\nb=2\n"},
+		{name: "lone cr", input: "a=1\rb=2\r", want: "a=1\nb=2\n"},
+		{name: "bom and crlf", input: "\ufeffa=1\r\n", want: "a=1\n"},
+		{name: "bom only at start", input: "a=\ufeff\n", want: "a=\ufeff\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(normalizeInput([]byte(tt.input))); got != tt.want {
+				t.Errorf("normalizeInput(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestParseInput_BOMAndCRLF parses each format saved with a byte order mark and
+// CRLF line endings and checks that neither leaks into the parsed fields.
+func TestParseInput_BOMAndCRLF(t *testing.T) {
+	inputs := map[string]string{
+		"properties": "name=web\ntype=httpclient\ninterface=127.0.0.1\nlistenPort=4480\ndescription=Windows tunnel\noption.inbound.length=2\n",
+		"ini":        "[web]\ntype = httpclient\naddress = 127.0.0.1\nport = 4480\ndescription = Windows tunnel\ninbound.length = 2\n",
+		"yaml":       "tunnels:\n  web:\n    name: web\n    type: httpclient\n    interface: 127.0.0.1\n    port: 4480\n    description: Windows tunnel\n    inbound:\n      length: 2\n",
+	}
+	for format, input := range inputs {
+		t.Run(format, func(t *testing.T) {
+			windows := "\ufeff" + strings.ReplaceAll(input, "\n", "\r\n")
+			config, err := (&Converter{}).ParseInput([]byte(windows), format)
+			if err != nil {
+				t.Fatalf("ParseInput() error = %v", err)
+			}
+			if config.Name != "web" || config.Type != "httpclient" || config.Port != 4480 {
+				t.Errorf("name/type/port = %q/%q/%d, want web/httpclient/4480", config.Name, config.Type, config.Port)
+			}
+			if config.Description != "Windows tunnel" {
+				t.Errorf("Description = %q, want no trailing carriage return", config.Description)
+			}
+			if config.Interface != "127.0.0.1" {
+				t.Errorf("Interface = %q", config.Interface)
+			}
+			if len(config.Unknown) != 0 {
+				t.Errorf("Unknown = %v, want the BOM not to hide a key", config.Unknown)
+			}
+
+			plain, err := (&Converter{}).ParseInput([]byte(input), format)
+			if err != nil {
+				t.Fatalf("ParseInput() of LF input error = %v", err)
+			}
+			if diffs := Diff(plain, config); len(diffs) != 0 {
+				t.Errorf("BOM/CRLF input parsed differently from LF input: %v", diffs)
+			}
+		})
+	}
+}
+
+// TestProcessSingleFile_GzipInput converts a gzipped properties file to YAML.
+// The format comes from the extension before .gz, and the output is written
+// uncompressed next to the input.
+func TestProcessSingleFile_GzipInput(t *testing.T) {
+	dir := t.TempDir()
+	inputFile := filepath.Join(dir, "tunnel.config.gz")
+	writeGzipFile(t, inputFile, "name=web\ntype=httpclient\nlistenPort=4444\noption.i2cp.reduceIdleTime=900000\n")
+
+	if _, err := processSingleFile(inputFile, "", processOptions{outputFormat: "yaml"}, &Converter{}); err != nil {
+		t.Fatalf("processSingleFile() error = %v", err)
+	}
+	out, err := os.ReadFile(filepath.Join(dir, "tunnel.yaml"))
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	for _, want := range []string{"name: web\n", "port: 4444\n", "reduceIdleTime: 900000\n"} {
+		if !strings.Contains(string(out), want) {
+			t.Errorf("output lacks %q:\n%s", want, out)
+		}
+	}
+
+	_, err = processSingleFile(inputFile, "", processOptions{outputFormat: "properties", inPlace: true}, &Converter{})
+	if err == nil || !strings.Contains(err.Error(), "compressed") {
+		t.Errorf("--in-place on a .gz input: got %v, want a compressed-input error", err)
+	}
+
+	plain := filepath.Join(dir, "plain.config.gz")
+	if err := os.WriteFile(plain, []byte("name=web\n"), 0o644); err != nil {
+		t.Fatalf("setup: %v", err)
+	}
+	if _, err := processSingleFile(plain, "", processOptions{outputFormat: "yaml"}, &Converter{}); err == nil || !strings.Contains(err.Error(), "decompress") {
+		t.Errorf("non-gzip data in a .gz file: got %v, want a decompress error", err)
+	}
+}
+
+// writeGzipFile writes content to path gzip-compressed.
+func writeGzipFile(t *testing.T, path, content string) {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write([]byte(content)); err != nil {
+		t.Fatalf("setup: %v", err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("setup: %v", err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		t.Fatalf("setup: %v", err)
+	}
+}
+
+// TestGzipSideInputs checks that the --base template and the
+// --merge-i2cp-from file are decompressed when their names end in .gz.
+func TestGzipSideInputs(t *testing.T) {
+	dir := t.TempDir()
+	basePath := filepath.Join(dir, "common.yaml.gz")
+	writeGzipFile(t, basePath, "i2cp:\n  reduceIdleTime: 900000\n")
+	sharedPath := filepath.Join(dir, "shared.config.gz")
+	writeGzipFile(t, sharedPath, "name=shared\ntype=httpclient\nlistenPort=4444\noption.i2cp.closeOnIdle=true\n")
+
+	config := &TunnelConfig{Name: "web", Type: "httpclient", Port: 8080}
+	merged, err := applyBaseConfig(config, basePath, &Converter{})
+	if err != nil {
+		t.Fatalf("applyBaseConfig() error = %v", err)
+	}
+	if err := mergeSharedI2CP(merged, sharedPath, &Converter{}); err != nil {
+		t.Fatalf("mergeSharedI2CP() error = %v", err)
+	}
+	for _, key := range []string{"reduceIdleTime", "closeOnIdle"} {
+		if _, ok := merged.I2CP[key]; !ok {
+			t.Errorf("i2cp.%s missing after merging gzipped inputs: %#v", key, merged.I2CP)
+		}
+	}
+}
@@ -0,0 +1,198 @@
+package i2pconv
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/go-i2p/i2pkeys"
+)
+
+// i2pBase64 is the base64 alphabet used by I2P, with '-' and '~' in place of
+// '+' and '/'.
+var i2pBase64 = base64.NewEncoding("ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789-~")
+
+// defaultSignatureType is the signing key type of generated destinations
+// when a tunnel sets no signaturetype: Ed25519, as i2pkeys.NewDestination uses.
+const defaultSignatureType = 7
+
+// samAddress is the SAM bridge that generates destinations for new keys.
+const samAddress = "127.0.0.1:7656"
+
+// keyGenerationTimeout bounds the SAM conversation that generates a
+// destination.
+const keyGenerationTimeout = 30 * time.Second
+
+// newDestination creates a fresh destination with the given signing key type.
+// It asks the SAM bridge at samAddress and is replaced in tests.
+var newDestination = func(sigType int) (*i2pkeys.I2PKeys, error) {
+	return generateDestination(samAddress, sigType)
+}
+
+// generateDestination asks the SAM bridge at addr for a new destination with
+// signing key type sigType.
+func generateDestination(addr string, sigType int) (*i2pkeys.I2PKeys, error) {
+	conn, r, err := dialSAM(addr, keyGenerationTimeout)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	// DEST REPLY carries no RESULT, so samRequest cannot be used here
+	if _, err := fmt.Fprintf(conn, "DEST GENERATE SIGNATURE_TYPE=%d\n", sigType); err != nil {
+		return nil, fmt.Errorf("failed to write to SAM bridge: %w", err)
+	}
+	reply, err := r.ReadString('\n')
+	if err != nil {
+		return nil, fmt.Errorf("failed to read destination from SAM bridge: %w", err)
+	}
+	var pub, priv string
+	for _, field := range strings.Fields(reply) {
+		if v, ok := strings.CutPrefix(field, "PUB="); ok {
+			pub = v
+		} else if v, ok := strings.CutPrefix(field, "PRIV="); ok {
+			priv = v
+		}
+	}
+	if pub == "" || priv == "" {
+		return nil, fmt.Errorf("SAM bridge returned no destination: %s", strings.TrimSpace(reply))
+	}
+	// PRIV holds the public destination followed by the private keys
+	keys := i2pkeys.NewKeys(i2pkeys.I2PAddr(pub), priv)
+	return &keys, nil
+}
+
+// LoadKeyFile reads the private keys of a destination from path, detecting
+// the file's format by its content:
+//   - two lines, the base64 destination and the base64 private keys, as
+//     written by SAMTunnel (i2pkeys.StoreKeysIncompat);
+//   - one line holding only the base64 private keys, the SAMv3 DESTINATION
+//     value that Java I2P exports;
+//   - the same private keys in binary, as i2pd keeps them in its .dat files.
+//
+// For the last two the destination is read from the front of the private keys.
+func LoadKeyFile(path string) (*i2pkeys.I2PKeys, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key file %s: %w", path, err)
+	}
+
+	text := strings.TrimSpace(string(data))
+	if lines := strings.Fields(text); len(lines) == 2 && isI2PBase64(lines[0]) && isI2PBase64(lines[1]) {
+		keys := i2pkeys.NewKeys(i2pkeys.I2PAddr(lines[0]), lines[1])
+		return &keys, nil
+	}
+	blob := data
+	if isI2PBase64(text) {
+		if blob, err = i2pBase64.DecodeString(text); err != nil {
+			return nil, fmt.Errorf("failed to decode key file %s: %w", path, err)
+		}
+	}
+	dest, err := privateKeysDestination(blob)
+	if err != nil {
+		return nil, fmt.Errorf("key file %s holds no usable private keys: %w", path, err)
+	}
+	keys := i2pkeys.NewKeys(i2pkeys.I2PAddr(i2pBase64.EncodeToString(dest)), i2pBase64.EncodeToString(blob))
+	return &keys, nil
+}
+
+// isI2PBase64 reports whether s is non-empty and decodes as I2P base64.
+func isI2PBase64(s string) bool {
+	if s == "" {
+		return false
+	}
+	_, err := i2pBase64.DecodeString(s)
+	return err == nil
+}
+
+// privateKeysDestination returns the destination at the front of the private
+// keys blob: the 384 bytes of public keys and the certificate after them. The
+// 256-byte encryption private key must follow it.
+func privateKeysDestination(blob []byte) ([]byte, error) {
+	const certOffset = 384
+	if len(blob) < certOffset+3 {
+		return nil, fmt.Errorf("too short for a destination (%d bytes)", len(blob))
+	}
+	end := certOffset + 3 + int(binary.BigEndian.Uint16(blob[certOffset+1:]))
+	if len(blob) < end+256 {
+		return nil, fmt.Errorf("too short for a destination and its private keys (%d bytes)", len(blob))
+	}
+	return blob[:end], nil
+}
+
+// tunnelSignatureType returns the signing key type new keys for config should
+// use: its signaturetype option, or defaultSignatureType when unset.
+func tunnelSignatureType(config *TunnelConfig) (int, error) {
+	if _, ok := config.Tunnel["signaturetype"]; !ok {
+		return defaultSignatureType, nil
+	}
+	if err := validateSignatureType(config); err != nil {
+		return 0, err
+	}
+	return coerceInt(config.Tunnel["signaturetype"])
+}
+
+// iniKeyFile returns the keys file an i2pd config for config refers to: the
+// parsed keyfile, or the tunnel name with spaces replaced and ".dat" added.
+func iniKeyFile(config *TunnelConfig) string {
+	if keyfile, ok := config.Tunnel["keyfile"]; ok {
+		return fmt.Sprint(keyfile)
+	}
+	keyName := strings.ReplaceAll(config.Name, " ", "_")
+	if keyName == "" {
+		keyName = "tunnel"
+	}
+	return keyName + ".dat"
+}
+
+// tunnelKeyFileName returns the base name of the key file written by
+// --generate-keys. i2pd output gets the binary private key file its keys
+// option names; the other formats get a SAM .keys file, as SAMTunnel writes.
+func tunnelKeyFileName(config *TunnelConfig, outputFormat string) string {
+	if outputFormat == "ini" {
+		return filepath.Base(iniKeyFile(config))
+	}
+	return config.Name + ".keys"
+}
+
+// writeTunnelKeys generates a destination for a persistent tunnel and writes
+// its key file into dir. An existing key file is never replaced, since it
+// holds the tunnel's identity. It returns the key file path and whether a new
+// file was written.
+func writeTunnelKeys(config *TunnelConfig, dir, outputFormat string) (string, bool, error) {
+	path := filepath.Join(dir, tunnelKeyFileName(config, outputFormat))
+	if _, err := os.Stat(path); err == nil {
+		return path, false, nil
+	}
+
+	sigType, err := tunnelSignatureType(config)
+	if err != nil {
+		return path, false, fmt.Errorf("tunnel '%s': %w", config.Name, err)
+	}
+	keys, err := newDestination(sigType)
+	if err != nil {
+		return path, false, fmt.Errorf("failed to generate keys for tunnel '%s': %w", config.Name, err)
+	}
+	var data []byte
+	if outputFormat == "ini" {
+		// i2pd reads the raw private key file that SAM returns base64-encoded
+		data, err = i2pBase64.DecodeString(keys.Both)
+		if err != nil {
+			return path, false, fmt.Errorf("failed to decode keys for tunnel '%s': %w", config.Name, err)
+		}
+	} else {
+		var sb strings.Builder
+		if err := i2pkeys.StoreKeysIncompat(*keys, &sb); err != nil {
+			return path, false, fmt.Errorf("failed to encode keys for tunnel '%s': %w", config.Name, err)
+		}
+		data = []byte(sb.String())
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return path, false, fmt.Errorf("failed to write key file '%s': %w", path, err)
+	}
+	return path, true, nil
+}
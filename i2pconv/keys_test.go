@@ -0,0 +1,251 @@
+package i2pconv
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-i2p/i2pkeys"
+)
+
+// fakeDestinationAddress returns a base64 destination whose key certificate
+// records sigType, with zeroed keys.
+func fakeDestinationAddress(sigType int) string {
+	dest := make([]byte, 384, 391)
+	dest = append(dest, 5, 0, 4) // key certificate, 4-byte payload
+	dest = binary.BigEndian.AppendUint16(dest, uint16(sigType))
+	dest = binary.BigEndian.AppendUint16(dest, 0)
+	return i2pBase64.EncodeToString(dest)
+}
+
+// fakeDestination stubs newDestination for the duration of the test, so keys
+// can be generated without a SAM bridge. The destinations carry the requested
+// signature type. It returns a pointer to the number of destinations created.
+func fakeDestination(t *testing.T) *int {
+	t.Helper()
+	calls := 0
+	orig := newDestination
+	newDestination = func(sigType int) (*i2pkeys.I2PKeys, error) {
+		calls++
+		keys := i2pkeys.NewKeys(i2pkeys.I2PAddr(fakeDestinationAddress(sigType)), i2pBase64.EncodeToString([]byte("private key file")))
+		return &keys, nil
+	}
+	t.Cleanup(func() { newDestination = orig })
+	return &calls
+}
+
+// TestWriteSplitTunnels_GenerateKeys checks that splitting with key generation
+// writes one key file per persistent tunnel, in the format of the output, and
+// never replaces an existing one.
+func TestWriteSplitTunnels_GenerateKeys(t *testing.T) {
+	input := filepath.Join(t.TempDir(), "tunnels.conf")
+	ini := "[web]\ntype = server\nhost = 127.0.0.1\nport = 8080\nkeys = web-keys.dat\n\n" +
+		"[my proxy]\ntype = client\nport = 4444\ndestination = example.i2p\nkeys = /var/lib/i2pd/proxy.dat\n\n" +
+		"[temp]\ntype = client\nport = 5555\ndestination = example.i2p\nkeys = transient\n"
+	if err := os.WriteFile(input, []byte(ini), 0o644); err != nil {
+		t.Fatalf("setup: %v", err)
+	}
+
+	t.Run("ini output", func(t *testing.T) {
+		calls := fakeDestination(t)
+		dir := t.TempDir()
+		if err := writeSplitTunnels(input, "ini", "ini", dir, false, false, true, &Converter{}); err != nil {
+			t.Fatalf("writeSplitTunnels() error = %v", err)
+		}
+		data, err := os.ReadFile(filepath.Join(dir, "web-keys.dat"))
+		if err != nil {
+			t.Fatalf("key file for persistent tunnel not written: %v", err)
+		}
+		if !bytes.Equal(data, []byte("private key file")) {
+			t.Errorf("key file = %q, want the decoded private keys", data)
+		}
+		if _, err := os.Stat(filepath.Join(dir, "proxy.dat")); err != nil {
+			t.Errorf("key file for absolute keys path should be written by base name: %v", err)
+		}
+		entries, _ := os.ReadDir(dir)
+		for _, e := range entries {
+			if strings.HasPrefix(e.Name(), "temp") && !strings.HasSuffix(e.Name(), ".conf") {
+				t.Errorf("transient tunnel should get no key file, found %s", e.Name())
+			}
+		}
+		if *calls != 2 {
+			t.Errorf("generated %d destinations, want 2", *calls)
+		}
+	})
+
+	t.Run("yaml output keeps existing keys", func(t *testing.T) {
+		calls := fakeDestination(t)
+		dir := t.TempDir()
+		existing := filepath.Join(dir, "web.keys")
+		if err := os.WriteFile(existing, []byte("keep me"), 0o600); err != nil {
+			t.Fatalf("setup: %v", err)
+		}
+		if err := writeSplitTunnels(input, "ini", "yaml", dir, false, false, true, &Converter{}); err != nil {
+			t.Fatalf("writeSplitTunnels() error = %v", err)
+		}
+		if data, _ := os.ReadFile(existing); string(data) != "keep me" {
+			t.Errorf("existing key file was replaced: %q", data)
+		}
+		data, err := os.ReadFile(filepath.Join(dir, "my proxy.keys"))
+		if err != nil {
+			t.Fatalf("SAM key file not written: %v", err)
+		}
+		if !strings.HasPrefix(string(data), fakeDestinationAddress(defaultSignatureType)+"\n") {
+			t.Errorf("SAM key file = %q, want address and keys", data)
+		}
+		if _, err := os.Stat(filepath.Join(dir, "temp.keys")); err == nil {
+			t.Error("transient tunnel should get no key file")
+		}
+		if *calls != 1 {
+			t.Errorf("generated %d destinations, want 1", *calls)
+		}
+	})
+
+	t.Run("off by default", func(t *testing.T) {
+		calls := fakeDestination(t)
+		dir := t.TempDir()
+		if err := writeSplitTunnels(input, "ini", "ini", dir, false, false, false, &Converter{}); err != nil {
+			t.Fatalf("writeSplitTunnels() error = %v", err)
+		}
+		if _, err := os.Stat(filepath.Join(dir, "web-keys.dat")); err == nil {
+			t.Error("key file written without generateKeys")
+		}
+		if *calls != 0 {
+			t.Errorf("generated %d destinations, want 0", *calls)
+		}
+	})
+}
+
+// TestTunnelSignatureType checks the signing key type new keys use: the
+// tunnel's signaturetype, or the default when unset.
+func TestTunnelSignatureType(t *testing.T) {
+	tests := []struct {
+		name    string
+		tunnel  map[string]interface{}
+		want    int
+		wantErr bool
+	}{
+		{name: "default", want: defaultSignatureType},
+		{name: "ECDSA", tunnel: map[string]interface{}{"signaturetype": 1}, want: 1},
+		{name: "string value", tunnel: map[string]interface{}{"signaturetype": "11"}, want: 11},
+		{name: "unsupported", tunnel: map[string]interface{}{"signaturetype": 8}, wantErr: true},
+		{name: "not a number", tunnel: map[string]interface{}{"signaturetype": "ed25519"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tunnelSignatureType(&TunnelConfig{Name: "t", Tunnel: tt.tunnel})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("tunnelSignatureType() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("tunnelSignatureType() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestGenerateDestination_FakeBridge checks the DEST GENERATE request sent to
+// the SAM bridge and that the PUB and PRIV values of its reply become the keys.
+func TestGenerateDestination_FakeBridge(t *testing.T) {
+	addr, got := fakeSAMBridge(t, "DEST REPLY PUB=cHVi PRIV=cHJpdg")
+	keys, err := generateDestination(addr, 11)
+	if err != nil {
+		t.Fatalf("generateDestination() error = %v", err)
+	}
+	if line := <-got; line != "DEST GENERATE SIGNATURE_TYPE=11\n" {
+		t.Errorf("bridge received %q, want DEST GENERATE SIGNATURE_TYPE=11", line)
+	}
+	if keys.Address.Base64() != "cHVi" || keys.Both != "cHJpdg" {
+		t.Errorf("keys = %s / %s, want the PUB and PRIV values", keys.Address.Base64(), keys.Both)
+	}
+
+	addr, _ = fakeSAMBridge(t, "DEST REPLY PUB=cHVi")
+	if _, err := generateDestination(addr, 7); err == nil || !strings.Contains(err.Error(), "no destination") {
+		t.Errorf("generateDestination() without PRIV: got %v, want a no destination error", err)
+	}
+}
+
+// fakePrivateKeys returns a private keys blob as SAM's DESTINATION value
+// holds it: the destination, a 256-byte encryption key, and a 32-byte Ed25519
+// signing key.
+func fakePrivateKeys(t *testing.T) (blob []byte, dest string) {
+	t.Helper()
+	destBytes, err := i2pBase64.DecodeString(fakeDestinationAddress(7))
+	if err != nil {
+		t.Fatalf("setup: %v", err)
+	}
+	blob = append(append([]byte(nil), destBytes...), bytes.Repeat([]byte{0xAB}, 256+32)...)
+	return blob, fakeDestinationAddress(7)
+}
+
+// TestLoadKeyFile checks that the SAM, base64, and binary key file formats all
+// give the same destination and private keys, and that truncated or foreign
+// files are rejected.
+func TestLoadKeyFile(t *testing.T) {
+	blob, dest := fakePrivateKeys(t)
+	both := i2pBase64.EncodeToString(blob)
+
+	tests := []struct {
+		name    string
+		content []byte
+		wantErr bool
+	}{
+		{name: "SAM two-line file", content: []byte(dest + "\n" + both)},
+		{name: "base64 private keys", content: []byte(both + "\n")},
+		{name: "binary private keys", content: blob},
+		{name: "truncated private keys", content: []byte(i2pBase64.EncodeToString(blob[:400])), wantErr: true},
+		{name: "not a key file", content: []byte("hello world\n"), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "site.keys")
+			if err := os.WriteFile(path, tt.content, 0o600); err != nil {
+				t.Fatalf("setup: %v", err)
+			}
+			keys, err := LoadKeyFile(path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LoadKeyFile() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if keys.Address.Base64() != dest {
+				t.Errorf("destination = %s, want %s", keys.Address.Base64(), dest)
+			}
+			if keys.Both != both {
+				t.Errorf("private keys = %s, want %s", keys.Both, both)
+			}
+		})
+	}
+}
+
+// TestSAMTunnelAt_ImportsKeyfile checks that exported private keys named by
+// the keyfile option are used instead of generating a new destination.
+func TestSAMTunnelAt_ImportsKeyfile(t *testing.T) {
+	calls := fakeDestination(t)
+	keystore := t.TempDir()
+	blob, dest := fakePrivateKeys(t)
+	if err := os.WriteFile(filepath.Join(keystore, "exported.txt"), []byte(i2pBase64.EncodeToString(blob)), 0o600); err != nil {
+		t.Fatalf("setup: %v", err)
+	}
+	config := &TunnelConfig{Name: "site", Type: "server", PersistentKey: true,
+		Tunnel: map[string]interface{}{"keyfile": "exported.txt"}}
+
+	keys, _, err := config.SAMTunnelAt(keystore)
+	if err != nil {
+		t.Fatalf("SAMTunnelAt() error = %v", err)
+	}
+	if keys.Address.Base64() != dest {
+		t.Errorf("destination = %s, want the imported one", keys.Address.Base64())
+	}
+	if *calls != 0 {
+		t.Errorf("generated %d destinations, want 0", *calls)
+	}
+	addr, err := config.B32AddressAt(keystore)
+	if err != nil || addr != keys.Address.Base32() {
+		t.Errorf("B32AddressAt() = %q, %v; want %q", addr, err, keys.Address.Base32())
+	}
+}
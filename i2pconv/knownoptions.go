@@ -0,0 +1,360 @@
+package i2pconv
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// knownBooleanI2CPOptions lists I2CP option names (without the "i2cp." prefix)
+// whose values are always booleans. Parsers coerce their values to bool so
+// that "yes", "1", or "on" in one format is emitted as true/false in every
+// other format and the type survives round-trips.
+var knownBooleanI2CPOptions = map[string]bool{
+	"reduceOnIdle":        true,
+	"closeOnIdle":         true,
+	"delayOpen":           true,
+	"newDestOnResume":     true,
+	"dontPublishLeaseSet": true,
+	"encryptLeaseSet":     true,
+	"fastReceive":         true,
+}
+
+// optionRange is the inclusive range of values accepted for a numeric option.
+type optionRange struct {
+	min, max int
+}
+
+// knownCryptoOptionRanges lists the session tag options of ElGamal/AES and
+// their sane ranges. Java I2P defaults to 40 tags sent and a low threshold of
+// 30; values far outside these ranges are almost always typos.
+var knownCryptoOptionRanges = map[string]optionRange{
+	"crypto.tagsToSend":      {min: 1, max: 128},
+	"crypto.lowTagThreshold": {min: 1, max: 128},
+}
+
+// knownConnectionLimitRanges lists the streaming options that limit incoming
+// connections and their sane ranges. 0 turns a limit off, as does -1 for
+// maxConcurrentStreams, which is Java I2P's default; larger values are
+// almost always typos.
+var knownConnectionLimitRanges = map[string]optionRange{
+	"i2p.streaming.maxConcurrentStreams":   {min: -1, max: 10000},
+	"i2p.streaming.maxConnsPerMinute":      {min: 0, max: 10000},
+	"i2p.streaming.maxConnsPerHour":        {min: 0, max: 100000},
+	"i2p.streaming.maxConnsPerDay":         {min: 0, max: 1000000},
+	"i2p.streaming.maxTotalConnsPerMinute": {min: 0, max: 10000},
+	"i2p.streaming.maxTotalConnsPerHour":   {min: 0, max: 100000},
+	"i2p.streaming.maxTotalConnsPerDay":    {min: 0, max: 1000000},
+}
+
+// knownTunnelPoolOptionRanges lists the inbound and outbound tunnel pool
+// options and the ranges Java I2P accepts for them. The same options apply to
+// both directions; i2pd reads them under the same names.
+var knownTunnelPoolOptionRanges = map[string]optionRange{
+	"length":         {min: 0, max: 7},
+	"lengthVariance": {min: -7, max: 7},
+	"quantity":       {min: 1, max: 16},
+	"backupQuantity": {min: 0, max: 16},
+}
+
+// canonicalTunnelPoolKey returns the spelling of a known tunnel pool option
+// whatever its case, so i2pd's inbound.backupquantity becomes backupQuantity.
+// Other keys are returned unchanged.
+func canonicalTunnelPoolKey(key string) string {
+	for known := range knownTunnelPoolOptionRanges {
+		if strings.EqualFold(key, known) {
+			return known
+		}
+	}
+	return key
+}
+
+// canonicalizeTunnelPoolKeys renames the known options of an inbound or
+// outbound map to their canonical spelling. A key already spelled canonically
+// wins over a differently cased duplicate.
+func canonicalizeTunnelPoolKeys(m map[string]interface{}) {
+	for _, k := range sortedKeys(m) {
+		canonical := canonicalTunnelPoolKey(k)
+		if canonical == k {
+			continue
+		}
+		if _, exists := m[canonical]; !exists {
+			m[canonical] = m[k]
+		}
+		delete(m, k)
+	}
+}
+
+// knownSignatureTypes lists the signing key types i2pd accepts for the
+// signaturetype option. Type 8 (Ed25519ph) exists only in Java I2P.
+var knownSignatureTypes = []int{0, 1, 2, 3, 4, 5, 6, 7, 9, 10, 11}
+
+// knownRouterPorts maps the ports of well-known router services to their
+// names. A tunnel listening on one of them likely conflicts with the router.
+var knownRouterPorts = map[int]string{
+	2827: "BOB bridge",
+	4444: "HTTP proxy",
+	4447: "SOCKS proxy",
+	6668: "IRC tunnel",
+	7070: "i2pd web console",
+	7654: "I2CP",
+	7656: "SAM bridge",
+	7657: "Java I2P router console",
+}
+
+// routerPortOwners maps the router ports that belong to a client tunnel to
+// that tunnel's type. The router's own HTTP proxy is an httpclient tunnel on
+// 4444, so such a tunnel on that port is the service rather than a conflict.
+var routerPortOwners = map[int]TunnelType{
+	4444: TunnelTypeHTTPClient,
+	4447: TunnelTypeSOCKS,
+	6668: TunnelTypeIRCClient,
+}
+
+// knownLeaseSetEncTypes lists the encryption types accepted in
+// i2cp.leaseSetEncType: ElGamal (0), ECIES-X25519 (4) and the hybrid
+// post-quantum types (5-7).
+var knownLeaseSetEncTypes = []int{0, 4, 5, 6, 7}
+
+// isKnownLeaseSetEncType reports whether s is one of knownLeaseSetEncTypes.
+func isKnownLeaseSetEncType(s string) bool {
+	n, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil {
+		return false
+	}
+	for _, t := range knownLeaseSetEncTypes {
+		if n == t {
+			return true
+		}
+	}
+	return false
+}
+
+// knownDurationI2CPOptions lists I2CP option names whose values are durations
+// in milliseconds. Parsers also accept human-readable durations such as "15m"
+// or "900s" for them and store the integer milliseconds the routers expect.
+var knownDurationI2CPOptions = map[string]bool{
+	"reduceIdleTime": true,
+	"closeIdleTime":  true,
+}
+
+// knownIntegerI2CPOptions lists I2CP option names, other than the durations,
+// whose values are integers.
+var knownIntegerI2CPOptions = map[string]bool{
+	"reduceQuantity": true,
+}
+
+// normalizeOptionTypes is applied to every parsed TunnelConfig regardless of
+// the source format. It gives list values the element type []string, spells
+// the tunnel pool options canonically, and coerces option values whose type
+// is fixed by a registry, so all three parsers hand the generators identical
+// keys and Go types.
+func normalizeOptionTypes(config *TunnelConfig) {
+	normalizeOptionLists(config)
+	canonicalizeTunnelPoolKeys(config.Inbound)
+	canonicalizeTunnelPoolKeys(config.Outbound)
+	for k, v := range config.I2CP {
+		if knownBooleanI2CPOptions[k] {
+			if b, ok := coerceBoolean(v); ok {
+				config.I2CP[k] = b
+			}
+		}
+		if knownDurationI2CPOptions[k] {
+			if ms, ok := parseDurationMillis(v); ok {
+				config.I2CP[k] = ms
+			}
+		}
+		if knownIntegerI2CPOptions[k] {
+			if n, ok := integerFromBoolean(v); ok {
+				config.I2CP[k] = n
+			}
+		}
+	}
+	for _, m := range []map[string]interface{}{config.Inbound, config.Outbound} {
+		for k, v := range m {
+			if _, known := knownTunnelPoolOptionRanges[k]; known {
+				if n, ok := integerFromBoolean(v); ok {
+					m[k] = n
+				}
+			}
+		}
+	}
+}
+
+// integerFromBoolean returns 1 or 0 for a bool v and true, or false for any
+// other value. The properties parser reads a bare 1 or 0 as a boolean, so an
+// integer option such as inbound.backupQuantity=0 is turned back here.
+func integerFromBoolean(v interface{}) (int, bool) {
+	b, ok := v.(bool)
+	if !ok {
+		return 0, false
+	}
+	if b {
+		return 1, true
+	}
+	return 0, true
+}
+
+// parseDurationMillis converts a human-readable duration string such as
+// "15m", "900s", or "1h30m" to whole milliseconds. The second result is false
+// for any other value, including plain integers, which are already in
+// milliseconds, and durations that are negative or not whole milliseconds.
+func parseDurationMillis(v interface{}) (int, bool) {
+	s, ok := v.(string)
+	if !ok {
+		return 0, false
+	}
+	d, err := time.ParseDuration(strings.TrimSpace(s))
+	if err != nil || d < 0 || d%time.Millisecond != 0 {
+		return 0, false
+	}
+	return int(d / time.Millisecond), true
+}
+
+// formatDurationMillis renders ms in the largest of h, m, s, and ms that
+// divides it exactly, so 900000 becomes "15m" and 5400000 becomes "90m".
+func formatDurationMillis(ms int) string {
+	units := []struct {
+		suffix string
+		size   int
+	}{{"h", 3600000}, {"m", 60000}, {"s", 1000}}
+	for _, u := range units {
+		if ms != 0 && ms%u.size == 0 {
+			return strconv.Itoa(ms/u.size) + u.suffix
+		}
+	}
+	return strconv.Itoa(ms) + "ms"
+}
+
+// humanizeDurations rewrites the known duration options of config from
+// integer milliseconds to human-readable strings for --humanize-durations.
+// Routers expect milliseconds, so the result is meant for reading; parsing it
+// again restores the integers.
+func humanizeDurations(config *TunnelConfig) {
+	for k, v := range config.I2CP {
+		if !knownDurationI2CPOptions[k] {
+			continue
+		}
+		if ms, err := coerceInt(v); err == nil {
+			config.I2CP[k] = formatDurationMillis(ms)
+		}
+	}
+}
+
+// coerceBoolean converts v to a bool when it is a bool, the integers 1/0, or
+// one of the boolean keywords accepted by Java I2P and i2pd (true/false,
+// yes/no, on/off, enabled/disabled). The second result is false when v does
+// not look like a boolean.
+func coerceBoolean(v interface{}) (bool, bool) {
+	switch val := v.(type) {
+	case bool:
+		return val, true
+	case int:
+		if val == 0 || val == 1 {
+			return val == 1, true
+		}
+	case string:
+		switch strings.ToLower(strings.TrimSpace(val)) {
+		case "true", "yes", "1", "on", "enabled":
+			return true, true
+		case "false", "no", "0", "off", "disabled":
+			return false, true
+		}
+	}
+	return false, false
+}
+
+// knownI2CPOptions lists the I2CP option names (without the "i2cp." prefix)
+// understood by Java I2P or i2pd. Strict validation warns about any other
+// key, since a misspelled option is silently ignored by the router.
+var knownI2CPOptions = map[string]bool{
+	"host":                       true,
+	"port":                       true,
+	"SSL":                        true,
+	"tcp.host":                   true,
+	"tcp.port":                   true,
+	"username":                   true,
+	"password":                   true,
+	"gzip":                       true,
+	"fastReceive":                true,
+	"messageReliability":         true,
+	"reduceOnIdle":               true,
+	"reduceIdleTime":             true,
+	"reduceQuantity":             true,
+	"closeOnIdle":                true,
+	"closeIdleTime":              true,
+	"delayOpen":                  true,
+	"newDestOnResume":            true,
+	"dontPublishLeaseSet":        true,
+	"encryptLeaseSet":            true,
+	"enableAccessList":           true,
+	"enableBlackList":            true,
+	"accessList":                 true,
+	"destination.sigType":        true,
+	"leaseSetType":               true,
+	"leaseSetEncType":            true,
+	"leaseSetKey":                true,
+	"leaseSetPrivKey":            true,
+	"leaseSetPrivateKey":         true,
+	"leaseSetSigningPrivateKey":  true,
+	"leaseSetAuthType":           true,
+	"leaseSetBlindedType":        true,
+	"leaseSetSecret":             true,
+	"leaseSetTransientPublicKey": true,
+	"leaseSetOfflineExpiration":  true,
+	"leaseSetOfflineSignature":   true,
+	"lookupTimeout":              true,
+}
+
+// knownI2CPOptionPrefixes lists I2CP option families whose names end in a
+// user-chosen suffix, such as leaseSetClient.dh.0 or leaseSetOption.1.
+var knownI2CPOptionPrefixes = []string{"leaseSetClient.", "leaseSetOption.", "crypto."}
+
+// isKnownI2CPOption reports whether key (without the "i2cp." prefix) is a
+// recognised I2CP option name.
+func isKnownI2CPOption(key string) bool {
+	if knownI2CPOptions[key] {
+		return true
+	}
+	for _, prefix := range knownI2CPOptionPrefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// suggestI2CPOption returns the known I2CP option closest to key, or "" when
+// none is close enough to be a likely typo. Names are compared without case,
+// so a wrongly capitalised name is suggested too.
+func suggestI2CPOption(key string) string {
+	best, bestDist := "", len(key)/3+1
+	for name := range knownI2CPOptions {
+		d := levenshtein(strings.ToLower(key), strings.ToLower(name))
+		if d < bestDist || (d == bestDist && best != "" && name < best) {
+			best, bestDist = name, d
+		}
+	}
+	return best
+}
+
+// levenshtein returns the edit distance between a and b.
+func levenshtein(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
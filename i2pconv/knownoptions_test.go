@@ -0,0 +1,177 @@
+package i2pconv
+
+import (
+	"testing"
+)
+
+// TestIdleBooleansAcrossFormats verifies that reduceOnIdle and closeOnIdle are
+// parsed as bools from every format and survive conversion to every format.
+func TestIdleBooleansAcrossFormats(t *testing.T) {
+	inputs := map[string]string{
+		"properties": "name=t\ntype=httpclient\nlistenPort=4444\n" +
+			"option.i2cp.reduceOnIdle=true\noption.i2cp.closeOnIdle=false\n",
+		"ini": "[t]\ntype = httpclient\nport = 4444\n" +
+			"i2cp.reduceOnIdle = yes\ni2cp.closeOnIdle = 0\n",
+		"yaml": "tunnels:\n  t:\n    type: httpclient\n    port: 4444\n    i2cp:\n" +
+			"      reduceOnIdle: \"true\"\n      closeOnIdle: false\n",
+	}
+
+	for inFormat, input := range inputs {
+		for _, outFormat := range []string{"properties", "ini", "yaml"} {
+			conv := &Converter{}
+			config, err := conv.ParseInput([]byte(input), inFormat)
+			if err != nil {
+				t.Fatalf("%s parse: %v", inFormat, err)
+			}
+			assertIdleBooleans(t, inFormat, config)
+
+			out, err := conv.generateOutput(config, outFormat)
+			if err != nil {
+				t.Fatalf("%s generate: %v", outFormat, err)
+			}
+			reparsed, err := conv.ParseInput(out, outFormat)
+			if err != nil {
+				t.Fatalf("%s reparse: %v", outFormat, err)
+			}
+			assertIdleBooleans(t, inFormat+"->"+outFormat, reparsed)
+		}
+	}
+}
+
+// assertIdleBooleans checks that reduceOnIdle is true and closeOnIdle is false,
+// both stored as bool.
+func assertIdleBooleans(t *testing.T, label string, config *TunnelConfig) {
+	t.Helper()
+	if v, ok := config.I2CP["reduceOnIdle"].(bool); !ok || !v {
+		t.Errorf("%s: reduceOnIdle = %#v, want bool true", label, config.I2CP["reduceOnIdle"])
+	}
+	if v, ok := config.I2CP["closeOnIdle"].(bool); !ok || v {
+		t.Errorf("%s: closeOnIdle = %#v, want bool false", label, config.I2CP["closeOnIdle"])
+	}
+}
+
+// TestCoerceBoolean covers the accepted boolean spellings and rejected values.
+func TestCoerceBoolean(t *testing.T) {
+	tests := []struct {
+		in     interface{}
+		want   bool
+		wantOK bool
+	}{
+		{true, true, true},
+		{"yes", true, true},
+		{"Off", false, true},
+		{1, true, true},
+		{0, false, true},
+		{2, false, false},
+		{"maybe", false, false},
+		{[]string{"a"}, false, false},
+	}
+	for _, tt := range tests {
+		got, ok := coerceBoolean(tt.in)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("coerceBoolean(%#v) = (%v, %v), want (%v, %v)", tt.in, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+// TestDurationOptionsAcrossFormats verifies that human-readable durations are
+// stored as milliseconds by every parser and that humanizeDurations renders
+// them back.
+func TestDurationOptionsAcrossFormats(t *testing.T) {
+	inputs := map[string]string{
+		"properties": "name=t\ntype=httpclient\nlistenPort=4444\n" +
+			"option.i2cp.reduceIdleTime=15m\noption.i2cp.closeIdleTime=1800000\n",
+		"ini": "[t]\ntype = httpclient\nport = 4444\n" +
+			"i2cp.reduceIdleTime = 900s\ni2cp.closeIdleTime = 30m\n",
+		"yaml": "tunnels:\n  t:\n    type: httpclient\n    port: 4444\n    i2cp:\n" +
+			"      reduceIdleTime: 15m\n      closeIdleTime: 1800000\n",
+	}
+
+	for inFormat, input := range inputs {
+		conv := &Converter{}
+		config, err := conv.ParseInput([]byte(input), inFormat)
+		if err != nil {
+			t.Fatalf("%s parse: %v", inFormat, err)
+		}
+		if got := config.I2CP["reduceIdleTime"]; got != 900000 {
+			t.Errorf("%s: reduceIdleTime = %#v, want 900000", inFormat, got)
+		}
+		if got := config.I2CP["closeIdleTime"]; got != 1800000 {
+			t.Errorf("%s: closeIdleTime = %#v, want 1800000", inFormat, got)
+		}
+
+		humanizeDurations(config)
+		if got := config.I2CP["reduceIdleTime"]; got != "15m" {
+			t.Errorf("%s: humanized reduceIdleTime = %#v, want \"15m\"", inFormat, got)
+		}
+		out, err := conv.generateOutput(config, "properties")
+		if err != nil {
+			t.Fatalf("%s generate: %v", inFormat, err)
+		}
+		reparsed, err := conv.ParseInput(out, "properties")
+		if err != nil {
+			t.Fatalf("%s reparse: %v", inFormat, err)
+		}
+		if got := reparsed.I2CP["reduceIdleTime"]; got != 900000 {
+			t.Errorf("%s: reparsed humanized reduceIdleTime = %#v, want 900000", inFormat, got)
+		}
+	}
+}
+
+// TestDurationMillis checks parsing duration strings such as "15m" into
+// milliseconds and formatting milliseconds back in the largest whole unit.
+func TestDurationMillis(t *testing.T) {
+	parse := []struct {
+		in     interface{}
+		want   int
+		wantOK bool
+	}{
+		{"15m", 900000, true},
+		{"900s", 900000, true},
+		{"1h30m", 5400000, true},
+		{" 250ms ", 250, true},
+		{900000, 0, false},
+		{"900000", 0, false},
+		{"-5m", 0, false},
+		{"1.5ms", 0, false},
+		{"soon", 0, false},
+	}
+	for _, tt := range parse {
+		got, ok := parseDurationMillis(tt.in)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("parseDurationMillis(%#v) = %d, %v; want %d, %v", tt.in, got, ok, tt.want, tt.wantOK)
+		}
+	}
+
+	format := map[int]string{900000: "15m", 5400000: "90m", 7200000: "2h", 1500: "1500ms", 2000: "2s", 0: "0ms"}
+	for ms, want := range format {
+		if got := formatDurationMillis(ms); got != want {
+			t.Errorf("formatDurationMillis(%d) = %q, want %q", ms, got, want)
+		}
+	}
+}
+
+// TestIntegerOptionsFromProperties checks that integer options written as a
+// bare 0 or 1 in properties input, which the parser reads as booleans, come
+// back as integers.
+func TestIntegerOptionsFromProperties(t *testing.T) {
+	input := "name=t\ntype=httpclient\nlistenPort=4444\n" +
+		"option.i2cp.reduceQuantity=1\noption.inbound.backupQuantity=0\noption.outbound.length=1\n"
+	config, err := (&Converter{}).ParseInput([]byte(input), "properties")
+	if err != nil {
+		t.Fatalf("ParseInput() error = %v", err)
+	}
+	for _, tt := range []struct {
+		name string
+		got  interface{}
+		want int
+	}{
+		{"i2cp.reduceQuantity", config.I2CP["reduceQuantity"], 1},
+		{"inbound.backupQuantity", config.Inbound["backupQuantity"], 0},
+		{"outbound.length", config.Outbound["length"], 1},
+	} {
+		if tt.got != tt.want {
+			t.Errorf("%s = %#v, want %d", tt.name, tt.got, tt.want)
+		}
+	}
+}
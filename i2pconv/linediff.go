@@ -0,0 +1,141 @@
+package i2pconv
+
+import (
+	"fmt"
+	"strings"
+)
+
+// diffContext is the number of unchanged lines shown around each change in a
+// unified diff, as in diff -u.
+const diffContext = 3
+
+// diffLine is one line of a line diff: kept (' '), removed ('-'), or added ('+').
+type diffLine struct {
+	op   byte
+	text string
+}
+
+// splitDiffLines splits data into lines without their trailing newline.
+func splitDiffLines(data []byte) []string {
+	if len(data) == 0 {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+}
+
+// lineDiff returns the edit script turning a into b, built from a longest
+// common subsequence of their lines. Removals are listed before additions.
+func lineDiff(a, b []string) []diffLine {
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var lines []diffLine
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			lines = append(lines, diffLine{' ', a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			lines = append(lines, diffLine{'-', a[i]})
+			i++
+		default:
+			lines = append(lines, diffLine{'+', b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		lines = append(lines, diffLine{'-', a[i]})
+	}
+	for ; j < len(b); j++ {
+		lines = append(lines, diffLine{'+', b[j]})
+	}
+	return lines
+}
+
+// unifiedDiff returns a unified diff from a (labelled fromName) to b
+// (labelled toName), or "" when their lines are equal. Changes closer than
+// twice diffContext lines share a hunk.
+func unifiedDiff(fromName, toName string, a, b []byte) string {
+	lines := lineDiff(splitDiffLines(a), splitDiffLines(b))
+
+	var sb strings.Builder
+	aLine, bLine := 0, 0 // lines of a and b before lines[i]
+	for i := 0; i < len(lines); {
+		if lines[i].op == ' ' {
+			aLine++
+			bLine++
+			i++
+			continue
+		}
+
+		// Step back over the leading context, then extend the hunk until a
+		// run of unchanged lines is long enough to end it.
+		start := max(0, i-diffContext)
+		aStart, bStart := aLine-(i-start), bLine-(i-start)
+		end := i
+		for end < len(lines) {
+			if lines[end].op != ' ' {
+				end++
+				continue
+			}
+			run := end
+			for run < len(lines) && lines[run].op == ' ' {
+				run++
+			}
+			if run == len(lines) || run-end > 2*diffContext {
+				end = min(end+diffContext, len(lines))
+				break
+			}
+			end = run
+		}
+
+		aCount, bCount := 0, 0
+		for _, l := range lines[start:end] {
+			if l.op != '+' {
+				aCount++
+			}
+			if l.op != '-' {
+				bCount++
+			}
+		}
+		if sb.Len() == 0 {
+			fmt.Fprintf(&sb, "--- %s\n+++ %s\n", fromName, toName)
+		}
+		fmt.Fprintf(&sb, "@@ -%s +%s @@\n", hunkRange(aStart, aCount), hunkRange(bStart, bCount))
+		for _, l := range lines[start:end] {
+			sb.WriteByte(l.op)
+			sb.WriteString(l.text)
+			sb.WriteByte('\n')
+		}
+
+		aLine, bLine = aStart+aCount, bStart+bCount
+		i = end
+	}
+	return sb.String()
+}
+
+// hunkRange formats a hunk's line range as diff -u does: the 1-based first
+// line and the line count, where an empty range names the line before it.
+func hunkRange(start, count int) string {
+	switch count {
+	case 0:
+		return fmt.Sprintf("%d,0", start)
+	case 1:
+		return fmt.Sprintf("%d", start+1)
+	default:
+		return fmt.Sprintf("%d,%d", start+1, count)
+	}
+}
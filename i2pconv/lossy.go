@@ -0,0 +1,61 @@
+package i2pconv
+
+import "fmt"
+
+// The checks in this file report settings a conversion to outFormat cannot
+// carry. Each returns nil when nothing is lost; otherwise the error is printed
+// as a warning and the conversion goes ahead without the setting.
+
+// checkCryptoType returns a warning when an i2pd cryptotype cannot be carried
+// into Java I2P properties output unchanged.
+func checkCryptoType(config *TunnelConfig, outFormat string) error {
+	if outFormat != "properties" {
+		return nil
+	}
+	m, ok := mapCryptoType(config)
+	if !ok || m.lossy == "" {
+		return nil
+	}
+	return fmt.Errorf("tunnel '%s': %s", config.Name, m.lossy)
+}
+
+// checkSharedClient returns a warning when a Java I2P shared client tunnel is
+// converted to i2pd. i2pd has no sharedClient option, so the INI generator
+// drops it; client tunnels in i2pd only share a destination when they use the
+// same keys file.
+func checkSharedClient(config *TunnelConfig, outFormat string) error {
+	if outFormat != "ini" {
+		return nil
+	}
+	shared, ok := config.Tunnel["sharedClient"].(bool)
+	if !ok || !shared {
+		return nil
+	}
+	return fmt.Errorf("tunnel '%s' is a shared client, which i2pd does not support; sharedClient was dropped (point the tunnels at the same keys file to share a destination)", config.Name)
+}
+
+// checkStyle returns a warning when a SAM session style cannot be carried
+// into outFormat. Java I2P properties have no style; i2pd INI only records
+// DATAGRAM on a client tunnel, as the udptunnel type. A style that matches the
+// one the tunnel type implies is not lost.
+func checkStyle(config *TunnelConfig, outFormat string) error {
+	style := styleValue(config)
+	if outFormat == "yaml" || style == "" {
+		return nil
+	}
+	if outFormat == "ini" && style == "DATAGRAM" && NormalizeTypeName(config.Type) == string(TunnelTypeClient) {
+		return nil
+	}
+	return fmt.Errorf("tunnel '%s' uses the SAM style %s, which %s output cannot express; the style was dropped", config.Name, style, outFormat)
+}
+
+// checkEnabledState returns a warning when a disabled go-i2p tunnel is
+// converted to Java I2P or i2pd. Neither has a per-tunnel enabled state, so
+// the state is dropped and the tunnel will run once the router loads it.
+// startOnLoad is a different setting: it only controls autostart.
+func checkEnabledState(config *TunnelConfig, outFormat string) error {
+	if outFormat == "yaml" || config.Enabled == nil || *config.Enabled {
+		return nil
+	}
+	return fmt.Errorf("tunnel '%s' is disabled, which %s output cannot express; the enabled state was dropped and the tunnel will be active", config.Name, outFormat)
+}
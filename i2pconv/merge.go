@@ -0,0 +1,72 @@
+package i2pconv
+
+// Merge lays overlay over t. Every scalar field overlay sets replaces the one
+// in t; zero values in overlay, such as an empty Target or a Port of 0, leave
+// t's value in place. The I2CP, Tunnel, Inbound, and Outbound maps and the
+// Unknown map are merged key by key with overlay winning, so keys only t sets
+// are kept. Overlay's comments replace t's when it has any. Values are copied,
+// so overlay can be modified afterwards without affecting t. A nil overlay
+// leaves t unchanged.
+func (t *TunnelConfig) Merge(overlay *TunnelConfig) {
+	if overlay == nil {
+		return
+	}
+	if overlay.Name != "" {
+		t.Name = overlay.Name
+	}
+	if overlay.Type != "" {
+		t.Type = overlay.Type
+	}
+	if overlay.Interface != "" {
+		t.Interface = overlay.Interface
+	}
+	if overlay.Port != 0 {
+		t.Port = overlay.Port
+	}
+	if overlay.Target != "" {
+		t.Target = overlay.Target
+	}
+	if overlay.PersistentKey {
+		t.PersistentKey = true
+	}
+	if overlay.Description != "" {
+		t.Description = overlay.Description
+	}
+	if overlay.Enabled != nil {
+		enabled := *overlay.Enabled
+		t.Enabled = &enabled
+	}
+	if overlay.Style != "" {
+		t.Style = overlay.Style
+	}
+	t.I2CP = mergeOptionMap(t.I2CP, overlay.I2CP)
+	t.Tunnel = mergeOptionMap(t.Tunnel, overlay.Tunnel)
+	t.Inbound = mergeOptionMap(t.Inbound, overlay.Inbound)
+	t.Outbound = mergeOptionMap(t.Outbound, overlay.Outbound)
+	if len(overlay.Unknown) > 0 {
+		if t.Unknown == nil {
+			t.Unknown = make(map[string]string, len(overlay.Unknown))
+		}
+		for k, v := range overlay.Unknown {
+			t.Unknown[k] = v
+		}
+	}
+	if overlay.Comments != nil {
+		t.Comments = overlay.Comments.clone()
+	}
+}
+
+// mergeOptionMap copies every key of overlay into base, allocating base when
+// needed, and returns it.
+func mergeOptionMap(base, overlay map[string]interface{}) map[string]interface{} {
+	if len(overlay) == 0 {
+		return base
+	}
+	if base == nil {
+		base = make(map[string]interface{}, len(overlay))
+	}
+	for k, v := range overlay {
+		base[k] = cloneOptionValue(v)
+	}
+	return base
+}
@@ -0,0 +1,69 @@
+package i2pconv
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestTunnelConfig_Merge checks that Merge overlays the set fields and map keys
+// of another config, copies what it takes, and leaves the config alone for an
+// empty or nil overlay.
+func TestTunnelConfig_Merge(t *testing.T) {
+	enabled := false
+	base := &TunnelConfig{
+		Name:          "base",
+		Type:          "httpclient",
+		Interface:     "127.0.0.1",
+		Port:          4444,
+		PersistentKey: true,
+		Description:   "shared defaults",
+		I2CP:          map[string]interface{}{"reduceIdleTime": 900000, "reduceOnIdle": true},
+		Inbound:       map[string]interface{}{"length": 3, "quantity": 2},
+		Unknown:       map[string]string{"foo": "bar"},
+	}
+	overlay := &TunnelConfig{
+		Name:     "web",
+		Port:     8080,
+		Enabled:  &enabled,
+		I2CP:     map[string]interface{}{"reduceOnIdle": false, "closeOnIdle": true},
+		Inbound:  map[string]interface{}{"length": 2},
+		Outbound: map[string]interface{}{"length": 2},
+		Tunnel:   map[string]interface{}{"proxyList": []interface{}{"a.i2p"}},
+	}
+
+	base.Merge(overlay)
+
+	want := &TunnelConfig{
+		Name:          "web",
+		Type:          "httpclient",
+		Interface:     "127.0.0.1",
+		Port:          8080,
+		PersistentKey: true,
+		Description:   "shared defaults",
+		Enabled:       &enabled,
+		I2CP:          map[string]interface{}{"reduceIdleTime": 900000, "reduceOnIdle": false, "closeOnIdle": true},
+		Tunnel:        map[string]interface{}{"proxyList": []interface{}{"a.i2p"}},
+		Inbound:       map[string]interface{}{"length": 2, "quantity": 2},
+		Outbound:      map[string]interface{}{"length": 2},
+		Unknown:       map[string]string{"foo": "bar"},
+	}
+	if !reflect.DeepEqual(base, want) {
+		t.Errorf("Merge() = %+v, want %+v", base, want)
+	}
+
+	overlay.Tunnel["proxyList"].([]interface{})[0] = "b.i2p"
+	*overlay.Enabled = true
+	if got := base.Tunnel["proxyList"].([]interface{})[0]; got != "a.i2p" {
+		t.Errorf("mutating the overlay changed the merged proxyList to %v", got)
+	}
+	if *base.Enabled {
+		t.Error("mutating the overlay changed the merged Enabled")
+	}
+
+	before := base.Clone()
+	base.Merge(&TunnelConfig{})
+	base.Merge(nil)
+	if !reflect.DeepEqual(base, before) {
+		t.Errorf("merging an empty overlay changed the config: %+v", base)
+	}
+}
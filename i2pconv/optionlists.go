@@ -0,0 +1,127 @@
+package i2pconv
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// orderSensitiveOptions lists option keys whose comma-separated values are
+// preference lists: the first entry is preferred and later entries are
+// fallbacks. For example leaseSetEncType=4,0 means "prefer type 4, fall back
+// to type 0". These lists must keep their original order through every
+// conversion and are never touched by sortOptionLists.
+var orderSensitiveOptions = map[string]bool{
+	"leaseSetEncType": true,
+}
+
+// isOrderSensitiveOption reports whether the list value stored under key must
+// keep its original order. Keys may be given with or without an "i2cp." prefix.
+func isOrderSensitiveOption(key string) bool {
+	if orderSensitiveOptions[key] {
+		return true
+	}
+	if len(key) > 5 && key[:5] == "i2cp." {
+		return orderSensitiveOptions[key[5:]]
+	}
+	return false
+}
+
+// normalizeOptionLists gives every list-valued option in the I2CP, Tunnel,
+// Inbound, and Outbound maps the element type []string, whatever the parser
+// produced: YAML sequences ([]interface{}, possibly of ints) and
+// comma-separated YAML strings become the same trimmed []string the
+// properties and INI parsers return for "4,0".
+func normalizeOptionLists(config *TunnelConfig) {
+	for _, m := range []map[string]interface{}{config.I2CP, config.Tunnel, config.Inbound, config.Outbound} {
+		for k, v := range m {
+			if list, ok := stringListValue(v); ok {
+				m[k] = list
+			}
+		}
+	}
+}
+
+// stringListValue returns v as a []string with trimmed elements when it is a
+// list or a string containing a comma; the second result is false otherwise.
+func stringListValue(v interface{}) ([]string, bool) {
+	var list []string
+	switch val := v.(type) {
+	case []string:
+		list = append(list, val...)
+	case []interface{}:
+		for _, e := range val {
+			list = append(list, formatPropertyValue(e))
+		}
+	case string:
+		if !strings.Contains(val, ",") {
+			return nil, false
+		}
+		list = strings.Split(val, ",")
+	default:
+		return nil, false
+	}
+	for i, e := range list {
+		list[i] = strings.TrimSpace(e)
+	}
+	return list, true
+}
+
+// sortOptionLists sorts the elements of every list-valued option in the I2CP,
+// Tunnel, Inbound, and Outbound maps so that set-like lists (access lists,
+// explicit peers, proxy lists) produce stable output. Order-sensitive lists
+// such as leaseSetEncType are left exactly as parsed.
+func sortOptionLists(config *TunnelConfig) {
+	for _, m := range []map[string]interface{}{config.I2CP, config.Tunnel, config.Inbound, config.Outbound} {
+		for k, v := range m {
+			if isOrderSensitiveOption(k) {
+				continue
+			}
+			m[k] = sortedListValue(v)
+		}
+	}
+}
+
+// sortedListValue returns a sorted copy of v when it is a []string or
+// []interface{}; any other value is returned unchanged.
+func sortedListValue(v interface{}) interface{} {
+	switch list := v.(type) {
+	case []string:
+		sorted := append([]string(nil), list...)
+		sort.Strings(sorted)
+		return sorted
+	case []interface{}:
+		sorted := append([]interface{}(nil), list...)
+		sort.SliceStable(sorted, func(i, j int) bool {
+			return fmt.Sprint(sorted[i]) < fmt.Sprint(sorted[j])
+		})
+		return sorted
+	default:
+		return v
+	}
+}
+
+// joinOptionLists replaces every list-valued option in the I2CP, Tunnel,
+// Inbound, and Outbound maps with its elements joined by bare commas, the
+// form all three formats read back as the same list.
+func joinOptionLists(config *TunnelConfig) {
+	for _, m := range []map[string]interface{}{config.I2CP, config.Tunnel, config.Inbound, config.Outbound} {
+		for k, v := range m {
+			switch v.(type) {
+			case []string, []interface{}:
+				m[k] = formatPropertyValue(v)
+			}
+		}
+	}
+}
+
+// sortedKeys returns the keys of an option map or the Unknown map in sorted
+// order. The generators iterate maps through it so output is reproducible.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,164 @@
+package i2pconv
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// TestIsOrderSensitiveOption checks the registry lookup with and without the
+// i2cp. prefix.
+func TestIsOrderSensitiveOption(t *testing.T) {
+	tests := []struct {
+		key  string
+		want bool
+	}{
+		{"leaseSetEncType", true},
+		{"i2cp.leaseSetEncType", true},
+		{"accessList", false},
+		{"explicitPeers", false},
+		{"i2cp.", false},
+	}
+	for _, tt := range tests {
+		if got := isOrderSensitiveOption(tt.key); got != tt.want {
+			t.Errorf("isOrderSensitiveOption(%q) = %v, want %v", tt.key, got, tt.want)
+		}
+	}
+}
+
+// TestSortOptionLists_PreservesLeaseSetEncType verifies that sorting leaves
+// leaseSetEncType untouched while sorting other list values.
+func TestSortOptionLists_PreservesLeaseSetEncType(t *testing.T) {
+	config := &TunnelConfig{
+		I2CP: map[string]interface{}{
+			"leaseSetEncType": []string{"4", "0"},
+		},
+		Tunnel: map[string]interface{}{
+			"accessList":    []string{"zzz.b32.i2p", "aaa.b32.i2p"},
+			"explicitPeers": []interface{}{"peerB", "peerA"},
+		},
+	}
+
+	sortOptionLists(config)
+
+	if got := config.I2CP["leaseSetEncType"]; !reflect.DeepEqual(got, []string{"4", "0"}) {
+		t.Errorf("leaseSetEncType reordered to %v, want [4 0]", got)
+	}
+	if got := config.Tunnel["accessList"]; !reflect.DeepEqual(got, []string{"aaa.b32.i2p", "zzz.b32.i2p"}) {
+		t.Errorf("accessList = %v, want sorted", got)
+	}
+	if got := config.Tunnel["explicitPeers"]; !reflect.DeepEqual(got, []interface{}{"peerA", "peerB"}) {
+		t.Errorf("explicitPeers = %v, want sorted", got)
+	}
+}
+
+// TestLeaseSetEncTypeOrderAcrossFormats converts leaseSetEncType=4,0 through
+// every format pair, with and without list sorting, and asserts that 4,0
+// never becomes 0,4.
+func TestLeaseSetEncTypeOrderAcrossFormats(t *testing.T) {
+	inputs := map[string]string{
+		"properties": "name=t\ntype=httpclient\nlistenPort=4444\noption.i2cp.leaseSetEncType=4,0\n",
+		"ini":        "[t]\ntype = httpclient\nport = 4444\ni2cp.leaseSetEncType = 4,0\n",
+		"yaml":       "tunnels:\n  t:\n    type: httpclient\n    port: 4444\n    i2cp:\n      leaseSetEncType: \"4,0\"\n",
+	}
+	formats := []string{"properties", "ini", "yaml"}
+
+	for _, sorted := range []bool{false, true} {
+		for inFormat, input := range inputs {
+			for _, outFormat := range formats {
+				conv := &Converter{}
+				config, err := conv.ParseInput([]byte(input), inFormat)
+				if err != nil {
+					t.Fatalf("%s parse: %v", inFormat, err)
+				}
+				if sorted {
+					sortOptionLists(config)
+				}
+				out, err := conv.generateOutput(config, outFormat)
+				if err != nil {
+					t.Fatalf("%s generate: %v", outFormat, err)
+				}
+				reparsed, err := conv.ParseInput(out, outFormat)
+				if err != nil {
+					t.Fatalf("%s reparse: %v", outFormat, err)
+				}
+				got := strings.ReplaceAll(formatPropertyValue(reparsed.I2CP["leaseSetEncType"]), " ", "")
+				if got != "4,0" {
+					t.Errorf("%s -> %s (sorted=%v): leaseSetEncType = %q, want \"4,0\"", inFormat, outFormat, sorted, got)
+				}
+			}
+		}
+	}
+}
+
+// TestGeneratorsDeterministic generates the same config repeatedly and checks
+// that every generator and SAMTunnel produce identical output each time.
+func TestGeneratorsDeterministic(t *testing.T) {
+	config := &TunnelConfig{
+		Name:      "proxy",
+		Type:      "httpclient",
+		Interface: "127.0.0.1",
+		Port:      4444,
+		I2CP:      map[string]interface{}{"reduceOnIdle": true, "closeIdleTime": 1800000, "leaseSetEncType": "4,0", "delayOpen": false},
+		Tunnel:    map[string]interface{}{"gzip": true, "accesslist": "a,b", "startOnLoad": true, "sharedClient": false},
+		Inbound:   map[string]interface{}{"length": 3, "quantity": 2, "backupQuantity": 1, "lengthVariance": 0},
+		Outbound:  map[string]interface{}{"length": 3, "quantity": 2, "backupQuantity": 1, "lengthVariance": 0},
+	}
+	conv := &Converter{}
+
+	for _, format := range []string{"ini", "properties", "yaml"} {
+		first, err := conv.generateOutput(config, format)
+		if err != nil {
+			t.Fatalf("generateOutput(%s) error = %v", format, err)
+		}
+		for i := 0; i < 20; i++ {
+			again, _ := conv.generateOutput(config, format)
+			if string(again) != string(first) {
+				t.Fatalf("%s output differs between runs:\n%s\n---\n%s", format, first, again)
+			}
+		}
+	}
+
+	_, firstOpts, err := config.SAMTunnel()
+	if err != nil {
+		t.Fatalf("SAMTunnel() error = %v", err)
+	}
+	for i := 0; i < 20; i++ {
+		_, opts, _ := config.SAMTunnel()
+		if !reflect.DeepEqual(opts, firstOpts) {
+			t.Fatalf("SAM options differ between runs:\n%v\n%v", firstOpts, opts)
+		}
+	}
+	if !sort.StringsAreSorted(firstOpts) {
+		t.Errorf("SAM options should be sorted, got %v", firstOpts)
+	}
+}
+
+// TestOptionListsSameTypeAcrossParsers checks that every parser returns the
+// same []string for the same comma list, whatever its spelling in the source.
+func TestOptionListsSameTypeAcrossParsers(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		input  string
+	}{
+		{"properties", "properties", "name=t\ntype=client\nlistenPort=8080\ntargetDestination=example.i2p\noption.i2cp.leaseSetEncType=4,0\n"},
+		{"properties with spaces", "properties", "name=t\ntype=client\nlistenPort=8080\ntargetDestination=example.i2p\noption.i2cp.leaseSetEncType=4, 0\n"},
+		{"ini", "ini", "[t]\ntype = client\nport = 8080\ndestination = example.i2p\ni2cp.leaseSetEncType = 4, 0\n"},
+		{"yaml string", "yaml", "tunnels:\n  t:\n    type: client\n    port: 8080\n    target: example.i2p\n    i2cp:\n      leaseSetEncType: 4,0\n"},
+		{"yaml sequence", "yaml", "tunnels:\n  t:\n    type: client\n    port: 8080\n    target: example.i2p\n    i2cp:\n      leaseSetEncType: [4, 0]\n"},
+	}
+	want := []string{"4", "0"}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config, err := (&Converter{}).ParseInput([]byte(tt.input), tt.format)
+			if err != nil {
+				t.Fatalf("ParseInput() error = %v", err)
+			}
+			if got := config.I2CP["leaseSetEncType"]; !reflect.DeepEqual(got, want) {
+				t.Errorf("leaseSetEncType = %#v, want %#v", got, want)
+			}
+		})
+	}
+}
@@ -3,10 +3,11 @@ package i2pconv
 import (
 	"fmt"
 	"strconv"
+	"strings"
 )
 
 // Options returns the tunnel configuration as a flat string-to-string map.
-// Nested maps (I2CP, Tunnel, Inbound, Outbound) are flattened with dot-separated
+// Nested maps (I2CP, Tunnel, Inbound, Outbound, Unknown) are flattened with dot-separated
 // prefix keys (e.g., "I2CP.foo", "Tunnel.bar").
 func (t *TunnelConfig) Options() map[string]string {
 	options := make(map[string]string)
@@ -27,6 +28,12 @@ func (t *TunnelConfig) Options() map[string]string {
 	if t.Description != "" {
 		options["Description"] = t.Description
 	}
+	if t.Enabled != nil {
+		options["Enabled"] = strconv.FormatBool(*t.Enabled)
+	}
+	if t.Style != "" {
+		options["Style"] = t.Style
+	}
 	for k, v := range t.I2CP {
 		options["I2CP."+k] = fmt.Sprintf("%v", v)
 	}
@@ -39,6 +46,9 @@ func (t *TunnelConfig) Options() map[string]string {
 	for k, v := range t.Outbound {
 		options["Outbound."+k] = fmt.Sprintf("%v", v)
 	}
+	for k, v := range t.Unknown {
+		options["Unknown."+k] = v
+	}
 	return options
 }
 
@@ -68,6 +78,14 @@ func (t *TunnelConfig) SetOptions(options map[string]string) error {
 			t.PersistentKey = persistentKey
 		case "Description":
 			t.Description = v
+		case "Enabled":
+			enabled, err := strconv.ParseBool(v)
+			if err != nil {
+				return fmt.Errorf("invalid enabled: %v", err)
+			}
+			t.Enabled = &enabled
+		case "Style":
+			t.Style = v
 		default:
 			if len(k) > 5 && k[:5] == "I2CP." {
 				if t.I2CP == nil {
@@ -97,7 +115,157 @@ func (t *TunnelConfig) SetOptions(options map[string]string) error {
 				t.Outbound[k[9:]] = v
 				continue
 			}
+			if len(k) > 8 && k[:8] == "Unknown." {
+				if t.Unknown == nil {
+					t.Unknown = make(map[string]string)
+				}
+				t.Unknown[k[8:]] = v
+				continue
+			}
 		}
 	}
 	return nil
 }
+
+// optionMap returns a pointer to the option map addressed by section, which is
+// one of the YAML keys "i2cp", "options", "inbound", or "outbound".
+func (t *TunnelConfig) optionMap(section string) (*map[string]interface{}, bool) {
+	switch section {
+	case "i2cp":
+		return &t.I2CP, true
+	case "options":
+		return &t.Tunnel, true
+	case "inbound":
+		return &t.Inbound, true
+	case "outbound":
+		return &t.Outbound, true
+	}
+	return nil, false
+}
+
+// Get returns the value at path, using the same names as the YAML format.
+// Top-level fields are addressed by name ("port", "target"), map entries by
+// section and key ("i2cp.leaseSetEncType", "inbound.length"). Everything after
+// the first dot is the map key, so keys that contain dots themselves work too.
+// The second result is false when path names no field or the key is unset.
+func (t *TunnelConfig) Get(path string) (interface{}, bool) {
+	switch path {
+	case "name":
+		return t.Name, true
+	case "type":
+		return t.Type, true
+	case "interface":
+		return t.Interface, true
+	case "port":
+		return t.Port, true
+	case "target":
+		return t.Target, true
+	case "persistentKey":
+		return t.PersistentKey, true
+	case "description":
+		return t.Description, true
+	case "enabled":
+		return enabledValue(t.Enabled), true
+	case "style":
+		return t.Style, true
+	}
+	section, key, ok := strings.Cut(path, ".")
+	if !ok || key == "" {
+		return nil, false
+	}
+	m, ok := t.optionMap(section)
+	if !ok {
+		return nil, false
+	}
+	v, ok := (*m)[key]
+	return v, ok
+}
+
+// Set stores value at path (see Get for the path syntax), coercing it to the
+// field's type: "port" accepts integers and numeric strings, "persistentKey"
+// and "enabled" accept the boolean forms understood by the parsers, and string
+// fields accept any value. Map entries are typed the way the parsers type them, so "3"
+// becomes 3 and known boolean I2CP options become bool.
+func (t *TunnelConfig) Set(path string, value interface{}) error {
+	switch path {
+	case "name", "type", "interface", "target", "description", "style":
+		s := fmt.Sprint(value)
+		switch path {
+		case "name":
+			t.Name = s
+		case "type":
+			t.Type = s
+		case "interface":
+			t.Interface = s
+		case "target":
+			t.Target = s
+		case "description":
+			t.Description = s
+		case "style":
+			t.Style = s
+		}
+		return nil
+	case "port":
+		port, err := coerceInt(value)
+		if err != nil {
+			return fmt.Errorf("invalid port: %w", err)
+		}
+		t.Port = port
+		return nil
+	case "persistentKey":
+		b, ok := coerceBoolean(value)
+		if !ok {
+			return fmt.Errorf("invalid persistentKey: %v is not a boolean", value)
+		}
+		t.PersistentKey = b
+		return nil
+	case "enabled":
+		b, ok := coerceBoolean(value)
+		if !ok {
+			return fmt.Errorf("invalid enabled: %v is not a boolean", value)
+		}
+		t.Enabled = &b
+		return nil
+	}
+
+	section, key, ok := strings.Cut(path, ".")
+	if !ok || key == "" {
+		return fmt.Errorf("unknown config path '%s'", path)
+	}
+	m, ok := t.optionMap(section)
+	if !ok {
+		return fmt.Errorf("unknown config section '%s' in path '%s'", section, path)
+	}
+	if s, isString := value.(string); isString {
+		value = parseValue(s)
+	}
+	if section == "i2cp" && knownBooleanI2CPOptions[key] {
+		b, ok := coerceBoolean(value)
+		if !ok {
+			return fmt.Errorf("invalid %s: %v is not a boolean", path, value)
+		}
+		value = b
+	}
+	if *m == nil {
+		*m = make(map[string]interface{})
+	}
+	(*m)[key] = value
+	return nil
+}
+
+// coerceInt converts integer values, integral floats, and numeric strings to int.
+func coerceInt(v interface{}) (int, error) {
+	switch val := v.(type) {
+	case int:
+		return val, nil
+	case int64:
+		return int(val), nil
+	case float64:
+		if val == float64(int(val)) {
+			return int(val), nil
+		}
+	case string:
+		return strconv.Atoi(strings.TrimSpace(val))
+	}
+	return 0, fmt.Errorf("%v is not an integer", v)
+}
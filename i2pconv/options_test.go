@@ -0,0 +1,81 @@
+package i2pconv
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestTunnelConfig_GetSet checks that Set converts each value to the type its
+// field or option holds, and that Get reads it back.
+func TestTunnelConfig_GetSet(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		value   interface{}
+		want    interface{}
+		wantErr bool
+	}{
+		{name: "port from int", path: "port", value: 8080, want: 8080},
+		{name: "port from string", path: "port", value: "4444", want: 4444},
+		{name: "port from float", path: "port", value: 7656.0, want: 7656},
+		{name: "port rejects text", path: "port", value: "http", wantErr: true},
+		{name: "target", path: "target", value: "example.i2p", want: "example.i2p"},
+		{name: "persistentKey from string", path: "persistentKey", value: "yes", want: true},
+		{name: "enabled from string", path: "enabled", value: "no", want: false},
+		{name: "enabled rejects text", path: "enabled", value: "sometimes", wantErr: true},
+		{name: "style", path: "style", value: "DATAGRAM", want: "DATAGRAM"},
+		{name: "inbound length from string", path: "inbound.length", value: "2", want: 2},
+		{name: "outbound quantity", path: "outbound.quantity", value: 4, want: 4},
+		{name: "i2cp list", path: "i2cp.leaseSetEncType", value: "4,0", want: []string{"4", "0"}},
+		{name: "known boolean i2cp", path: "i2cp.reduceOnIdle", value: "on", want: true},
+		{name: "known boolean rejects text", path: "i2cp.reduceOnIdle", value: "sometimes", wantErr: true},
+		{name: "dotted map key", path: "i2cp.outbound.nickname", value: "web", want: "web"},
+		{name: "tunnel options", path: "options.startOnLoad", value: "false", want: false},
+		{name: "unknown section", path: "bogus.length", value: 1, wantErr: true},
+		{name: "unknown field", path: "bogus", value: 1, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &TunnelConfig{Name: "test", Type: "client"}
+			err := config.Set(tt.path, tt.value)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Set(%q, %v) expected error", tt.path, tt.value)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Set(%q, %v) error = %v", tt.path, tt.value, err)
+			}
+			got, ok := config.Get(tt.path)
+			if !ok {
+				t.Fatalf("Get(%q) not found after Set", tt.path)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Get(%q) = %#v, want %#v", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestTunnelConfig_GetMissing checks that Get reports unset fields, options,
+// and unknown paths as not found.
+func TestTunnelConfig_GetMissing(t *testing.T) {
+	config := &TunnelConfig{
+		Port:    4444,
+		Inbound: map[string]interface{}{"length": 3},
+	}
+
+	if v, ok := config.Get("port"); !ok || v != 4444 {
+		t.Errorf("Get(port) = %v, %v", v, ok)
+	}
+	if v, ok := config.Get("inbound.length"); !ok || v != 3 {
+		t.Errorf("Get(inbound.length) = %v, %v", v, ok)
+	}
+	for _, path := range []string{"inbound.quantity", "outbound.length", "i2cp.", "nosuch", "nosuch.key"} {
+		if _, ok := config.Get(path); ok {
+			t.Errorf("Get(%q) should report not found", path)
+		}
+	}
+}
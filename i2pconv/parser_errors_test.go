@@ -78,6 +78,7 @@ func TestINIParserErrors(t *testing.T) {
 		expectError   bool
 		expectLineNum int
 		errorContains string
+		expectColumn  int
 	}{
 		{
 			name: "valid ini",
@@ -94,6 +95,7 @@ type = client`,
 			expectError:   true,
 			expectLineNum: 1,
 			errorContains: "unclosed section bracket",
+			expectColumn:  13,
 		},
 		{
 			name: "empty section name",
@@ -102,6 +104,7 @@ type = client`,
 			expectError:   true,
 			expectLineNum: 1,
 			errorContains: "empty section name",
+			expectColumn:  1,
 		},
 		{
 			name: "missing equals sign",
@@ -110,6 +113,15 @@ type client`,
 			expectError:   true,
 			expectLineNum: 2,
 			errorContains: "expected key=value pair",
+			expectColumn:  5,
+		},
+		{
+			name:          "indented missing equals sign",
+			input:         "[test]\n  inbound.length",
+			expectError:   true,
+			expectLineNum: 2,
+			errorContains: "expected key=value pair",
+			expectColumn:  17,
 		},
 		{
 			name: "empty key",
@@ -118,6 +130,23 @@ type client`,
 			expectError:   true,
 			expectLineNum: 2,
 			errorContains: "empty key name",
+			expectColumn:  1,
+		},
+		{
+			name:          "empty key after indentation",
+			input:         "[test]\n\t  = value",
+			expectError:   true,
+			expectLineNum: 2,
+			errorContains: "empty key name",
+			expectColumn:  4,
+		},
+		{
+			name:          "indented unclosed section",
+			input:         "  [test  \ntype = client",
+			expectError:   true,
+			expectLineNum: 1,
+			errorContains: "unclosed section bracket",
+			expectColumn:  8,
 		},
 		// Note: INI parser uses SplitN(line, "=", 2) which treats everything after first = as value
 		// This is valid behavior: key="type", value="client = extra"
@@ -148,6 +177,10 @@ type client`,
 					t.Errorf("ParseError.Line = %d, want %d", parseErr.Line, tt.expectLineNum)
 				}
 
+				if parseErr.Column != tt.expectColumn {
+					t.Errorf("ParseError.Column = %d, want %d", parseErr.Column, tt.expectColumn)
+				}
+
 				if parseErr.Format != "ini" {
 					t.Errorf("ParseError.Format = %q, want %q", parseErr.Format, "ini")
 				}
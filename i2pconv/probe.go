@@ -0,0 +1,103 @@
+package i2pconv
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"time"
+)
+
+// probeTimeout bounds the whole probe conversation. Creating a SAM session
+// waits for the router to build the first tunnels, which can take a while on
+// a freshly started router.
+const probeTimeout = 2 * time.Minute
+
+// samSessionCreateCommand builds the SAM SESSION CREATE line for config using
+// the options from SAMTunnel. The session always uses a TRANSIENT destination
+// so probing never creates or reads key files; it is signed with the tunnel's
+// signaturetype, as its persistent keys would be.
+func samSessionCreateCommand(config *TunnelConfig, id string) (string, error) {
+	transient := *config
+	transient.PersistentKey = false
+	_, opts, err := transient.SAMTunnel()
+	if err != nil {
+		return "", err
+	}
+	sigType, err := tunnelSignatureType(config)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "SESSION CREATE STYLE=%s ID=%s DESTINATION=TRANSIENT SIGNATURE_TYPE=%d",
+		config.SAMStyle(), id, sigType)
+	for _, opt := range opts {
+		if strings.ContainsAny(opt, " \t\r\n") {
+			return "", fmt.Errorf("option '%s' contains whitespace and cannot be sent over SAM", opt)
+		}
+		b.WriteString(" ")
+		b.WriteString(opt)
+	}
+	b.WriteString("\n")
+	return b.String(), nil
+}
+
+// ProbeRouter asks the SAM bridge at addr (host:port) to create a session with
+// the tunnel's options, then closes the connection, which tears the session
+// down again. A nil error means the router accepted the configuration.
+func ProbeRouter(config *TunnelConfig, addr string) error {
+	id := fmt.Sprintf("i2pconv-probe-%d-%d", os.Getpid(), time.Now().UnixNano())
+	cmd, err := samSessionCreateCommand(config, id)
+	if err != nil {
+		return err
+	}
+
+	conn, r, err := dialSAM(addr, probeTimeout)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if _, err := samRequest(conn, r, cmd); err != nil {
+		return fmt.Errorf("router at %s rejected tunnel '%s': %w", addr, config.Name, err)
+	}
+	return nil
+}
+
+// dialSAM connects to the SAM bridge at addr and completes the HELLO
+// handshake. The whole conversation on the returned connection must finish
+// within timeout.
+func dialSAM(addr string, timeout time.Duration) (net.Conn, *bufio.Reader, error) {
+	conn, err := net.DialTimeout("tcp", addr, 10*time.Second)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to connect to SAM bridge at %s: %w", addr, err)
+	}
+	if err := conn.SetDeadline(time.Now().Add(timeout)); err != nil {
+		conn.Close()
+		return nil, nil, fmt.Errorf("failed to set deadline: %w", err)
+	}
+	r := bufio.NewReader(conn)
+	if _, err := samRequest(conn, r, "HELLO VERSION MIN=3.1 MAX=3.3\n"); err != nil {
+		conn.Close()
+		return nil, nil, fmt.Errorf("SAM handshake with %s failed: %w", addr, err)
+	}
+	return conn, r, nil
+}
+
+// samRequest writes one SAM command and reads the reply line, returning an
+// error unless the reply carries RESULT=OK.
+func samRequest(conn net.Conn, r *bufio.Reader, cmd string) (string, error) {
+	if _, err := conn.Write([]byte(cmd)); err != nil {
+		return "", err
+	}
+	reply, err := r.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	reply = strings.TrimSpace(reply)
+	if !strings.Contains(reply, "RESULT=OK") {
+		return reply, fmt.Errorf("%s", reply)
+	}
+	return reply, nil
+}
@@ -0,0 +1,193 @@
+package i2pconv
+
+import (
+	"bufio"
+	"net"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestSamSessionCreateCommand checks the SESSION CREATE line built for a
+// tunnel: its options, SAM style, and signature type, always with a transient
+// destination.
+func TestSamSessionCreateCommand(t *testing.T) {
+	tests := []struct {
+		name      string
+		config    *TunnelConfig
+		want      []string
+		wantErr   bool
+		wantStyle string
+	}{
+		{
+			name: "stream client",
+			config: &TunnelConfig{
+				Name:     "web",
+				Type:     "httpclient",
+				I2CP:     map[string]interface{}{"reduceOnIdle": true},
+				Inbound:  map[string]interface{}{"length": 2},
+				Outbound: map[string]interface{}{"quantity": 3},
+			},
+			want:      []string{"i2cp.reduceOnIdle=true", "inbound.length=2", "outbound.quantity=3", "i2cp.leaseSetEncType=4,0", "SIGNATURE_TYPE=7"},
+			wantStyle: "STYLE=STREAM",
+		},
+		{
+			name:      "tunnel signature type",
+			config:    &TunnelConfig{Name: "signed", Type: "server", Tunnel: map[string]interface{}{"signaturetype": 11}},
+			want:      []string{"SIGNATURE_TYPE=11"},
+			wantStyle: "STYLE=STREAM",
+		},
+		{
+			name:    "unsupported signature type",
+			config:  &TunnelConfig{Name: "bad", Type: "server", Tunnel: map[string]interface{}{"signaturetype": 99}},
+			wantErr: true,
+		},
+		{
+			name:      "streamr uses datagrams",
+			config:    &TunnelConfig{Name: "media", Type: "streamrclient"},
+			wantStyle: "STYLE=DATAGRAM",
+		},
+		{
+			name: "persistent key stays transient",
+			config: &TunnelConfig{
+				Name:          "keep",
+				Type:          "server",
+				PersistentKey: true,
+			},
+			want:      []string{"DESTINATION=TRANSIENT"},
+			wantStyle: "STYLE=STREAM",
+		},
+		{
+			name: "whitespace in value",
+			config: &TunnelConfig{
+				Name: "bad",
+				Type: "client",
+				I2CP: map[string]interface{}{"outbound.nickname": "two words"},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd, err := samSessionCreateCommand(tt.config, "probe-1")
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got command %q", cmd)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !strings.HasPrefix(cmd, "SESSION CREATE ") || !strings.HasSuffix(cmd, "\n") {
+				t.Errorf("malformed command %q", cmd)
+			}
+			fields := strings.Fields(cmd)
+			for _, want := range append(tt.want, tt.wantStyle, "ID=probe-1") {
+				if !containsString(fields, want) {
+					t.Errorf("command %q missing %q", cmd, want)
+				}
+			}
+		})
+	}
+}
+
+// fakeSAMBridge answers HELLO with OK and SESSION CREATE with sessionReply.
+// It returns the listener address and a channel carrying the SESSION line.
+func fakeSAMBridge(t *testing.T, sessionReply string) (string, <-chan string) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	got := make(chan string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		if _, err := r.ReadString('\n'); err != nil {
+			return
+		}
+		conn.Write([]byte("HELLO REPLY RESULT=OK VERSION=3.1\n"))
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		got <- line
+		conn.Write([]byte(sessionReply + "\n"))
+	}()
+	return ln.Addr().String(), got
+}
+
+// TestProbeRouter_FakeBridge runs ProbeRouter against a fake SAM bridge that
+// accepts or rejects the session.
+func TestProbeRouter_FakeBridge(t *testing.T) {
+	config := &TunnelConfig{Name: "web", Type: "httpclient", Port: 4444}
+
+	t.Run("accepted", func(t *testing.T) {
+		addr, got := fakeSAMBridge(t, "SESSION STATUS RESULT=OK DESTINATION=abc")
+		if err := ProbeRouter(config, addr); err != nil {
+			t.Fatalf("ProbeRouter() error = %v", err)
+		}
+		select {
+		case line := <-got:
+			if !strings.HasPrefix(line, "SESSION CREATE STYLE=STREAM") {
+				t.Errorf("unexpected session line %q", line)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("bridge never received SESSION CREATE")
+		}
+	})
+
+	t.Run("rejected", func(t *testing.T) {
+		addr, _ := fakeSAMBridge(t, `SESSION STATUS RESULT=I2P_ERROR MESSAGE="bad option"`)
+		err := ProbeRouter(config, addr)
+		if err == nil || !strings.Contains(err.Error(), "I2P_ERROR") {
+			t.Fatalf("expected I2P_ERROR, got %v", err)
+		}
+	})
+}
+
+// TestProbeRouter_Integration creates a real session on a local router. It is
+// skipped unless a SAM bridge answers at I2P_SAM_ADDR (default 127.0.0.1:7656).
+func TestProbeRouter_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping router integration test in short mode")
+	}
+	addr := os.Getenv("I2P_SAM_ADDR")
+	if addr == "" {
+		addr = "127.0.0.1:7656"
+	}
+	conn, err := net.DialTimeout("tcp", addr, time.Second)
+	if err != nil {
+		t.Skipf("no SAM bridge at %s: %v", addr, err)
+	}
+	conn.Close()
+
+	config := &TunnelConfig{
+		Name:     "probe-integration",
+		Type:     "client",
+		Target:   "example.i2p",
+		Inbound:  map[string]interface{}{"length": 1, "quantity": 1},
+		Outbound: map[string]interface{}{"length": 1, "quantity": 1},
+	}
+	if err := ProbeRouter(config, addr); err != nil {
+		t.Fatalf("ProbeRouter() error = %v", err)
+	}
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
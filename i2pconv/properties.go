@@ -2,6 +2,7 @@ package i2pconv
 
 import (
 	"fmt"
+	"net"
 	"strconv"
 	"strings"
 
@@ -20,6 +21,16 @@ func (c *Converter) parseJavaProperties(input []byte) (*TunnelConfig, error) {
 		return nil, c.enhancePropertiesError(input, err)
 	}
 
+	// The properties library keeps the last of several identical keys without
+	// reporting it; in strict mode a duplicate is treated as a parse error.
+	if c.strict {
+		if dups := findDuplicatePropertyKeys(input); len(dups) > 0 {
+			d := dups[0]
+			return nil, newParseError(input, d.Line, 0, "properties",
+				fmt.Sprintf("duplicate key '%s' (first defined on line %d)", d.Key, d.FirstLine))
+		}
+	}
+
 	config := &TunnelConfig{
 		I2CP:     make(map[string]interface{}),
 		Tunnel:   make(map[string]interface{}),
@@ -32,9 +43,99 @@ func (c *Converter) parseJavaProperties(input []byte) (*TunnelConfig, error) {
 		c.parsePropertyKey(k, p.GetString(k, ""), config)
 	}
 
+	config.Comments = collectPropertiesComments(input)
+	normalizeOptionTypes(config)
 	return config, nil
 }
 
+// collectPropertiesComments returns the '#' and '!' comment lines of a
+// properties file, attached to the key that follows them. Properties values
+// have no inline comments: a '#' after the separator is part of the value.
+func collectPropertiesComments(input []byte) *Comments {
+	cc := newCommentCollector("properties")
+	continuation := false
+	for _, line := range strings.Split(string(input), "\n") {
+		line = strings.TrimRight(line, "\r")
+		if continuation {
+			continuation = endsWithContinuation(line)
+			continue
+		}
+		trimmed := strings.TrimLeft(line, " \t\f")
+		switch {
+		case trimmed == "":
+			cc.blank()
+		case trimmed[0] == '#' || trimmed[0] == '!':
+			if comment := strings.TrimRight(trimmed, " \t\f"); !propertiesSectionHeaders[comment] {
+				cc.comment(comment)
+			}
+		default:
+			continuation = endsWithContinuation(line)
+			cc.key(stripTunnelIndex(propertyKey(trimmed)), "")
+		}
+	}
+	return cc.result()
+}
+
+// duplicateKey records a key that is defined more than once in an input file.
+type duplicateKey struct {
+	Key       string
+	FirstLine int // Line of the first definition (1-indexed)
+	Line      int // Line of the repeated definition (1-indexed)
+}
+
+// findDuplicatePropertyKeys scans properties input line by line and returns
+// every key that is defined more than once, in input order. Comment lines,
+// blank lines, and backslash continuation lines are skipped; the key ends at
+// the first unescaped '=', ':', or whitespace, as in java.util.Properties.
+func findDuplicatePropertyKeys(input []byte) []duplicateKey {
+	var dups []duplicateKey
+	seen := make(map[string]int)
+	continuation := false
+	for i, line := range strings.Split(string(input), "\n") {
+		line = strings.TrimRight(line, "\r")
+		if continuation {
+			continuation = endsWithContinuation(line)
+			continue
+		}
+		trimmed := strings.TrimLeft(line, " \t\f")
+		if trimmed == "" || trimmed[0] == '#' || trimmed[0] == '!' {
+			continue
+		}
+		continuation = endsWithContinuation(line)
+		key := propertyKey(trimmed)
+		if first, ok := seen[key]; ok {
+			dups = append(dups, duplicateKey{Key: key, FirstLine: first, Line: i + 1})
+			continue
+		}
+		seen[key] = i + 1
+	}
+	return dups
+}
+
+// endsWithContinuation reports whether line ends with an odd number of
+// backslashes, meaning the logical line continues on the next physical line.
+func endsWithContinuation(line string) bool {
+	n := 0
+	for i := len(line) - 1; i >= 0 && line[i] == '\\'; i-- {
+		n++
+	}
+	return n%2 == 1
+}
+
+// propertyKey returns the key portion of a properties line with leading
+// whitespace already removed.
+func propertyKey(line string) string {
+	for i := 0; i < len(line); i++ {
+		switch line[i] {
+		case '\\':
+			i++ // skip the escaped character
+		case '=', ':', ' ', '\t', '\f':
+			return line[:i]
+		}
+	}
+	return line
+}
+
 // enhancePropertiesError wraps properties parsing errors with line context.
 // It attempts to extract line numbers from the error message and provide context.
 func (c *Converter) enhancePropertiesError(input []byte, err error) error {
@@ -86,10 +187,7 @@ func parseFlatPropertyKey(k, s string, config *TunnelConfig) bool {
 	case "description":
 		config.Description = s
 	case "proxyList":
-		if config.Tunnel == nil {
-			config.Tunnel = make(map[string]interface{})
-		}
-		config.Tunnel["proxyList"] = parseValue(s)
+		setProxyList(config, s)
 	case "sharedClient":
 		if config.Tunnel == nil {
 			config.Tunnel = make(map[string]interface{})
@@ -128,6 +226,39 @@ func parseFlatPropertyKey(k, s string, config *TunnelConfig) bool {
 	return true
 }
 
+// setProxyList stores the outproxy list s in Tunnel["proxyList"]. The flat
+// proxyList key and option.i2ptunnel.proxyList name the same option. When both
+// appear neither wins: the entries are merged in file order and duplicates are
+// dropped. Java I2P picks an outproxy from the list at random, so the merged
+// order does not change behaviour.
+func setProxyList(config *TunnelConfig, s string) {
+	if config.Tunnel == nil {
+		config.Tunnel = make(map[string]interface{})
+	}
+	existing, ok := config.Tunnel["proxyList"]
+	if !ok {
+		config.Tunnel["proxyList"] = parseValue(s)
+		return
+	}
+
+	var merged []string
+	seen := make(map[string]bool)
+	for _, list := range []string{formatPropertyValue(existing), s} {
+		for _, proxy := range strings.Split(list, ",") {
+			proxy = strings.TrimSpace(proxy)
+			if proxy != "" && !seen[proxy] {
+				seen[proxy] = true
+				merged = append(merged, proxy)
+			}
+		}
+	}
+	if len(merged) == 1 {
+		config.Tunnel["proxyList"] = merged[0]
+	} else {
+		config.Tunnel["proxyList"] = merged
+	}
+}
+
 // parsePrefixedPropertyKey handles Java I2P option.* prefix patterns and updates
 // config accordingly. It returns true when the key matched a known prefix, false
 // otherwise.
@@ -138,11 +269,19 @@ func parsePrefixedPropertyKey(k, s string, config *TunnelConfig) bool {
 			config.I2CP = make(map[string]interface{})
 		}
 		config.I2CP[strings.TrimPrefix(k, "option.i2cp.")] = parseValue(s)
+	case k == "option.i2ptunnel.proxyList":
+		setProxyList(config, s)
 	case strings.HasPrefix(k, "option.i2ptunnel."):
 		if config.Tunnel == nil {
 			config.Tunnel = make(map[string]interface{})
 		}
 		config.Tunnel[strings.TrimPrefix(k, "option.i2ptunnel.")] = parseValue(s)
+	case strings.HasPrefix(k, "option.i2p.streaming."):
+		// Streaming options keep their full name, as in i2pd
+		if config.Tunnel == nil {
+			config.Tunnel = make(map[string]interface{})
+		}
+		config.Tunnel[strings.TrimPrefix(k, "option.")] = parseValue(s)
 	case strings.HasPrefix(k, "option.inbound."):
 		if config.Inbound == nil {
 			config.Inbound = make(map[string]interface{})
@@ -173,10 +312,11 @@ func parsePrefixedPropertyKey(k, s string, config *TunnelConfig) bool {
 //
 // Numbered tunnel patterns:
 //   - tunnel.N.property (e.g., tunnel.0.name, tunnel.1.type, tunnel.2.interface)
+//   - tunnel.N.option.* -> routed like the option prefixes below
 //
 // Option prefixes:
 //   - option.i2cp.* -> stored in I2CP map
-//   - option.i2ptunnel.* -> stored in Tunnel map
+//   - option.i2ptunnel.* -> stored in Tunnel map (proxyList is merged with the flat key)
 //   - option.inbound.* -> stored in Inbound map
 //   - option.outbound.* -> stored in Outbound map
 //   - option.persistentClientKey -> sets PersistentKey field
@@ -251,7 +391,13 @@ func (c *Converter) parseNumberedTunnelProperty(property, value string, config *
 		if config.Description == "" {
 			config.Description = value
 		}
+	case "proxyList":
+		setProxyList(config, value)
 	default:
+		// Route tunnel.N.option.* like the flat option.* keys
+		if parsePrefixedPropertyKey(property, value, config) {
+			return
+		}
 		// Store other numbered tunnel properties in the Tunnel map
 		if config.Tunnel == nil {
 			config.Tunnel = make(map[string]interface{})
@@ -260,6 +406,26 @@ func (c *Converter) parseNumberedTunnelProperty(property, value string, config *
 	}
 }
 
+// propertiesServerTarget is the inverse of iniServerAddress: it splits a
+// server tunnel's "host:port" target, as i2pd writes it, into the targetHost
+// and targetPort Java I2P keeps apart. The third result is true when the
+// target was split; a target without a numeric port, or one whose port is already set
+// in the Tunnel map, is returned unchanged as the host.
+func propertiesServerTarget(config *TunnelConfig) (string, int, bool) {
+	if _, ok := config.Tunnel["targetPort"]; ok {
+		return config.Target, 0, false
+	}
+	host, portStr, err := net.SplitHostPort(config.Target)
+	if err != nil {
+		return config.Target, 0, false
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil {
+		return config.Target, 0, false
+	}
+	return host, port, true
+}
+
 // Helper to parse property values with type conversion
 func parseValue(s string) interface{} {
 	// Try boolean
@@ -281,6 +447,13 @@ func parseValue(s string) interface{} {
 	return s
 }
 
+// i2cpRouterKeys maps the I2CP keys holding the router connection address to
+// the flat property names Java I2P reads them from.
+var i2cpRouterKeys = map[string]string{
+	"host": "i2cpHost",
+	"port": "i2cpPort",
+}
+
 // generateJavaProperties generates a Java properties file content based on the provided TunnelConfig.
 // It constructs the properties as a byte slice.
 //
@@ -303,7 +476,7 @@ func (c *Converter) generateJavaProperties(config *TunnelConfig) ([]byte, error)
 		sb.WriteString(fmt.Sprintf("name=%s\n", config.Name))
 	}
 	if config.Type != "" {
-		sb.WriteString(fmt.Sprintf("type=%s\n", config.Type))
+		sb.WriteString(fmt.Sprintf("type=%s\n", c.typeName(config.Type, "properties")))
 	}
 	if config.Interface != "" {
 		sb.WriteString(fmt.Sprintf("interface=%s\n", config.Interface))
@@ -312,7 +485,15 @@ func (c *Converter) generateJavaProperties(config *TunnelConfig) ([]byte, error)
 		sb.WriteString(fmt.Sprintf("listenPort=%d\n", config.Port))
 	}
 	if config.Target != "" {
-		sb.WriteString(fmt.Sprintf("targetDestination=%s\n", config.Target))
+		if TunnelType(config.Type).IsServer() {
+			host, port, split := propertiesServerTarget(config)
+			sb.WriteString(fmt.Sprintf("targetHost=%s\n", host))
+			if split {
+				sb.WriteString(fmt.Sprintf("targetPort=%d\n", port))
+			}
+		} else {
+			sb.WriteString(fmt.Sprintf("targetDestination=%s\n", config.Target))
+		}
 	}
 	if config.PersistentKey {
 		sb.WriteString("option.persistentClientKey=true\n")
@@ -321,30 +502,103 @@ func (c *Converter) generateJavaProperties(config *TunnelConfig) ([]byte, error)
 		sb.WriteString(fmt.Sprintf("description=%s\n", config.Description))
 	}
 
-	for k, v := range config.I2CP {
-		sb.WriteString(fmt.Sprintf("option.i2cp.%s=%s\n", k, formatPropertyValue(v)))
+	i2cp := config.I2CP
+	if m, ok := mapCryptoType(config); ok && m.mapped != "" {
+		// i2pd's cryptotype becomes the lease set encryption type
+		i2cp = cloneOptionMap(config.I2CP)
+		if i2cp == nil {
+			i2cp = make(map[string]interface{})
+		}
+		i2cp["leaseSetEncType"] = m.mapped
 	}
+	var lines []string
+	for _, k := range sortedKeys(i2cp) {
+		v := i2cp[k]
+		// The router address is a flat top-level key in Java I2P, not an option
+		if flat, ok := i2cpRouterKeys[k]; ok {
+			lines = append(lines, fmt.Sprintf("%s=%s", flat, formatPropertyValue(v)))
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("option.i2cp.%s=%s", k, formatPropertyValue(v)))
+	}
+	writePropertiesSection(&sb, propertiesSectionI2CP, lines)
 
-	for k, v := range config.Tunnel {
+	lines = nil
+	for _, k := range sortedKeys(config.Tunnel) {
+		v := config.Tunnel[k]
 		// Handle special flat properties that should not have option.i2ptunnel prefix
 		switch k {
+		case "cryptotype":
+			// Mapped to i2cp.leaseSetEncType above
+			continue
 		case "proxyList", "sharedClient", "startOnLoad", "accessList", "spoofedHost", "targetPort":
-			sb.WriteString(fmt.Sprintf("%s=%s\n", k, formatPropertyValue(v)))
+			lines = append(lines, fmt.Sprintf("%s=%s", k, formatPropertyValue(v)))
 		default:
+			if strings.HasPrefix(k, "i2p.streaming.") {
+				lines = append(lines, fmt.Sprintf("option.%s=%s", k, formatPropertyValue(v)))
+				continue
+			}
 			// Other tunnel options use the option.i2ptunnel prefix
-			sb.WriteString(fmt.Sprintf("option.i2ptunnel.%s=%s\n", k, formatPropertyValue(v)))
+			lines = append(lines, fmt.Sprintf("option.i2ptunnel.%s=%s", k, formatPropertyValue(v)))
 		}
 	}
+	writePropertiesSection(&sb, propertiesSectionTunnel, lines)
 
-	for k, v := range config.Inbound {
-		sb.WriteString(fmt.Sprintf("option.inbound.%s=%s\n", k, formatPropertyValue(v)))
+	lines = nil
+	for _, k := range sortedKeys(config.Inbound) {
+		lines = append(lines, fmt.Sprintf("option.inbound.%s=%s", k, formatPropertyValue(config.Inbound[k])))
 	}
+	writePropertiesSection(&sb, propertiesSectionInbound, lines)
 
-	for k, v := range config.Outbound {
-		sb.WriteString(fmt.Sprintf("option.outbound.%s=%s\n", k, formatPropertyValue(v)))
+	lines = nil
+	for _, k := range sortedKeys(config.Outbound) {
+		lines = append(lines, fmt.Sprintf("option.outbound.%s=%s", k, formatPropertyValue(config.Outbound[k])))
 	}
+	writePropertiesSection(&sb, propertiesSectionOutbound, lines)
+
+	lines = nil
+	for _, k := range sortedKeys(config.Unknown) {
+		lines = append(lines, fmt.Sprintf("%s=%s", k, config.Unknown[k]))
+	}
+	writePropertiesSection(&sb, propertiesSectionOther, lines)
+
+	return applyComments([]byte(sb.String()), "properties", config.Comments, propertiesLineKey), nil
+}
 
-	return []byte(sb.String()), nil
+// Section headers generateJavaProperties writes above each group of options,
+// after a blank line, in the style of the example files.
+const (
+	propertiesSectionI2CP     = "# I2CP options"
+	propertiesSectionTunnel   = "# Tunnel options"
+	propertiesSectionInbound  = "# Inbound tunnel options"
+	propertiesSectionOutbound = "# Outbound tunnel options"
+	propertiesSectionOther    = "# Other settings"
+)
+
+// propertiesSectionHeaders holds the generated section headers. The comment
+// collector skips them, so a properties round trip does not write them twice.
+var propertiesSectionHeaders = map[string]bool{
+	propertiesSectionI2CP:     true,
+	propertiesSectionTunnel:   true,
+	propertiesSectionInbound:  true,
+	propertiesSectionOutbound: true,
+	propertiesSectionOther:    true,
+}
+
+// writePropertiesSection writes lines to sb as a group under header,
+// separated from what comes before by a blank line. Nothing is written for an
+// empty group.
+func writePropertiesSection(sb *strings.Builder, header string, lines []string) {
+	if len(lines) == 0 {
+		return
+	}
+	if sb.Len() > 0 {
+		sb.WriteString("\n")
+	}
+	sb.WriteString(header + "\n")
+	for _, line := range lines {
+		sb.WriteString(line + "\n")
+	}
 }
 
 // formatPropertyValue formats a property value for output
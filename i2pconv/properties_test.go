@@ -1,8 +1,10 @@
 package i2pconv
 
 import (
+	"errors"
 	"fmt"
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -737,3 +739,383 @@ func TestCountPropertiesTunnels(t *testing.T) {
 		})
 	}
 }
+
+// TestFindDuplicatePropertyKeys covers duplicate detection, including lines
+// that must be ignored (comments and continuation lines).
+func TestFindDuplicatePropertyKeys(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []duplicateKey
+	}{
+		{
+			name:  "no duplicates",
+			input: "name=a\ntype=client\n",
+		},
+		{
+			name:  "duplicate type",
+			input: "name=a\ntype=client\nlistenPort=1\ntype=server\n",
+			want:  []duplicateKey{{Key: "type", FirstLine: 2, Line: 4}},
+		},
+		{
+			name:  "colon and space separators",
+			input: "type: client\ntype server\n",
+			want:  []duplicateKey{{Key: "type", FirstLine: 1, Line: 2}},
+		},
+		{
+			name:  "comments and continuations ignored",
+			input: "# type=x\\\ntype=client\ndescription=a \\\ntype=notakey\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := findDuplicatePropertyKeys([]byte(tt.input))
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("dup %d = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+// TestParseJavaProperties_DuplicateKeyStrict verifies that strict mode reports
+// a duplicate key as a ParseError while non-strict mode keeps the last value.
+func TestParseJavaProperties_DuplicateKeyStrict(t *testing.T) {
+	input := "name=dup\ntype=httpclient\nlistenPort=4444\ntype=server\n"
+
+	config, err := (&Converter{}).parseJavaProperties([]byte(input))
+	if err != nil {
+		t.Fatalf("non-strict parse should succeed, got: %v", err)
+	}
+	if config.Type != "server" {
+		t.Errorf("non-strict parse should keep the last value, got %q", config.Type)
+	}
+
+	_, err = (&Converter{strict: true}).parseJavaProperties([]byte(input))
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) {
+		t.Fatalf("strict parse should return *ParseError, got: %v", err)
+	}
+	if parseErr.Line != 4 || !strings.Contains(parseErr.Message, "duplicate key 'type' (first defined on line 2)") {
+		t.Errorf("unexpected ParseError: line %d, message %q", parseErr.Line, parseErr.Message)
+	}
+}
+
+// TestPropertiesCommentsRoundTrip verifies that comments above keys, including
+// numbered tunnel.N.* keys, are written back by generateJavaProperties.
+func TestPropertiesCommentsRoundTrip(t *testing.T) {
+	input := "# header\n\n# the tunnel name\ntunnel.0.name=web\ntunnel.0.type=httpclient\n! listen here\ntunnel.0.listenPort=4444\n"
+	conv := &Converter{}
+	config, err := conv.ParseInput([]byte(input), "properties")
+	if err != nil {
+		t.Fatalf("ParseInput() error = %v", err)
+	}
+	out, err := conv.generateJavaProperties(config)
+	if err != nil {
+		t.Fatalf("generateJavaProperties() error = %v", err)
+	}
+	got := string(out)
+	for _, want := range []string{"# header\n\n", "# the tunnel name\nname=web\n", "! listen here\nlistenPort=4444\n"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("regenerated properties missing %q:\n%s", want, got)
+		}
+	}
+}
+
+// TestI2CPRouterAddressRoundTrip verifies that i2cpHost/i2cpPort are written
+// back as the flat keys Java I2P reads, not as option.i2cp.* options.
+func TestI2CPRouterAddressRoundTrip(t *testing.T) {
+	input := "name=web\ntype=httpclient\nlistenPort=4444\ni2cpHost=10.0.0.2\ni2cpPort=7654\noption.i2cp.reduceOnIdle=true\n"
+	conv := &Converter{}
+
+	out, err := conv.Convert([]byte(input), "properties", "properties")
+	if err != nil {
+		t.Fatalf("Convert() error = %v", err)
+	}
+	got := string(out)
+	for _, want := range []string{"i2cpHost=10.0.0.2\n", "i2cpPort=7654\n", "option.i2cp.reduceOnIdle=true\n"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output missing %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "option.i2cp.host") || strings.Contains(got, "option.i2cp.port") {
+		t.Errorf("router address must not be emitted as an I2CP option:\n%s", got)
+	}
+
+	reparsed, err := conv.ParseInput(out, "properties")
+	if err != nil {
+		t.Fatalf("re-parse error = %v", err)
+	}
+	if reparsed.I2CP["host"] != "10.0.0.2" || reparsed.I2CP["port"] != 7654 {
+		t.Errorf("round trip lost the router address: %v", reparsed.I2CP)
+	}
+
+	// Through the other formats and back, the same flat keys reappear
+	for _, via := range []string{"ini", "yaml"} {
+		mid, err := conv.Convert([]byte(input), "properties", via)
+		if err != nil {
+			t.Fatalf("Convert(properties, %s) error = %v", via, err)
+		}
+		back, err := conv.Convert(mid, via, "properties")
+		if err != nil {
+			t.Fatalf("Convert(%s, properties) error = %v", via, err)
+		}
+		for _, want := range []string{"i2cpHost=10.0.0.2\n", "i2cpPort=7654\n"} {
+			if !strings.Contains(string(back), want) {
+				t.Errorf("round trip via %s lost %q:\n%s", via, want, back)
+			}
+		}
+	}
+}
+
+// TestNumberedTunnelOptionRouting checks that the option.* keys of a numbered
+// tunnel.N entry land in the I2CP, pool, and tunnel maps as they do for a
+// single tunnel.
+func TestNumberedTunnelOptionRouting(t *testing.T) {
+	input := []byte(`tunnel.0.name=web
+tunnel.0.type=httpclient
+tunnel.0.listenPort=4444
+tunnel.0.option.i2cp.reduceIdleTime=900000
+tunnel.0.option.i2cp.leaseSetEncType=4,0
+tunnel.0.option.inbound.length=2
+tunnel.0.option.outbound.quantity=3
+tunnel.0.option.i2ptunnel.httpclient.allowInternalSSL=true
+tunnel.0.option.persistentClientKey=true
+`)
+	conv := &Converter{}
+	config, err := conv.ParseInput(input, "properties")
+	if err != nil {
+		t.Fatalf("ParseInput() error = %v", err)
+	}
+
+	if got := config.I2CP["reduceIdleTime"]; got != 900000 {
+		t.Errorf("I2CP[reduceIdleTime] = %#v, want 900000", got)
+	}
+	if got := config.I2CP["leaseSetEncType"]; !reflect.DeepEqual(got, []string{"4", "0"}) {
+		t.Errorf("I2CP[leaseSetEncType] = %#v, want [4 0]", got)
+	}
+	if got := config.Inbound["length"]; got != 2 {
+		t.Errorf("Inbound[length] = %#v, want 2", got)
+	}
+	if got := config.Outbound["quantity"]; got != 3 {
+		t.Errorf("Outbound[quantity] = %#v, want 3", got)
+	}
+	if got := config.Tunnel["httpclient.allowInternalSSL"]; got != true {
+		t.Errorf("Tunnel[httpclient.allowInternalSSL] = %#v, want true", got)
+	}
+	if !config.PersistentKey {
+		t.Error("PersistentKey should be set from tunnel.0.option.persistentClientKey")
+	}
+	for k := range config.Tunnel {
+		if strings.HasPrefix(k, "option.") {
+			t.Errorf("Tunnel map should not contain raw key %q", k)
+		}
+	}
+}
+
+// TestProxyListFlatAndPrefixed checks that proxyList entries given both flat
+// and under option.i2ptunnel are merged in file order.
+func TestProxyListFlatAndPrefixed(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  interface{}
+	}{
+		{
+			name:  "flat only",
+			input: "proxyList=a.i2p,b.i2p\n",
+			want:  []string{"a.i2p", "b.i2p"},
+		},
+		{
+			name:  "prefixed only",
+			input: "option.i2ptunnel.proxyList=a.i2p\n",
+			want:  "a.i2p",
+		},
+		{
+			name:  "both merged in file order",
+			input: "proxyList=a.i2p,b.i2p\noption.i2ptunnel.proxyList=c.i2p\n",
+			want:  []string{"a.i2p", "b.i2p", "c.i2p"},
+		},
+		{
+			name:  "prefixed first",
+			input: "option.i2ptunnel.proxyList=c.i2p\nproxyList=a.i2p\n",
+			want:  []string{"c.i2p", "a.i2p"},
+		},
+		{
+			name:  "duplicates dropped",
+			input: "proxyList=a.i2p, b.i2p\noption.i2ptunnel.proxyList=b.i2p,a.i2p\n",
+			want:  []string{"a.i2p", "b.i2p"},
+		},
+		{
+			name:  "same single proxy",
+			input: "proxyList=a.i2p\noption.i2ptunnel.proxyList=a.i2p\n",
+			want:  "a.i2p",
+		},
+		{
+			name:  "numbered tunnel",
+			input: "tunnel.0.proxyList=a.i2p\ntunnel.0.option.i2ptunnel.proxyList=b.i2p\n",
+			want:  []string{"a.i2p", "b.i2p"},
+		},
+		{
+			name:  "numbered tunnel prefixed first",
+			input: "tunnel.0.option.i2ptunnel.proxyList=b.i2p\ntunnel.0.proxyList=a.i2p\n",
+			want:  []string{"b.i2p", "a.i2p"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := "name=proxy\ntype=httpclient\nlistenPort=4444\n" + tt.input
+			config, err := (&Converter{}).ParseInput([]byte(input), "properties")
+			if err != nil {
+				t.Fatalf("ParseInput() error = %v", err)
+			}
+			if got := config.Tunnel["proxyList"]; !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Tunnel[proxyList] = %#v, want %#v", got, tt.want)
+			}
+			if len(config.Unknown) != 0 {
+				t.Errorf("Unknown = %v, want none", config.Unknown)
+			}
+		})
+	}
+}
+
+// TestStreamingOptionsRoundTrip checks that i2p.streaming.* options keep their
+// names through Java I2P and i2pd output.
+func TestStreamingOptionsRoundTrip(t *testing.T) {
+	input := "name=web\ntype=server\ntargetHost=127.0.0.1\ntargetPort=8080\noption.i2p.streaming.maxConnsPerMinute=5\n"
+	conv := &Converter{}
+	config, err := conv.ParseInput([]byte(input), "properties")
+	if err != nil {
+		t.Fatalf("ParseInput() error = %v", err)
+	}
+	if got := config.Tunnel["i2p.streaming.maxConnsPerMinute"]; got != 5 {
+		t.Fatalf("Tunnel[i2p.streaming.maxConnsPerMinute] = %#v, want 5", got)
+	}
+	for _, tt := range []struct{ format, line string }{
+		{"properties", "option.i2p.streaming.maxConnsPerMinute=5\n"},
+		{"ini", "i2p.streaming.maxConnsPerMinute = 5\n"},
+	} {
+		out, err := conv.generateOutput(config, tt.format)
+		if err != nil {
+			t.Fatalf("generateOutput(%s) error = %v", tt.format, err)
+		}
+		if !strings.Contains(string(out), tt.line) {
+			t.Errorf("%s output lacks %q:\n%s", tt.format, tt.line, out)
+		}
+		reparsed, err := conv.ParseInput(out, tt.format)
+		if err != nil {
+			t.Fatalf("ParseInput(%s) error = %v", tt.format, err)
+		}
+		if got := reparsed.Tunnel["i2p.streaming.maxConnsPerMinute"]; got != 5 {
+			t.Errorf("%s round trip lost the option: %#v", tt.format, reparsed.Tunnel)
+		}
+	}
+}
+
+// TestGenerateJavaProperties_SectionHeaders checks the commented section
+// headers that separate each group of options in properties output.
+func TestGenerateJavaProperties_SectionHeaders(t *testing.T) {
+	config := &TunnelConfig{
+		Name:     "web",
+		Type:     "httpclient",
+		Port:     4444,
+		I2CP:     map[string]interface{}{"reduceOnIdle": true},
+		Tunnel:   map[string]interface{}{"proxyList": "false.i2p"},
+		Inbound:  map[string]interface{}{"length": 2},
+		Outbound: map[string]interface{}{"length": 2},
+		Unknown:  map[string]string{"custom.setting": "x"},
+	}
+	conv := &Converter{}
+	out, err := conv.generateJavaProperties(config)
+	if err != nil {
+		t.Fatalf("generateJavaProperties() error = %v", err)
+	}
+	want := "name=web\ntype=httpclient\nlistenPort=4444\n" +
+		"\n# I2CP options\noption.i2cp.reduceOnIdle=true\n" +
+		"\n# Tunnel options\nproxyList=false.i2p\n" +
+		"\n# Inbound tunnel options\noption.inbound.length=2\n" +
+		"\n# Outbound tunnel options\noption.outbound.length=2\n" +
+		"\n# Other settings\ncustom.setting=x\n"
+	if string(out) != want {
+		t.Errorf("output:\n%s\nwant:\n%s", out, want)
+	}
+
+	// Empty groups get no header.
+	out, _ = conv.generateJavaProperties(&TunnelConfig{Name: "bare", Type: "client", Port: 1234})
+	if strings.Contains(string(out), "#") {
+		t.Errorf("headers written for empty groups:\n%s", out)
+	}
+
+	// Re-reading the output keeps the headers out of the collected comments,
+	// so a properties round trip writes each header once.
+	config.Unknown = nil
+	if out, err = conv.generateJavaProperties(config); err != nil {
+		t.Fatalf("generateJavaProperties() error = %v", err)
+	}
+	user := "# My proxy\n\n" + string(out) + "# end\n"
+	reparsed, err := conv.ParseInput([]byte(user), "properties")
+	if err != nil {
+		t.Fatalf("ParseInput() error = %v", err)
+	}
+	again, err := conv.generateJavaProperties(reparsed)
+	if err != nil {
+		t.Fatalf("generateJavaProperties() error = %v", err)
+	}
+	if string(again) != user {
+		t.Errorf("round trip changed the output:\n%s\nwant:\n%s", again, user)
+	}
+}
+
+// TestGenerateJavaProperties_SplitsServerAddress checks that an i2pd server
+// address with a port is written as separate targetHost and targetPort keys,
+// and that the result converts back to the same address.
+func TestGenerateJavaProperties_SplitsServerAddress(t *testing.T) {
+	tests := []struct {
+		name   string
+		config *TunnelConfig
+		want   string
+	}{
+		{
+			name:   "host and port",
+			config: &TunnelConfig{Name: "web", Type: "httpserver", Target: "127.0.0.1:8080"},
+			want:   "targetHost=127.0.0.1\ntargetPort=8080\n",
+		},
+		{
+			name:   "ipv6 host and port",
+			config: &TunnelConfig{Name: "web", Type: "server", Target: "[::1]:8080"},
+			want:   "targetHost=::1\ntargetPort=8080\n",
+		},
+		{
+			name:   "host only",
+			config: &TunnelConfig{Name: "web", Type: "server", Target: "127.0.0.1"},
+			want:   "targetHost=127.0.0.1\n",
+		},
+	}
+	conv := &Converter{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := conv.generateJavaProperties(tt.config)
+			if err != nil {
+				t.Fatalf("generateJavaProperties() error = %v", err)
+			}
+			if !strings.Contains(string(out), tt.want) {
+				t.Errorf("output lacks %q:\n%s", tt.want, out)
+			}
+			if err := verifyOutput(tt.config, out, "properties", conv); err != nil {
+				t.Errorf("verifyOutput() error = %v", err)
+			}
+			reparsed, err := conv.ParseInput(out, "properties")
+			if err != nil {
+				t.Fatalf("ParseInput() error = %v", err)
+			}
+			if address, _ := iniServerAddress(reparsed); address != tt.config.Target {
+				t.Errorf("address after round trip = %q, want %q", address, tt.config.Target)
+			}
+		})
+	}
+}
@@ -0,0 +1,171 @@
+package i2pconv
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// allTunnelTypes is the rule type that applies a custom rule to every tunnel
+// type.
+const allTunnelTypes = "*"
+
+// RuleFile is the document read by LoadValidationRules. JSON files work too,
+// since JSON is valid YAML:
+//
+//	rules:
+//	  - types: [server, httpserver]
+//	    field: description
+//	    required: true
+//	    description: Server tunnels must say what they serve
+type RuleFile struct {
+	Rules []CustomRule `yaml:"rules"`
+}
+
+// CustomRule is a deployment-specific validation rule. Field uses the paths
+// of TunnelConfig.Get ("description", "i2cp.leaseSetEncType",
+// "options.keyfile"). A value must be one of Allowed, when given, and match
+// Pattern in full, when given. Unset and zero-valued top-level fields count
+// as missing, so they only fail a Required rule.
+type CustomRule struct {
+	Types       []string `yaml:"types"` // Tunnel types the rule applies to; "*" for all
+	Field       string   `yaml:"field"`
+	Required    bool     `yaml:"required"`
+	Allowed     []string `yaml:"allowed"`
+	Pattern     string   `yaml:"pattern"`
+	Description string   `yaml:"description"`
+}
+
+// LoadValidationRules reads a rules file and returns its rules keyed by
+// tunnel type, ready for ValidationContext.AddRules. Unknown tunnel types,
+// unknown fields, and invalid patterns are reported with the rule's index.
+func LoadValidationRules(path string) (map[TunnelType][]ValidationRule, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rules file '%s': %w", path, err)
+	}
+	var file RuleFile
+	if err := yaml.UnmarshalStrict(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse rules file '%s': %w", path, err)
+	}
+
+	known := NewValidationContext(false, "").TunnelSpecs
+	rules := make(map[TunnelType][]ValidationRule)
+	for i, r := range file.Rules {
+		rule, err := r.validationRule()
+		if err != nil {
+			return nil, fmt.Errorf("rules file '%s': rule %d: %w", path, i+1, err)
+		}
+		if len(r.Types) == 0 {
+			return nil, fmt.Errorf("rules file '%s': rule %d: no tunnel types given (use \"%s\" for all)", path, i+1, allTunnelTypes)
+		}
+		for _, t := range r.Types {
+			tunnelType := TunnelType(strings.ToLower(t))
+			if _, ok := known[tunnelType]; !ok && t != allTunnelTypes {
+				return nil, fmt.Errorf("rules file '%s': rule %d: unknown tunnel type '%s'", path, i+1, t)
+			}
+			rules[tunnelType] = append(rules[tunnelType], rule)
+		}
+	}
+	return rules, nil
+}
+
+// validationRule converts r into a ValidationRule whose validator applies
+// the required, allowed, and pattern constraints.
+func (r CustomRule) validationRule() (ValidationRule, error) {
+	if !isRuleField(r.Field) {
+		return ValidationRule{}, fmt.Errorf("unknown field '%s'", r.Field)
+	}
+	var pattern *regexp.Regexp
+	if r.Pattern != "" {
+		var err error
+		pattern, err = regexp.Compile("^(?:" + r.Pattern + ")$")
+		if err != nil {
+			return ValidationRule{}, fmt.Errorf("invalid pattern for '%s': %w", r.Field, err)
+		}
+	}
+	description := r.Description
+	if description == "" {
+		description = fmt.Sprintf("Custom rule for %s", r.Field)
+	}
+
+	return ValidationRule{
+		Field:       r.Field,
+		Description: description,
+		Validator: func(config *TunnelConfig) error {
+			value, set := ruleFieldValue(config, r.Field)
+			if !set {
+				if r.Required {
+					return fmt.Errorf("%s is required", r.Field)
+				}
+				return nil
+			}
+			if len(r.Allowed) > 0 && !slices.Contains(r.Allowed, value) {
+				return fmt.Errorf("%s is '%s'; allowed values are %s", r.Field, value, strings.Join(r.Allowed, ", "))
+			}
+			if pattern != nil && !pattern.MatchString(value) {
+				return fmt.Errorf("%s '%s' does not match pattern '%s'", r.Field, value, r.Pattern)
+			}
+			return nil
+		},
+	}, nil
+}
+
+// isRuleField reports whether field is a path TunnelConfig.Get understands.
+func isRuleField(field string) bool {
+	switch field {
+	case "name", "type", "interface", "port", "target", "persistentKey", "description":
+		return true
+	}
+	section, key, ok := strings.Cut(field, ".")
+	if !ok || key == "" {
+		return false
+	}
+	_, ok = (&TunnelConfig{}).optionMap(section)
+	return ok
+}
+
+// ruleFieldValue returns the value at field in its serialised form. The
+// second result is false when the field is unset, or is a zero-valued
+// top-level field.
+func ruleFieldValue(config *TunnelConfig, field string) (string, bool) {
+	v, ok := config.Get(field)
+	if !ok {
+		return "", false
+	}
+	if !strings.Contains(field, ".") {
+		switch val := v.(type) {
+		case string:
+			if val == "" {
+				return "", false
+			}
+		case int:
+			if val == 0 {
+				return "", false
+			}
+		case bool:
+			if !val {
+				return "", false
+			}
+		}
+	}
+	return formatPropertyValue(v), true
+}
+
+// AddRules appends rules to the specs of their tunnel types. Rules keyed by
+// "*" are added to every type.
+func (v *ValidationContext) AddRules(rules map[TunnelType][]ValidationRule) {
+	for tunnelType, extra := range rules {
+		for name, spec := range v.TunnelSpecs {
+			if tunnelType != allTunnelTypes && tunnelType != name {
+				continue
+			}
+			spec.Rules = append(append([]ValidationRule(nil), spec.Rules...), extra...)
+			v.TunnelSpecs[name] = spec
+		}
+	}
+}
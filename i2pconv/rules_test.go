@@ -0,0 +1,155 @@
+package i2pconv
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/urfave/cli/v2"
+)
+
+// serverPolicyRules requires a description on server tunnels and limits the
+// lease set encryption of every tunnel.
+const serverPolicyRules = `rules:
+  - types: [server, httpserver]
+    field: description
+    required: true
+    description: Server tunnels must say what they serve
+  - types: ["*"]
+    field: i2cp.leaseSetEncType
+    allowed: ["4", "4,0"]
+  - types: [httpclient]
+    field: options.outproxy
+    pattern: '[a-z0-9.]+\.i2p'
+`
+
+func writeRules(t *testing.T, rules string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "rules.yaml")
+	if err := os.WriteFile(path, []byte(rules), 0o644); err != nil {
+		t.Fatalf("setup: %v", err)
+	}
+	return path
+}
+
+// TestLoadValidationRules loads a rules file and checks the configs its rules
+// accept and reject.
+func TestLoadValidationRules(t *testing.T) {
+	rules, err := LoadValidationRules(writeRules(t, serverPolicyRules))
+	if err != nil {
+		t.Fatalf("LoadValidationRules() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		config  *TunnelConfig
+		wantErr string
+	}{
+		{
+			name:   "server with description",
+			config: &TunnelConfig{Name: "web", Type: "httpserver", Target: "127.0.0.1:8080", Description: "Blog"},
+		},
+		{
+			name:    "server without description",
+			config:  &TunnelConfig{Name: "web", Type: "server", Target: "127.0.0.1:8080"},
+			wantErr: "Server tunnels must say what they serve: description is required",
+		},
+		{
+			name:   "client without description",
+			config: &TunnelConfig{Name: "proxy", Type: "httpclient", Port: 4444},
+		},
+		{
+			name:    "disallowed value for all types",
+			config:  &TunnelConfig{Name: "proxy", Type: "client", Port: 4444, I2CP: map[string]interface{}{"leaseSetEncType": 0}},
+			wantErr: "i2cp.leaseSetEncType is '0'; allowed values are 4, 4,0",
+		},
+		{
+			name:   "allowed list value",
+			config: &TunnelConfig{Name: "proxy", Type: "client", Port: 4444, I2CP: map[string]interface{}{"leaseSetEncType": []string{"4", "0"}}},
+		},
+		{
+			name:    "pattern mismatch",
+			config:  &TunnelConfig{Name: "proxy", Type: "httpclient", Port: 4444, Tunnel: map[string]interface{}{"outproxy": "exit.example.com"}},
+			wantErr: "does not match pattern",
+		},
+		{
+			name:   "pattern match",
+			config: &TunnelConfig{Name: "proxy", Type: "httpclient", Port: 4444, Tunnel: map[string]interface{}{"outproxy": "exit.stormycloud.i2p"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := NewValidationContext(false, "")
+			ctx.AddRules(rules)
+			err := ctx.Validate(tt.config)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Validate() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	if err := NewValidationContext(false, "").Validate(&TunnelConfig{Name: "web", Type: "server", Target: "127.0.0.1:8080"}); err != nil {
+		t.Errorf("rules must not leak into a fresh context: %v", err)
+	}
+}
+
+// TestLoadValidationRules_Invalid checks the errors for rules files with
+// unknown types or fields, bad patterns, or misspelled keys.
+func TestLoadValidationRules_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		rules   string
+		wantErr string
+	}{
+		{name: "unknown type", rules: "rules:\n  - types: [webserver]\n    field: description\n", wantErr: "unknown tunnel type 'webserver'"},
+		{name: "no types", rules: "rules:\n  - field: description\n", wantErr: "no tunnel types given"},
+		{name: "unknown field", rules: "rules:\n  - types: [server]\n    field: descripton\n", wantErr: "unknown field 'descripton'"},
+		{name: "bad pattern", rules: "rules:\n  - types: [server]\n    field: target\n    pattern: '('\n", wantErr: "invalid pattern"},
+		{name: "misspelled key", rules: "rules:\n  - types: [server]\n    field: target\n    requird: true\n", wantErr: "failed to parse"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadValidationRules(writeRules(t, tt.rules))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("LoadValidationRules() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestConvertCommand_Rules checks that --rules applies a rules file to the
+// conversion and reports a file that cannot be read.
+func TestConvertCommand_Rules(t *testing.T) {
+	rulesFile := filepath.Join(t.TempDir(), "rules.json")
+	rules := `{"rules": [{"types": ["server"], "field": "description", "required": true}]}`
+	if err := os.WriteFile(rulesFile, []byte(rules), 0o644); err != nil {
+		t.Fatalf("setup: %v", err)
+	}
+	input := filepath.Join(t.TempDir(), "server.conf")
+	if err := os.WriteFile(input, []byte("[web]\ntype = server\nhost = 127.0.0.1\nport = 8080\n"), 0o644); err != nil {
+		t.Fatalf("setup: %v", err)
+	}
+
+	app := makeStdinApp()
+	app.Flags = append(app.Flags, &cli.StringFlag{Name: "rules"})
+	err := app.Run([]string{"go-i2ptunnel-config", "--validate", "--rules", rulesFile, input})
+	if err == nil || !strings.Contains(err.Error(), "description is required") {
+		t.Errorf("expected custom rule failure, got %v", err)
+	}
+
+	app = makeStdinApp()
+	app.Flags = append(app.Flags, &cli.StringFlag{Name: "rules"})
+	err = app.Run([]string{"go-i2ptunnel-config", "--validate", "--rules", filepath.Join(t.TempDir(), "missing.yaml"), input})
+	if err == nil || !strings.Contains(err.Error(), "failed to read rules file") {
+		t.Errorf("expected rules file error, got %v", err)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/go-i2p/i2pkeys"
@@ -12,37 +13,48 @@ import (
 // SAMTunnelAt returns the I2P keys and SAM options for this tunnel configuration,
 // storing any persistent key file in keystore. If keystore is empty the current
 // working directory is used.
-// If PersistentKey is true, keys will be loaded from or created in keystore.
+// If PersistentKey is true, keys will be loaded from or created in keystore,
+// which is created with mode 0700 when it does not exist yet. Keys are loaded
+// from <name>.keys, or else from the file the keyfile option names, in any
+// format LoadKeyFile reads. New keys use the signing key type in
+// Tunnel["signaturetype"] (Ed25519 by default); keys loaded from file keep
+// their own type.
+// The options are sorted, and a key that appears in more than one section is
+// given once, with its first value in the order I2CP, tunnel, inbound, outbound.
+// The session style is not among them; use SAMStyle for the STYLE value.
 func (c *TunnelConfig) SAMTunnelAt(keystore string) (*i2pkeys.I2PKeys, []string, error) {
 	var keys *i2pkeys.I2PKeys
 
 	if c.PersistentKey {
-		if c.Name == "" {
-			return nil, nil, fmt.Errorf("tunnel name is required for persistent keys")
+		keypath, err := c.keyPath(keystore)
+		if err != nil {
+			return nil, nil, err
 		}
+		ks := filepath.Dir(keypath)
 
-		ks := keystore
-		if ks == "" {
-			var err error
-			ks, err = os.Getwd()
+		// Load or create keys for this tunnel. The file is checked here rather
+		// than by i2pkeys.LoadKeys, which would create it itself.
+		if existing, ok := c.existingKeyFile(keypath, ks); ok {
+			keys, err = LoadKeyFile(existing)
 			if err != nil {
-				return nil, nil, fmt.Errorf("failed to get working directory: %w", err)
+				return nil, nil, err
 			}
-		}
-
-		// Load or create keys for this tunnel
-		keypath := filepath.Join(ks, c.Name+".keys")
-		loadedKeys, err := i2pkeys.LoadKeys(keypath)
-		if err == nil {
-			keys = &loadedKeys
 		} else {
-			// Create new keys if none exist
-			newKeys, err := i2pkeys.NewDestination()
+			// Create new keys if none exist, with the tunnel's signaturetype
+			sigType, err := tunnelSignatureType(c)
+			if err != nil {
+				return nil, nil, err
+			}
+			newKeys, err := newDestination(sigType)
 			if err != nil {
 				return nil, nil, fmt.Errorf("failed to generate new I2P keys: %w", err)
 			}
 			keys = newKeys
 
+			if err := os.MkdirAll(ks, 0o700); err != nil {
+				return nil, nil, fmt.Errorf("failed to create keystore directory %s: %w", ks, err)
+			}
+
 			// Store the new keys to file
 			keyFile, err := os.Create(keypath)
 			if err != nil {
@@ -56,48 +68,141 @@ func (c *TunnelConfig) SAMTunnelAt(keystore string) (*i2pkeys.I2PKeys, []string,
 		}
 	}
 
-	// Generate SAM options from config
+	// Generate SAM options from config. seen records each option key once so
+	// duplicate and default checks stay O(1) however many options there are.
 	var opts []string
+	seen := make(map[string]struct{})
+	add := func(key string, v interface{}) {
+		if _, dup := seen[key]; dup {
+			return
+		}
+		seen[key] = struct{}{}
+		opts = append(opts, key+"="+formatPropertyValue(v))
+	}
 
 	// Process I2CP options
-	for k, v := range c.I2CP {
-		opts = append(opts, "i2cp."+k+"="+fmt.Sprint(v))
+	for _, k := range sortedKeys(c.I2CP) {
+		add("i2cp."+k, c.I2CP[k])
 	}
 
 	// Process tunnel options
-	for k, v := range c.Tunnel {
-		opts = append(opts, k+"="+fmt.Sprint(v))
+	for _, k := range sortedKeys(c.Tunnel) {
+		add(k, c.Tunnel[k])
 	}
 
 	// Process inbound/outbound options
-	for k, v := range c.Inbound {
-		opts = append(opts, "inbound."+k+"="+fmt.Sprint(v))
+	for _, k := range sortedKeys(c.Inbound) {
+		add("inbound."+k, c.Inbound[k])
 	}
-	for k, v := range c.Outbound {
-		opts = append(opts, "outbound."+k+"="+fmt.Sprint(v))
+	for _, k := range sortedKeys(c.Outbound) {
+		add("outbound."+k, c.Outbound[k])
 	}
 
 	// Ensure lease set encryption
-	if !hasOption(opts, "i2cp.leaseSetEncType") {
-		opts = append(opts, "i2cp.leaseSetEncType=4,0")
-	}
+	add("i2cp.leaseSetEncType", "4,0")
 
+	// Sort so session setup logs and tests see the same order on every call
+	slices.Sort(opts)
 	return keys, opts, nil
 }
 
+// keyPath returns the path of the persistent key file of this tunnel in
+// keystore, or in the current working directory if keystore is empty.
+func (c *TunnelConfig) keyPath(keystore string) (string, error) {
+	if c.Name == "" {
+		return "", fmt.Errorf("tunnel name is required for persistent keys")
+	}
+	if keystore == "" {
+		var err error
+		keystore, err = os.Getwd()
+		if err != nil {
+			return "", fmt.Errorf("failed to get working directory: %w", err)
+		}
+	}
+	return filepath.Join(keystore, c.Name+".keys"), nil
+}
+
+// existingKeyFile returns the key file to load for this tunnel: keypath when
+// it exists, otherwise the file named by the keyfile option, such as keys
+// exported from Java I2P or an i2pd .dat file. A relative keyfile is looked up
+// in keystore. The second result is false when neither exists.
+func (c *TunnelConfig) existingKeyFile(keypath, keystore string) (string, bool) {
+	if _, err := os.Stat(keypath); err == nil {
+		return keypath, true
+	}
+	keyfile, ok := c.Tunnel["keyfile"]
+	if !ok {
+		return "", false
+	}
+	path := fmt.Sprint(keyfile)
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(keystore, path)
+	}
+	if _, err := os.Stat(path); err != nil {
+		return "", false
+	}
+	return path, true
+}
+
+// B32AddressAt returns the .b32.i2p address of this tunnel's persistent keys,
+// loaded from keystore as SAMTunnelAt would. Unlike SAMTunnelAt it never
+// creates keys: a tunnel with transient keys, or whose key file does not
+// exist yet, has no fixed address and returns an error.
+func (c *TunnelConfig) B32AddressAt(keystore string) (string, error) {
+	if !c.PersistentKey {
+		return "", fmt.Errorf("tunnel '%s' uses transient keys and has no fixed destination", c.Name)
+	}
+	keypath, err := c.keyPath(keystore)
+	if err != nil {
+		return "", err
+	}
+	existing, ok := c.existingKeyFile(keypath, filepath.Dir(keypath))
+	if !ok {
+		return "", fmt.Errorf("tunnel '%s' has no key file %s yet", c.Name, keypath)
+	}
+	keys, err := LoadKeyFile(existing)
+	if err != nil {
+		return "", err
+	}
+	if _, err := keys.Address.ToBytes(); err != nil {
+		return "", fmt.Errorf("key file %s holds an invalid destination: %w", keypath, err)
+	}
+	return keys.Address.Base32(), nil
+}
+
+// B32Address returns the .b32.i2p address of this tunnel's persistent keys,
+// loaded from the current working directory. Use B32AddressAt to specify a
+// custom keystore directory.
+func (c *TunnelConfig) B32Address() (string, error) {
+	return c.B32AddressAt("")
+}
+
+// samStyles lists the SAM session styles a tunnel can use.
+var samStyles = []string{"STREAM", "DATAGRAM", "RAW"}
+
+// samSessionStyle returns the SAM session style for a tunnel type. Streamr
+// and UDP tunnels carry datagrams; every other type is a stream tunnel.
+func samSessionStyle(tunnelType string) string {
+	switch NormalizeTypeName(tunnelType) {
+	case string(TunnelTypeStreamClient), string(TunnelTypeStreamServer), "udpclient", "udpserver":
+		return "DATAGRAM"
+	}
+	return "STREAM"
+}
+
+// SAMStyle returns the STYLE of the SAM session for this tunnel, to be used
+// with the options from SAMTunnel: Style when set, otherwise DATAGRAM for
+// streamr and UDP tunnel types and STREAM for the rest.
+func (c *TunnelConfig) SAMStyle() string {
+	if c.Style != "" {
+		return strings.ToUpper(c.Style)
+	}
+	return samSessionStyle(c.Type)
+}
+
 // SAMTunnel returns the I2P keys and SAM options for this tunnel configuration.
 // If PersistentKey is true, keys will be loaded from or stored to a SAMv3 compatible file
 // in the current working directory. Use SAMTunnelAt to specify a custom keystore directory.
 func (c *TunnelConfig) SAMTunnel() (*i2pkeys.I2PKeys, []string, error) {
 	return c.SAMTunnelAt("")
 }
-
-// hasOption reports whether any element in opts starts with prefix.
-func hasOption(opts []string, prefix string) bool {
-	for _, opt := range opts {
-		if strings.HasPrefix(opt, prefix) {
-			return true
-		}
-	}
-	return false
-}
@@ -1,10 +1,14 @@
 package i2pconv
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
+
+	"github.com/go-i2p/i2pkeys"
 )
 
 // TestSAMTunnel_NonPersistent tests SAM tunnel creation without persistent keys
@@ -38,36 +42,38 @@ func TestSAMTunnel_NonPersistent(t *testing.T) {
 		t.Error("Expected nil keys for non-persistent tunnel")
 	}
 
-	// Check that options are properly formatted
-	expectedOpts := []string{
+	// Options are sorted, and the configured leaseSetEncType replaces the default
+	want := []string{
 		"i2cp.leaseSetEncType=4,0",
 		"i2cp.tunnelDepth=3",
-		"quantity=2",
 		"inbound.length=3",
 		"outbound.length=3",
-		"i2cp.leaseSetEncType=4,0", // Default should be added if not present
+		"quantity=2",
+	}
+	if !slices.Equal(opts, want) {
+		t.Errorf("opts = %v, want %v", opts, want)
 	}
+}
 
-	// Check that all expected options are present (order may vary)
-	for _, expected := range expectedOpts {
-		found := false
-		for _, opt := range opts {
-			if opt == expected {
-				found = true
-				break
-			}
-		}
-		if !found && !strings.Contains(expected, "i2cp.leaseSetEncType=4,0") {
-			// Skip duplicate leaseSetEncType check since it may be present twice
-			if !hasOption(opts, "i2cp.leaseSetEncType") {
-				t.Errorf("Expected option %q not found in %v", expected, opts)
-			}
+// TestSAMTunnel_ListOptions checks that list-valued options, which parsing
+// stores as slices, are sent to SAM comma-separated.
+func TestSAMTunnel_ListOptions(t *testing.T) {
+	input := "tunnels:\n  web:\n    type: httpclient\n    port: 4444\n    i2cp:\n      leaseSetEncType: \"4,0\"\n    options:\n      proxyList: [a.i2p, b.i2p]\n"
+	config, err := (&Converter{}).ParseInput([]byte(input), "yaml")
+	if err != nil {
+		t.Fatalf("ParseInput() error = %v", err)
+	}
+	_, opts, err := config.SAMTunnel()
+	if err != nil {
+		t.Fatalf("SAMTunnel() error = %v", err)
+	}
+	for _, want := range []string{"i2cp.leaseSetEncType=4,0", "proxyList=a.i2p,b.i2p"} {
+		if !slices.Contains(opts, want) {
+			t.Errorf("opts = %v, want %s", opts, want)
 		}
 	}
-
-	// Verify the default lease set encryption is added
-	if !hasOption(opts, "i2cp.leaseSetEncType") {
-		t.Error("Default i2cp.leaseSetEncType should be added when not present")
+	if _, err := samSessionCreateCommand(config, "probe-1"); err != nil {
+		t.Errorf("samSessionCreateCommand() error = %v", err)
 	}
 }
 
@@ -115,7 +121,7 @@ func TestSAMTunnel_PersistentNewKeys(t *testing.T) {
 	}
 
 	// Verify default options are present
-	if !hasOption(opts, "i2cp.leaseSetEncType") {
+	if !slices.Contains(opts, "i2cp.leaseSetEncType=4,0") {
 		t.Error("Default i2cp.leaseSetEncType should be added")
 	}
 }
@@ -280,11 +286,6 @@ func TestSAMTunnel_LeaseSetEncTypeDefault(t *testing.T) {
 		t.Fatalf("Unexpected error: %v", err)
 	}
 
-	// Should have default lease set encryption
-	if !hasOption(opts, "i2cp.leaseSetEncType") {
-		t.Error("Expected default i2cp.leaseSetEncType=4,0 when not specified")
-	}
-
 	// Check specific default value
 	found := false
 	for _, opt := range opts {
@@ -374,3 +375,294 @@ func TestSAMTunnel_ReadOnlyDirectory(t *testing.T) {
 		t.Errorf("Expected 'failed to create key file' in error, got: %v", err)
 	}
 }
+
+// TestSAMTunnel_DuplicateOptionKeys checks that an option key reaching SAM
+// from two maps is sent once, with the first value kept, and that an
+// explicit leaseSetEncType suppresses the default.
+func TestSAMTunnel_DuplicateOptionKeys(t *testing.T) {
+	config := &TunnelConfig{
+		Name:    "dup",
+		Type:    "client",
+		I2CP:    map[string]interface{}{"leaseSetEncType": 4, "reduceOnIdle": true},
+		Tunnel:  map[string]interface{}{"i2cp.reduceOnIdle": false, "inbound.length": 1},
+		Inbound: map[string]interface{}{"length": 3},
+	}
+
+	_, opts, err := config.SAMTunnel()
+	if err != nil {
+		t.Fatalf("SAMTunnel() error = %v", err)
+	}
+	want := []string{"i2cp.leaseSetEncType=4", "i2cp.reduceOnIdle=true", "inbound.length=1"}
+	if strings.Join(opts, " ") != strings.Join(want, " ") {
+		t.Errorf("opts = %v, want %v", opts, want)
+	}
+}
+
+// BenchmarkSAMTunnel_ManyOptions measures building SAM options for configs
+// with growing option counts; the time per option should stay flat.
+func BenchmarkSAMTunnel_ManyOptions(b *testing.B) {
+	for _, n := range []int{10, 100, 1000} {
+		config := &TunnelConfig{
+			Name:     "bench",
+			Type:     "client",
+			I2CP:     make(map[string]interface{}, n),
+			Tunnel:   make(map[string]interface{}, n),
+			Inbound:  map[string]interface{}{"length": 3},
+			Outbound: map[string]interface{}{"length": 3},
+		}
+		for i := 0; i < n; i++ {
+			config.I2CP[fmt.Sprintf("option%d", i)] = i
+			config.Tunnel[fmt.Sprintf("tunnel.option%d", i)] = i
+		}
+		b.Run(fmt.Sprintf("options=%d", 2*n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, _, err := config.SAMTunnel(); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// TestSAMTunnelAt_CreatesKeystore checks that a missing keystore directory is
+// created and the new .keys file lands in it, and that a second call loads the
+// stored keys instead of generating new ones.
+func TestSAMTunnelAt_CreatesKeystore(t *testing.T) {
+	calls := fakeDestination(t)
+	keystore := filepath.Join(t.TempDir(), "var", "keys")
+	config := &TunnelConfig{Name: "daemon", Type: "server", PersistentKey: true}
+
+	keys, _, err := config.SAMTunnelAt(keystore)
+	if err != nil {
+		t.Fatalf("SAMTunnelAt() error = %v", err)
+	}
+	keypath := filepath.Join(keystore, "daemon.keys")
+	if _, err := os.Stat(keypath); err != nil {
+		t.Fatalf("key file not created in keystore: %v", err)
+	}
+	if dirInfo, err := os.Stat(keystore); err != nil {
+		t.Errorf("keystore directory not created: %v", err)
+	} else if dirInfo.Mode().Perm() != 0o700 {
+		t.Errorf("keystore directory mode = %v, want 0700", dirInfo.Mode().Perm())
+	}
+
+	again, _, err := config.SAMTunnelAt(keystore)
+	if err != nil {
+		t.Fatalf("second SAMTunnelAt() error = %v", err)
+	}
+	if *calls != 1 {
+		t.Errorf("generated %d destinations, want 1", *calls)
+	}
+	if again.Both != keys.Both {
+		t.Errorf("reloaded keys differ from the stored ones")
+	}
+}
+
+// TestSAMTunnelAt_SignatureType checks that new keys are requested with the
+// tunnel's signaturetype, that unsupported types are rejected before any key
+// is generated, and that stored keys are loaded whatever signaturetype says.
+func TestSAMTunnelAt_SignatureType(t *testing.T) {
+	var requested []int
+	orig := newDestination
+	newDestination = func(sigType int) (*i2pkeys.I2PKeys, error) {
+		requested = append(requested, sigType)
+		keys := i2pkeys.NewKeys(i2pkeys.I2PAddr(fakeDestinationAddress(sigType)), i2pBase64.EncodeToString([]byte("private key file")))
+		return &keys, nil
+	}
+	t.Cleanup(func() { newDestination = orig })
+	keystore := t.TempDir()
+
+	config := &TunnelConfig{Name: "signed", Type: "server", PersistentKey: true,
+		Tunnel: map[string]interface{}{"signaturetype": 11}}
+	keys, _, err := config.SAMTunnelAt(keystore)
+	if err != nil {
+		t.Fatalf("SAMTunnelAt() error = %v", err)
+	}
+	defaulted := &TunnelConfig{Name: "plain", Type: "server", PersistentKey: true}
+	if _, _, err := defaulted.SAMTunnelAt(keystore); err != nil {
+		t.Fatalf("SAMTunnelAt() error = %v", err)
+	}
+	if !slices.Equal(requested, []int{11, defaultSignatureType}) {
+		t.Errorf("requested signature types %v, want [11 %d]", requested, defaultSignatureType)
+	}
+
+	// The stored keys are loaded as they are, whatever signaturetype now says
+	config.Tunnel["signaturetype"] = 7
+	again, _, err := config.SAMTunnelAt(keystore)
+	if err != nil {
+		t.Fatalf("second SAMTunnelAt() error = %v", err)
+	}
+	if again.Address != keys.Address {
+		t.Errorf("loaded destination %s, want the stored %s", again.Address, keys.Address)
+	}
+
+	unsupported := &TunnelConfig{Name: "bad", Type: "server", PersistentKey: true,
+		Tunnel: map[string]interface{}{"signaturetype": 99}}
+	if _, _, err := unsupported.SAMTunnelAt(keystore); err == nil || !strings.Contains(err.Error(), "signaturetype '99' is not supported") {
+		t.Errorf("SAMTunnelAt() error = %v, want unsupported signaturetype", err)
+	}
+	if _, err := os.Stat(filepath.Join(keystore, "bad.keys")); err == nil {
+		t.Error("key file written for an unsupported signaturetype")
+	}
+	if len(requested) != 2 {
+		t.Errorf("generated %d destinations, want 2", len(requested))
+	}
+}
+
+// TestTunnelConfig_B32Address checks that B32AddressAt reports a missing key
+// file and otherwise gives the .b32.i2p address of the stored keys.
+func TestTunnelConfig_B32Address(t *testing.T) {
+	fakeDestination(t)
+	keystore := t.TempDir()
+	config := &TunnelConfig{Name: "site", Type: "server", PersistentKey: true}
+
+	if _, err := config.B32AddressAt(keystore); err == nil || !strings.Contains(err.Error(), "has no key file") {
+		t.Errorf("B32AddressAt() before keys exist error = %v, want missing key file", err)
+	}
+	keys, _, err := config.SAMTunnelAt(keystore)
+	if err != nil {
+		t.Fatalf("SAMTunnelAt() error = %v", err)
+	}
+
+	addr, err := config.B32AddressAt(keystore)
+	if err != nil {
+		t.Fatalf("B32AddressAt() error = %v", err)
+	}
+	label, ok := strings.CutSuffix(addr, ".b32.i2p")
+	if !ok || len(label) != b32AddressLength || !isI2PDestination(addr) {
+		t.Errorf("B32AddressAt() = %q, want a .b32.i2p address", addr)
+	}
+	if want := keys.Address.Base32(); addr != want {
+		t.Errorf("B32AddressAt() = %q, want %q from the generated keys", addr, want)
+	}
+
+	transient := &TunnelConfig{Name: "proxy", Type: "client"}
+	if _, err := transient.B32AddressAt(keystore); err == nil || !strings.Contains(err.Error(), "transient keys") {
+		t.Errorf("B32AddressAt() for transient tunnel error = %v, want transient keys error", err)
+	}
+}
+
+// TestSAMTunnel_DeterministicOptions checks that SAMTunnel returns the same
+// sorted options on every call, whatever the map iteration order.
+func TestSAMTunnel_DeterministicOptions(t *testing.T) {
+	config := &TunnelConfig{
+		Name:     "stable",
+		Type:     "server",
+		I2CP:     map[string]interface{}{"reduceOnIdle": true, "closeIdleTime": 1800000, "leaseSetEncType": "4"},
+		Tunnel:   map[string]interface{}{"shouldBundleReplyInfo": false, "i2cp.reduceOnIdle": false, "accessList": "a,b"},
+		Inbound:  map[string]interface{}{"length": 2, "quantity": 3},
+		Outbound: map[string]interface{}{"length": 1, "backupQuantity": 1},
+	}
+
+	_, first, err := config.SAMTunnel()
+	if err != nil {
+		t.Fatalf("SAMTunnel() error = %v", err)
+	}
+	if !slices.IsSorted(first) {
+		t.Errorf("opts are not sorted: %v", first)
+	}
+	for i := 0; i < 20; i++ {
+		_, opts, err := config.SAMTunnel()
+		if err != nil {
+			t.Fatalf("SAMTunnel() error = %v", err)
+		}
+		if !slices.Equal(opts, first) {
+			t.Fatalf("call %d returned %v, want %v", i+2, opts, first)
+		}
+	}
+	if !slices.Contains(first, "i2cp.reduceOnIdle=true") || slices.Contains(first, "i2cp.reduceOnIdle=false") {
+		t.Errorf("the i2cp section should win over a repeated tunnel option: %v", first)
+	}
+}
+
+// TestTunnelConfig_SAMStyle checks the SAM style derived from each tunnel
+// type and that an explicit Style wins.
+func TestTunnelConfig_SAMStyle(t *testing.T) {
+	tests := []struct {
+		name   string
+		config *TunnelConfig
+		want   string
+	}{
+		{name: "streamr server", config: &TunnelConfig{Name: "feed", Type: "streamrserver"}, want: "DATAGRAM"},
+		{name: "streamr client", config: &TunnelConfig{Name: "feed", Type: "streamrclient"}, want: "DATAGRAM"},
+		{name: "i2pd udp server", config: &TunnelConfig{Name: "dns", Type: "udpserver"}, want: "DATAGRAM"},
+		{name: "client", config: &TunnelConfig{Name: "proxy", Type: "client"}, want: "STREAM"},
+		{name: "http server", config: &TunnelConfig{Name: "web", Type: "httpserver"}, want: "STREAM"},
+		{name: "explicit style", config: &TunnelConfig{Name: "raw", Type: "client", Style: "raw"}, want: "RAW"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.config.SAMStyle(); got != tt.want {
+				t.Errorf("SAMStyle() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestStyleParsing checks that the style is read from YAML and from the INI
+// udptunnel alias, and that an unknown style fails validation.
+func TestStyleParsing(t *testing.T) {
+	conv := &Converter{}
+	config, err := conv.ParseInput([]byte("tunnels:\n  feed:\n    type: client\n    port: 5000\n    style: DATAGRAM\n"), "yaml")
+	if err != nil {
+		t.Fatalf("ParseInput(yaml) error = %v", err)
+	}
+	if config.SAMStyle() != "DATAGRAM" {
+		t.Errorf("YAML style = %q, want DATAGRAM", config.SAMStyle())
+	}
+
+	config, err = conv.ParseInput([]byte("[dns]\ntype = udptunnel\nport = 5353\ndestination = dns.i2p\n"), "ini")
+	if err != nil {
+		t.Fatalf("ParseInput(ini) error = %v", err)
+	}
+	if config.Type != "client" || config.SAMStyle() != "DATAGRAM" {
+		t.Errorf("udptunnel parsed as type %q style %q, want client DATAGRAM", config.Type, config.SAMStyle())
+	}
+
+	bad := &TunnelConfig{Name: "feed", Type: "client", Port: 5000, Style: "SOCKET"}
+	if err := NewValidationContext(false, "").Validate(bad); err == nil || !strings.Contains(err.Error(), "style 'SOCKET' is not a SAM session style") {
+		t.Errorf("Validate() error = %v, want invalid style", err)
+	}
+}
+
+// TestStyleConversion checks that the udptunnel datagram style survives an
+// INI round trip, and that converting a style to a format that cannot express
+// it warns.
+func TestStyleConversion(t *testing.T) {
+	conv := &Converter{}
+	config, err := conv.ParseInput([]byte("[dns]\ntype = udptunnel\nport = 5353\ndestination = dns.i2p\n"), "ini")
+	if err != nil {
+		t.Fatalf("ParseInput(ini) error = %v", err)
+	}
+	out, err := conv.generateOutput(config, "ini")
+	if err != nil {
+		t.Fatalf("generateOutput(ini) error = %v", err)
+	}
+	if !strings.Contains(string(out), "type = udptunnel\n") {
+		t.Errorf("INI output lost the udptunnel type:\n%s", out)
+	}
+	if err := verifyOutput(config, out, "ini", conv); err != nil {
+		t.Errorf("verifyOutput(ini) error = %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		config    *TunnelConfig
+		outFormat string
+		wantWarn  bool
+	}{
+		{name: "datagram client to ini", config: config, outFormat: "ini"},
+		{name: "datagram client to properties", config: config, outFormat: "properties", wantWarn: true},
+		{name: "datagram client to yaml", config: config, outFormat: "yaml"},
+		{name: "raw client to ini", config: &TunnelConfig{Name: "raw", Type: "client", Style: "RAW"}, outFormat: "ini", wantWarn: true},
+		{name: "style implied by type", config: &TunnelConfig{Name: "feed", Type: "streamrclient", Style: "datagram"}, outFormat: "properties"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkStyle(tt.config, tt.outFormat)
+			if (err != nil) != tt.wantWarn {
+				t.Errorf("checkStyle() = %v, want warning %v", err, tt.wantWarn)
+			}
+		})
+	}
+}
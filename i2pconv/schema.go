@@ -0,0 +1,104 @@
+package i2pconv
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// schemaID identifies the generated schema; editors use it as the cache key.
+const schemaID = "https://github.com/go-i2p/go-i2ptunnel-config/tunnels.schema.json"
+
+// tunnelsSchema returns a JSON Schema for the go-i2p YAML format: a "tunnels"
+// object whose values are tunnel entries, or a list of entries. The entry properties are derived
+// from the yaml tags on TunnelConfig and the "type" enum from the tunnel types
+// ValidationContext knows, so the schema follows the struct as it changes.
+func tunnelsSchema() map[string]interface{} {
+	entry := tunnelEntrySchema()
+	return map[string]interface{}{
+		"$schema":     "https://json-schema.org/draft/2020-12/schema",
+		"$id":         schemaID,
+		"title":       "go-i2p tunnel configuration",
+		"type":        "object",
+		"required":    []string{"tunnels"},
+		"description": "I2P tunnel definitions keyed by tunnel name or listed with their names",
+		"properties": map[string]interface{}{
+			"tunnels": map[string]interface{}{
+				"oneOf": []interface{}{
+					map[string]interface{}{"type": "object", "additionalProperties": entry},
+					map[string]interface{}{"type": "array", "items": entry},
+				},
+			},
+		},
+	}
+}
+
+// tunnelEntrySchema returns the schema of a single tunnel entry. Fields
+// without omitempty in their yaml tag are required.
+func tunnelEntrySchema() map[string]interface{} {
+	properties := make(map[string]interface{})
+	var required []string
+	t := reflect.TypeOf(TunnelConfig{})
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, opts, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		properties[name] = fieldSchema(field.Type)
+		if !strings.Contains(opts, "omitempty") {
+			required = append(required, name)
+		}
+	}
+
+	ctx := NewValidationContext(false, "")
+	var types []string
+	for _, tt := range ctx.GetSupportedTunnelTypes() {
+		types = append(types, string(tt))
+	}
+	sort.Strings(types)
+	properties["type"].(map[string]interface{})["enum"] = types
+
+	return map[string]interface{}{
+		"type":                 "object",
+		"required":             required,
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+}
+
+// fieldSchema maps a TunnelConfig field type to its JSON Schema. Option maps
+// accept any value type, since list values and numbers are kept as written.
+func fieldSchema(t reflect.Type) map[string]interface{} {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Int, reflect.Int64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Map:
+		if t.Elem().Kind() == reflect.String {
+			return map[string]interface{}{"type": "object", "additionalProperties": map[string]interface{}{"type": "string"}}
+		}
+		return map[string]interface{}{"type": "object"}
+	default:
+		return map[string]interface{}{}
+	}
+}
+
+// printSchema writes the indented JSON Schema for the YAML format to w.
+func printSchema(w io.Writer) error {
+	data, err := json.MarshalIndent(tunnelsSchema(), "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode schema: %w", err)
+	}
+	_, err = fmt.Fprintln(w, string(data))
+	return err
+}
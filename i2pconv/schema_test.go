@@ -0,0 +1,110 @@
+package i2pconv
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"gopkg.in/yaml.v2"
+)
+
+// TestTunnelsSchema checks the structure of the printed JSON Schema: the map
+// and list forms of tunnels, the required fields, the type enum, and the field
+// types.
+func TestTunnelsSchema(t *testing.T) {
+	var buf bytes.Buffer
+	if err := printSchema(&buf); err != nil {
+		t.Fatalf("printSchema() error = %v", err)
+	}
+	var doc struct {
+		Properties struct {
+			Tunnels struct {
+				OneOf []struct {
+					AdditionalProperties struct {
+						Required   []string `json:"required"`
+						Properties map[string]struct {
+							Type string   `json:"type"`
+							Enum []string `json:"enum"`
+						} `json:"properties"`
+					} `json:"additionalProperties"`
+				} `json:"oneOf"`
+			} `json:"tunnels"`
+		} `json:"properties"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("schema is not valid JSON: %v", err)
+	}
+	if len(doc.Properties.Tunnels.OneOf) != 2 {
+		t.Fatalf("tunnels has %d forms, want the map and list forms", len(doc.Properties.Tunnels.OneOf))
+	}
+	entry := doc.Properties.Tunnels.OneOf[0].AdditionalProperties
+
+	if got := strings.Join(entry.Required, ","); got != "name,type" {
+		t.Errorf("required = %q, want \"name,type\"", got)
+	}
+	enum := strings.Join(entry.Properties["type"].Enum, ",")
+	for _, tt := range []string{"httpclient", "server", "udptunnel"} {
+		if !strings.Contains(","+enum+",", ","+tt+",") {
+			t.Errorf("type enum %q is missing %q", enum, tt)
+		}
+	}
+	for name, want := range map[string]string{"port": "integer", "enabled": "boolean", "i2cp": "object", "options": "object", "inbound": "object", "outbound": "object"} {
+		if got := entry.Properties[name].Type; got != want {
+			t.Errorf("properties[%q].type = %q, want %q", name, got, want)
+		}
+	}
+}
+
+// TestTunnelsSchema_ValidatesGeneratedYAML checks that generated YAML and the
+// list form of tunnels satisfy the printed schema and that an unknown tunnel
+// type does not.
+func TestTunnelsSchema_ValidatesGeneratedYAML(t *testing.T) {
+	var buf bytes.Buffer
+	if err := printSchema(&buf); err != nil {
+		t.Fatalf("printSchema() error = %v", err)
+	}
+	schema, err := jsonschema.CompileString(schemaID, buf.String())
+	if err != nil {
+		t.Fatalf("schema does not compile: %v", err)
+	}
+
+	validate := func(config *TunnelConfig) error {
+		t.Helper()
+		data, err := (&Converter{}).generateYAML(config)
+		if err != nil {
+			t.Fatalf("generateYAML() error = %v", err)
+		}
+		var doc interface{}
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			t.Fatalf("generated YAML does not parse: %v", err)
+		}
+		return schema.Validate(jsonValue(doc))
+	}
+
+	config := &TunnelConfig{
+		Name:     "web",
+		Type:     "httpclient",
+		Port:     4444,
+		I2CP:     map[string]interface{}{"leaseSetEncType": []string{"4", "0"}},
+		Inbound:  map[string]interface{}{"length": 3},
+		Outbound: map[string]interface{}{"length": 3},
+	}
+	if err := validate(config); err != nil {
+		t.Errorf("generated YAML does not satisfy the schema: %v", err)
+	}
+	list := "tunnels:\n  - name: web\n    type: httpclient\n    port: 4444\n"
+	var doc interface{}
+	if err := yaml.Unmarshal([]byte(list), &doc); err != nil {
+		t.Fatalf("list YAML does not parse: %v", err)
+	}
+	if err := schema.Validate(jsonValue(doc)); err != nil {
+		t.Errorf("list form does not satisfy the schema: %v", err)
+	}
+
+	config.Type = "ftpclient"
+	if err := validate(config); err == nil {
+		t.Error("schema accepted an unknown tunnel type")
+	}
+}
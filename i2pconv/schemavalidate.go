@@ -0,0 +1,62 @@
+package i2pconv
+
+import (
+	"fmt"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"gopkg.in/yaml.v2"
+)
+
+// validateAgainstSchema checks config against the JSON Schema in schemaPath.
+// The config is validated in the shape of a go-i2p YAML tunnel entry, with the
+// same field names ("name", "port", "i2cp", "options", ...), so one schema
+// applies whatever the input format was. Comments are not part of the document.
+func validateAgainstSchema(config *TunnelConfig, schemaPath string) error {
+	schema, err := jsonschema.Compile(schemaPath)
+	if err != nil {
+		return fmt.Errorf("failed to load schema '%s': %w", schemaPath, err)
+	}
+	doc, err := configDocument(config)
+	if err != nil {
+		return err
+	}
+	if err := schema.Validate(doc); err != nil {
+		return fmt.Errorf("schema validation failed: %w", err)
+	}
+	return nil
+}
+
+// configDocument returns config as a generic JSON value built from its YAML
+// field names.
+func configDocument(config *TunnelConfig) (interface{}, error) {
+	data, err := yaml.Marshal(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode config: %w", err)
+	}
+	var doc interface{}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to encode config: %w", err)
+	}
+	return jsonValue(doc), nil
+}
+
+// jsonValue converts the map[interface{}]interface{} values produced by
+// yaml.v2 into the map[string]interface{} values JSON Schema validation expects.
+func jsonValue(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(val))
+		for k, item := range val {
+			m[fmt.Sprint(k)] = jsonValue(item)
+		}
+		return m
+	case []interface{}:
+		out := make([]interface{}, len(val))
+		for i, item := range val {
+			out[i] = jsonValue(item)
+		}
+		return out
+	default:
+		return v
+	}
+}
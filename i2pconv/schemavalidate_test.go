@@ -0,0 +1,95 @@
+package i2pconv
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/urfave/cli/v2"
+)
+
+// requireDescriptionSchema demands a description and a port of at least 1024.
+const requireDescriptionSchema = `{
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "type": "object",
+  "required": ["name", "description"],
+  "properties": {
+    "port": {"type": "integer", "minimum": 1024},
+    "inbound": {
+      "type": "object",
+      "properties": {"length": {"type": "integer", "maximum": 3}}
+    }
+  }
+}`
+
+func writeSchema(t *testing.T, schema string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "policy.schema.json")
+	if err := os.WriteFile(path, []byte(schema), 0o644); err != nil {
+		t.Fatalf("setup: %v", err)
+	}
+	return path
+}
+
+// TestValidateAgainstSchema checks configs against a JSON Schema that requires
+// a description and bounds the tunnel length.
+func TestValidateAgainstSchema(t *testing.T) {
+	schema := writeSchema(t, requireDescriptionSchema)
+
+	tests := []struct {
+		name    string
+		config  *TunnelConfig
+		wantErr string
+	}{
+		{
+			name:   "satisfies schema",
+			config: &TunnelConfig{Name: "web", Type: "httpclient", Port: 4444, Description: "proxy", Inbound: map[string]interface{}{"length": 2}},
+		},
+		{
+			name:    "missing required field",
+			config:  &TunnelConfig{Name: "web", Type: "httpclient", Port: 4444},
+			wantErr: "description",
+		},
+		{
+			name:    "nested option out of range",
+			config:  &TunnelConfig{Name: "web", Type: "httpclient", Port: 4444, Description: "proxy", Inbound: map[string]interface{}{"length": 7}},
+			wantErr: "length",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateAgainstSchema(tt.config, schema)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), "schema validation failed") || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want schema failure mentioning %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	if err := validateAgainstSchema(&TunnelConfig{Name: "web"}, filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("expected an error for a missing schema file")
+	}
+}
+
+// TestConvertCommand_SchemaValidate checks that --schema-validate fails the
+// conversion of a config the schema rejects.
+func TestConvertCommand_SchemaValidate(t *testing.T) {
+	schema := writeSchema(t, requireDescriptionSchema)
+	input := filepath.Join(t.TempDir(), "proxy.properties")
+	if err := os.WriteFile(input, []byte("name=proxy\ntype=httpclient\ninterface=127.0.0.1\nlistenPort=4444\n"), 0o644); err != nil {
+		t.Fatalf("setup: %v", err)
+	}
+
+	app := makeStdinApp()
+	app.Flags = append(app.Flags, &cli.StringFlag{Name: "schema-validate"})
+	err := app.Run([]string{"go-i2ptunnel-config", "--validate", "--schema-validate", schema, input})
+	if err == nil || !strings.Contains(err.Error(), "schema validation failed") {
+		t.Fatalf("expected schema validation error, got %v", err)
+	}
+}
@@ -0,0 +1,58 @@
+package i2pconv
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// privateKeyOptions lists the I2CP options whose values are private key
+// material: the lease set encryption and signing keys, and the private key
+// of an encrypted lease set's client authorisation.
+//
+// The offline signing options (leaseSetOfflineExpiration,
+// leaseSetTransientPublicKey and leaseSetOfflineSignature) are left out: they
+// carry only public data, and the transient signing private key they pair with
+// is stored in the tunnel's private key file, never in the config.
+var privateKeyOptions = map[string]bool{
+	"leaseSetPrivateKey":        true,
+	"leaseSetSigningPrivateKey": true,
+	"leaseSetPrivKey":           true,
+}
+
+// isPrivateKeyOption reports whether the I2CP option key (without the "i2cp."
+// prefix) holds private key material. Besides the fixed names this matches the
+// per-client pre-shared keys, leaseSetClient.psk.N.
+func isPrivateKeyOption(key string) bool {
+	return privateKeyOptions[key] || strings.HasPrefix(key, "leaseSetClient.psk.")
+}
+
+// privateKeyFields returns, sorted, the "i2cp."-prefixed names of the options
+// in config that carry private key material. Unrecognised keys are checked
+// too, so a key the parser did not map is not missed.
+func privateKeyFields(config *TunnelConfig) []string {
+	var fields []string
+	for k := range config.I2CP {
+		if isPrivateKeyOption(k) {
+			fields = append(fields, "i2cp."+k)
+		}
+	}
+	for k := range config.Unknown {
+		if i := strings.LastIndex(k, "i2cp."); i >= 0 && isPrivateKeyOption(k[i+len("i2cp."):]) {
+			fields = append(fields, k)
+		}
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// checkPrivateKeys returns a warning when config embeds private key material.
+// The values are converted byte for byte, so the output is as sensitive as the
+// input and must not be shared.
+func checkPrivateKeys(config *TunnelConfig) error {
+	fields := privateKeyFields(config)
+	if len(fields) == 0 {
+		return nil
+	}
+	return fmt.Errorf("tunnel '%s' contains private key material (%s); the output holds the same secret keys, so keep it private and do not share or commit it", config.Name, strings.Join(fields, ", "))
+}
@@ -0,0 +1,97 @@
+package i2pconv
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/urfave/cli/v2"
+)
+
+const privateKeyProperties = `name=web
+type=httpserver
+targetHost=127.0.0.1
+targetPort=8080
+option.i2cp.leaseSetPrivateKey=ECIES_X25519:3Ab+/cdEF0123456789xyz=
+option.i2cp.leaseSetClient.psk.0=alice:abc-DEF~0=
+`
+
+// TestPrivateKeyFields checks which I2CP options are reported as private key
+// material, including keys left in Unknown and the public offline signing
+// options that must not be.
+func TestPrivateKeyFields(t *testing.T) {
+	tests := []struct {
+		name   string
+		config *TunnelConfig
+		want   string
+	}{
+		{name: "none", config: &TunnelConfig{I2CP: map[string]interface{}{"leaseSetEncType": "4,0"}}},
+		{name: "lease set keys", config: &TunnelConfig{I2CP: map[string]interface{}{"leaseSetSigningPrivateKey": "7:AAAA", "leaseSetPrivateKey": "4:BBBB"}}, want: "i2cp.leaseSetPrivateKey, i2cp.leaseSetSigningPrivateKey"},
+		{name: "client psk", config: &TunnelConfig{I2CP: map[string]interface{}{"leaseSetClient.psk.3": "bob:CCCC"}}, want: "i2cp.leaseSetClient.psk.3"},
+		{name: "offline signing", config: &TunnelConfig{I2CP: map[string]interface{}{"leaseSetOfflineExpiration": "1700000000", "leaseSetTransientPublicKey": "7:EEEE", "leaseSetOfflineSignature": "FFFF"}}},
+		{name: "unknown key", config: &TunnelConfig{Unknown: map[string]string{"tunnel.0.option.i2cp.leaseSetPrivKey": "DDDD"}}, want: "tunnel.0.option.i2cp.leaseSetPrivKey"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := strings.Join(privateKeyFields(tt.config), ", "); got != tt.want {
+				t.Errorf("privateKeyFields() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestConvertCommand_PrivateKeyWarning converts a tunnel holding private keys
+// to each format and checks the warning is printed and the keys survive
+// unchanged.
+func TestConvertCommand_PrivateKeyWarning(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "web.properties")
+	if err := os.WriteFile(input, []byte(privateKeyProperties), 0o644); err != nil {
+		t.Fatalf("setup: %v", err)
+	}
+	run := func(format string, extra ...string) (string, string) {
+		t.Helper()
+		app := makeStdinApp()
+		app.Flags = append(app.Flags, &cli.BoolFlag{Name: "no-key-warning"})
+		output := filepath.Join(t.TempDir(), "web"+extensionForFormat(format))
+		args := append([]string{"go-i2ptunnel-config", "--out-format", format, "-o", output}, extra...)
+		var runErr error
+		stderr := captureOutput(t, &os.Stderr, func() {
+			runErr = app.Run(append(args, input))
+		})
+		if runErr != nil {
+			t.Fatalf("convert to %s error = %v", format, runErr)
+		}
+		data, err := os.ReadFile(output)
+		if err != nil {
+			t.Fatalf("output not written: %v", err)
+		}
+		return stderr, string(data)
+	}
+
+	for _, format := range []string{"yaml", "ini", "properties"} {
+		t.Run(format, func(t *testing.T) {
+			stderr, out := run(format)
+			if !strings.Contains(stderr, "WARNING") || !strings.Contains(stderr, "i2cp.leaseSetPrivateKey") {
+				t.Errorf("no private key warning on stderr: %q", stderr)
+			}
+			for _, value := range []string{"ECIES_X25519:3Ab+/cdEF0123456789xyz=", "alice:abc-DEF~0="} {
+				if !strings.Contains(out, value) {
+					t.Errorf("output does not preserve %q exactly:\n%s", value, out)
+				}
+			}
+			config, err := (&Converter{}).ParseInput([]byte(out), format)
+			if err != nil {
+				t.Fatalf("output does not parse: %v", err)
+			}
+			if got := config.I2CP["leaseSetPrivateKey"]; got != "ECIES_X25519:3Ab+/cdEF0123456789xyz=" {
+				t.Errorf("re-parsed leaseSetPrivateKey = %#v", got)
+			}
+		})
+	}
+
+	if stderr, _ := run("yaml", "--no-key-warning"); strings.Contains(stderr, "private key") {
+		t.Errorf("--no-key-warning did not silence the warning: %q", stderr)
+	}
+}
@@ -1,7 +1,12 @@
 package i2pconv
 
 import (
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+
+	"github.com/urfave/cli/v2"
 )
 
 // TestSplitTunnels_INI verifies that a 3-section INI file produces 3 TunnelConfigs.
@@ -123,3 +128,100 @@ port = 4444
 		t.Errorf("expected name 'OnlyTunnel', got %q", configs[0].Name)
 	}
 }
+
+// TestConvertCommand_CountTunnels checks that --count-tunnels reports the number
+// of tunnels in each multi-tunnel format without writing any output.
+func TestConvertCommand_CountTunnels(t *testing.T) {
+	tests := []struct {
+		file    string
+		content string
+		want    string
+	}{
+		{"tunnels.yaml", "tunnels:\n  a:\n    type: httpclient\n    port: 4444\n  b:\n    type: server\n    target: 127.0.0.1:80\n", "2 tunnel(s)"},
+		{"tunnels.conf", "[A]\ntype = http\nport = 4444\n\n[B]\ntype = server\n\n[C]\ntype = socks\nport = 4447\n", "3 tunnel(s)"},
+		{"tunnels.config", "tunnel.0.name=a\ntunnel.0.type=httpclient\ntunnel.1.name=b\ntunnel.1.type=server\ntunnel.2.name=c\ntunnel.2.type=client\ntunnel.3.name=d\ntunnel.3.type=client\n", "4 tunnel(s)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			dir := t.TempDir()
+			inputFile := filepath.Join(dir, tt.file)
+			if err := os.WriteFile(inputFile, []byte(tt.content), 0o644); err != nil {
+				t.Fatalf("setup: %v", err)
+			}
+			app := makeSplitApp()
+			app.Flags = append(app.Flags, &cli.BoolFlag{Name: "count-tunnels"})
+
+			var runErr error
+			out := captureOutput(t, &os.Stdout, func() {
+				runErr = app.Run([]string{"go-i2ptunnel-config", "--count-tunnels", inputFile})
+			})
+			if runErr != nil {
+				t.Fatalf("unexpected error: %v", runErr)
+			}
+			if !strings.Contains(out, tt.want) {
+				t.Errorf("output %q does not contain %q", out, tt.want)
+			}
+			entries, _ := os.ReadDir(dir)
+			if len(entries) != 1 {
+				t.Errorf("--count-tunnels must not write files, found %v", entries)
+			}
+		})
+	}
+}
+
+// TestConvertCommand_CountTunnelsQuiet checks that --count-tunnels --quiet
+// prints just the number of tunnels, here the sections of a three-section INI
+// file, and that --quiet alone is rejected.
+func TestConvertCommand_CountTunnelsQuiet(t *testing.T) {
+	dir := t.TempDir()
+	inputFile := filepath.Join(dir, "tunnels.conf")
+	content := "[A]\ntype = http\nport = 4444\n\n[B]\ntype = server\n\n[C]\ntype = socks\nport = 4447\n"
+	if err := os.WriteFile(inputFile, []byte(content), 0o644); err != nil {
+		t.Fatalf("setup: %v", err)
+	}
+	app := makeSplitApp()
+	app.Flags = append(app.Flags, &cli.BoolFlag{Name: "count-tunnels"}, &cli.BoolFlag{Name: "quiet"})
+
+	var runErr error
+	out := captureOutput(t, &os.Stdout, func() {
+		runErr = app.Run([]string{"go-i2ptunnel-config", "--count-tunnels", "--quiet", inputFile})
+	})
+	if runErr != nil {
+		t.Fatalf("unexpected error: %v", runErr)
+	}
+	if out != "3\n" {
+		t.Errorf("--count-tunnels --quiet output = %q, want %q", out, "3\n")
+	}
+
+	if err := app.Run([]string{"go-i2ptunnel-config", "--quiet", "--dry-run", inputFile}); err == nil || !strings.Contains(err.Error(), "--quiet") {
+		t.Errorf("--quiet without --count-tunnels: got %v, want an error", err)
+	}
+}
+
+// TestConvertCommand_CountOnly checks that --count-only prints the same bare
+// number as --count-tunnels --quiet for a three-section INI file.
+func TestConvertCommand_CountOnly(t *testing.T) {
+	dir := t.TempDir()
+	inputFile := filepath.Join(dir, "tunnels.conf")
+	content := "[A]\ntype = http\nport = 4444\n\n[B]\ntype = server\n\n[C]\ntype = socks\nport = 4447\n"
+	if err := os.WriteFile(inputFile, []byte(content), 0o644); err != nil {
+		t.Fatalf("setup: %v", err)
+	}
+	app := makeSplitApp()
+	app.Flags = append(app.Flags, &cli.BoolFlag{Name: "count-only"})
+
+	var runErr error
+	out := captureOutput(t, &os.Stdout, func() {
+		runErr = app.Run([]string{"go-i2ptunnel-config", "--count-only", inputFile})
+	})
+	if runErr != nil {
+		t.Fatalf("unexpected error: %v", runErr)
+	}
+	if out != "3\n" {
+		t.Errorf("--count-only output = %q, want %q", out, "3\n")
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Errorf("--count-only must not write files, found %v", entries)
+	}
+}
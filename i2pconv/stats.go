@@ -0,0 +1,154 @@
+package i2pconv
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+// FormatPair names the input and output formats of a conversion.
+type FormatPair struct {
+	From string
+	To   string
+}
+
+// ConversionCounts tallies conversions. Attempted can exceed Succeeded plus
+// Failed while conversions are still running.
+type ConversionCounts struct {
+	Attempted int64
+	Succeeded int64
+	Failed    int64
+}
+
+// ConverterStats is a snapshot of the conversions run by Convert on a
+// Converter created with ConverterOptions.CollectStats.
+type ConverterStats struct {
+	ConversionCounts
+	// ByFormat holds the counts of each input and output format pair seen.
+	ByFormat map[FormatPair]ConversionCounts
+}
+
+// conversionCounters is the live, atomically updated form of ConversionCounts.
+type conversionCounters struct {
+	attempted atomic.Int64
+	succeeded atomic.Int64
+	failed    atomic.Int64
+}
+
+// snapshot returns the current counts. Each counter is read atomically, but
+// not all three at once, so a conversion running meanwhile may show in some
+// and not yet in others.
+func (cc *conversionCounters) snapshot() ConversionCounts {
+	return ConversionCounts{
+		Attempted: cc.attempted.Load(),
+		Succeeded: cc.succeeded.Load(),
+		Failed:    cc.failed.Load(),
+	}
+}
+
+// converterStats holds the counters of a Converter. Counters are only added
+// to the pairs map, never removed, so updates need no lock.
+type converterStats struct {
+	total conversionCounters
+	pairs sync.Map // FormatPair -> *conversionCounters
+}
+
+// conversionRecord tracks one conversion between begin and finish.
+type conversionRecord struct {
+	stats *converterStats
+	pair  *conversionCounters
+}
+
+// begin counts a conversion attempt from inFormat to outFormat. It is a no-op
+// on a nil receiver, so converters without stats pay nothing.
+func (s *converterStats) begin(inFormat, outFormat string) conversionRecord {
+	if s == nil {
+		return conversionRecord{}
+	}
+	v, ok := s.pairs.Load(FormatPair{From: inFormat, To: outFormat})
+	if !ok {
+		v, _ = s.pairs.LoadOrStore(FormatPair{From: inFormat, To: outFormat}, &conversionCounters{})
+	}
+	pair := v.(*conversionCounters)
+	s.total.attempted.Add(1)
+	pair.attempted.Add(1)
+	return conversionRecord{stats: s, pair: pair}
+}
+
+// finish counts the conversion as succeeded when err is nil, else as failed.
+func (r conversionRecord) finish(err error) {
+	if r.stats == nil {
+		return
+	}
+	if err != nil {
+		r.stats.total.failed.Add(1)
+		r.pair.failed.Add(1)
+		return
+	}
+	r.stats.total.succeeded.Add(1)
+	r.pair.succeeded.Add(1)
+}
+
+// Stats returns a snapshot of the conversions Convert has run. Converters
+// created without ConverterOptions.CollectStats return zero counts and a nil
+// ByFormat. It is safe to call while conversions run on other goroutines.
+func (c *Converter) Stats() ConverterStats {
+	if c.stats == nil {
+		return ConverterStats{}
+	}
+	snap := ConverterStats{
+		ConversionCounts: c.stats.total.snapshot(),
+		ByFormat:         make(map[FormatPair]ConversionCounts),
+	}
+	c.stats.pairs.Range(func(k, v interface{}) bool {
+		snap.ByFormat[k.(FormatPair)] = v.(*conversionCounters).snapshot()
+		return true
+	})
+	return snap
+}
+
+// configCounts summarises what a parsed tunnel config carries: the number of
+// top-level fields set and the size of each option map. --stats prints it for
+// every input so outliers stand out in a large migration.
+type configCounts struct {
+	Fields   int
+	I2CP     int
+	Tunnel   int
+	Inbound  int
+	Outbound int
+	Unknown  int
+}
+
+// countConfig returns the configCounts of config.
+func countConfig(config *TunnelConfig) configCounts {
+	fields := 0
+	for _, set := range []bool{
+		config.Name != "",
+		config.Type != "",
+		config.Interface != "",
+		config.Port != 0,
+		config.Target != "",
+		config.PersistentKey,
+		config.Description != "",
+		config.Enabled != nil,
+		config.Style != "",
+	} {
+		if set {
+			fields++
+		}
+	}
+	return configCounts{
+		Fields:   fields,
+		I2CP:     len(config.I2CP),
+		Tunnel:   len(config.Tunnel),
+		Inbound:  len(config.Inbound),
+		Outbound: len(config.Outbound),
+		Unknown:  len(config.Unknown),
+	}
+}
+
+// String renders the counts as "9 fields, i2cp 3, options 0, ...".
+func (c configCounts) String() string {
+	return fmt.Sprintf("%d fields, i2cp %d, options %d, inbound %d, outbound %d, unknown %d",
+		c.Fields, c.I2CP, c.Tunnel, c.Inbound, c.Outbound, c.Unknown)
+}
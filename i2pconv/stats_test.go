@@ -0,0 +1,132 @@
+package i2pconv
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/urfave/cli/v2"
+)
+
+// TestConverterStats checks the totals and per-format-pair counts after a mix
+// of successful and failed conversions.
+func TestConverterStats(t *testing.T) {
+	valid := []byte("name=web\ntype=httpclient\nlistenPort=4480\n")
+	invalid := []byte("type=httpclient\nlistenPort=4480\n") // no name
+
+	conv := NewConverterWithOptions(ConverterOptions{CollectStats: true})
+	for _, outFormat := range []string{"yaml", "ini", "yaml"} {
+		if _, err := conv.Convert(valid, "properties", outFormat); err != nil {
+			t.Fatalf("Convert(properties, %s) error = %v", outFormat, err)
+		}
+	}
+	if _, err := conv.Convert(invalid, "properties", "yaml"); err == nil {
+		t.Fatal("Convert() of a tunnel without a name should fail")
+	}
+	if _, err := conv.Convert(valid, "toml", "yaml"); err == nil {
+		t.Fatal("Convert() from an unsupported format should fail")
+	}
+
+	want := ConverterStats{
+		ConversionCounts: ConversionCounts{Attempted: 5, Succeeded: 3, Failed: 2},
+		ByFormat: map[FormatPair]ConversionCounts{
+			{From: "properties", To: "yaml"}: {Attempted: 3, Succeeded: 2, Failed: 1},
+			{From: "properties", To: "ini"}:  {Attempted: 1, Succeeded: 1},
+			{From: "toml", To: "yaml"}:       {Attempted: 1, Failed: 1},
+		},
+	}
+	if got := conv.Stats(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Stats() = %+v, want %+v", got, want)
+	}
+}
+
+// TestConverterStats_Concurrent runs conversions and reads the stats from
+// several goroutines and checks that no count is lost.
+func TestConverterStats_Concurrent(t *testing.T) {
+	input := []byte("[proxy]\ntype = client\nport = 4480\ndestination = example.i2p\n")
+	conv := NewConverterWithOptions(ConverterOptions{CollectStats: true})
+
+	const workers, perWorker = 8, 25
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			outFormat := []string{"yaml", "properties"}[w%2]
+			for i := 0; i < perWorker; i++ {
+				if _, err := conv.Convert(input, "ini", outFormat); err != nil {
+					t.Errorf("Convert() error = %v", err)
+				}
+				conv.Stats()
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	got := conv.Stats()
+	if got.Attempted != workers*perWorker || got.Succeeded != workers*perWorker || got.Failed != 0 {
+		t.Errorf("totals = %+v, want %d succeeded", got.ConversionCounts, workers*perWorker)
+	}
+	half := ConversionCounts{Attempted: workers * perWorker / 2, Succeeded: workers * perWorker / 2}
+	for _, pair := range []FormatPair{{From: "ini", To: "yaml"}, {From: "ini", To: "properties"}} {
+		if got.ByFormat[pair] != half {
+			t.Errorf("ByFormat[%v] = %+v, want %+v", pair, got.ByFormat[pair], half)
+		}
+	}
+}
+
+// TestConverterStats_Disabled checks that a converter without CollectStats
+// reports zero stats.
+func TestConverterStats_Disabled(t *testing.T) {
+	conv := NewConverter(false)
+	if _, err := conv.Convert([]byte("name=web\ntype=httpclient\nlistenPort=4480\n"), "properties", "yaml"); err != nil {
+		t.Fatalf("Convert() error = %v", err)
+	}
+	if got := conv.Stats(); !reflect.DeepEqual(got, ConverterStats{}) {
+		t.Errorf("Stats() without CollectStats = %+v, want zero", got)
+	}
+}
+
+// TestConvertCommand_Stats checks the field and option counts --stats prints on
+// stderr for each input.
+func TestConvertCommand_Stats(t *testing.T) {
+	dir := t.TempDir()
+	web := filepath.Join(dir, "web.properties")
+	input := "name=web\ntype=httpclient\nlistenPort=4480\noption.i2cp.reduceOnIdle=true\noption.i2cp.reduceIdleTime=900000\noption.i2cp.leaseSetEncType=4,0\noption.inbound.length=2\n"
+	if err := os.WriteFile(web, []byte(input), 0o644); err != nil {
+		t.Fatalf("setup: %v", err)
+	}
+	bare := filepath.Join(dir, "bare.properties")
+	if err := os.WriteFile(bare, []byte("name=bare\ntype=socks\nlistenPort=4481\n"), 0o644); err != nil {
+		t.Fatalf("setup: %v", err)
+	}
+	run := func(args ...string) string {
+		t.Helper()
+		app := makeStdinApp()
+		app.Flags = append(app.Flags, &cli.BoolFlag{Name: "stats"})
+		var runErr error
+		stderr := captureOutput(t, &os.Stderr, func() {
+			runErr = app.Run(append([]string{"go-i2ptunnel-config", "--stats", "--dry-run"}, args...))
+		})
+		if runErr != nil {
+			t.Fatalf("run error = %v", runErr)
+		}
+		return stderr
+	}
+
+	want := "Stats for '" + web + "': 3 fields, i2cp 3, options 0, inbound 1, outbound 0, unknown 0\n"
+	if got := run(web); !strings.Contains(got, want) {
+		t.Errorf("single-file stats = %q, want %q", got, want)
+	}
+
+	got := run("--batch", filepath.Join(dir, "*.properties"))
+	if !strings.Contains(got, want) {
+		t.Errorf("batch stats = %q, want %q", got, want)
+	}
+	if !strings.Contains(got, "Stats for '"+bare+"': 3 fields, i2cp 0, options 0, inbound 0, outbound 0, unknown 0\n") {
+		t.Errorf("batch stats lack the second file: %q", got)
+	}
+}
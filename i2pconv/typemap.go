@@ -1,24 +1,155 @@
 package i2pconv
 
-import "strings"
+import (
+	"fmt"
+	"strings"
+)
 
-// i2pdTypeAliases maps i2pd-style tunnel type names to their canonical
-// Java I2P / go-i2p equivalents. i2pd uses shorter names (e.g., "http")
-// while Java I2P uses descriptive names (e.g., "httpclient"). The canonical
-// name is what all internal TunnelConfig representations and output generators
-// use, ensuring lossless round-trips between formats.
-var i2pdTypeAliases = map[string]string{
-	"http":      "httpclient",
-	"socks":     "sockstunnel",
-	"udptunnel": "client",
+// tunnelTypeNames pairs the Java I2P name of every tunnel type with the name
+// i2pd uses for it. i2pd uses shorter names for the client proxies (e.g.,
+// "http") where Java I2P uses descriptive ones (e.g., "httpclient"); the
+// server variants, and the types only one router has, are named the same by
+// both. The Java I2P name is the canonical one all internal TunnelConfig
+// representations use.
+var tunnelTypeNames = []tunnelTypeName{
+	{"httpclient", "http"},
+	{"sockstunnel", "socks"},
+	{"ircclient", "irc"},
+	{"client", "client"},
+	{"streamrclient", "streamrclient"},
+	{"socksirc", "socksirc"},
+	{"server", "server"},
+	{"httpserver", "httpserver"},
+	{"ircserver", "ircserver"},
+	{"streamrserver", "streamrserver"},
+	{"httpbidirserver", "httpbidirserver"},
+	{"socksserver", "socksserver"},
+	{"udpclient", "udpclient"},
+	{"udpserver", "udpserver"},
+}
+
+// formatTypeDialects names the router whose tunnel type names each format
+// uses: Java I2P ("java") or i2pd ("i2pd").
+var formatTypeDialects = map[string]string{
+	"properties": "java",
+	"yaml":       "java",
+	"ini":        "i2pd",
+}
+
+// tunnelTypeName is one row of tunnelTypeNames.
+type tunnelTypeName struct{ java, i2pd string }
+
+// in returns the name the router dialect ("java" or "i2pd") uses, or false
+// for any other dialect.
+func (n tunnelTypeName) in(dialect string) (string, bool) {
+	switch dialect {
+	case "java":
+		return n.java, true
+	case "i2pd":
+		return n.i2pd, true
+	}
+	return "", false
+}
+
+// translateTypeName returns the name dialect to uses for the tunnel type that
+// dialect from calls t, matching t without regard to case. The second result
+// is false when t is not a type of from or to is not a dialect.
+func translateTypeName(t, from, to string) (string, bool) {
+	for _, names := range tunnelTypeNames {
+		if name, ok := names.in(from); ok && strings.EqualFold(t, name) {
+			return names.in(to)
+		}
+	}
+	return t, false
 }
 
 // NormalizeTypeName converts an i2pd alias type name to its canonical equivalent.
 // If the name is already canonical (or unknown), it is returned unchanged.
+// i2pd's "udptunnel" alias normalizes to "client"; the INI generator writes it
+// back for a client with the DATAGRAM style.
 func NormalizeTypeName(t string) string {
 	lower := strings.ToLower(t)
-	if canonical, ok := i2pdTypeAliases[lower]; ok {
+	if lower == "udptunnel" {
+		return string(TunnelTypeClient)
+	}
+	if canonical, ok := translateTypeName(lower, "i2pd", "java"); ok {
 		return canonical
 	}
 	return lower
 }
+
+// formatTypeName returns the tunnel type name to write for format. The type is
+// normalized first, so an i2pd alias is translated to the Java I2P name for
+// properties output and vice versa. Types not in tunnelTypeNames are passed
+// through unchanged.
+func formatTypeName(t, format string) string {
+	if name, ok := translateTypeName(NormalizeTypeName(t), "java", formatTypeDialects[format]); ok {
+		return name
+	}
+	return t
+}
+
+// canonicalTypeModes are the values accepted by ConverterOptions.CanonicalTypes.
+// The empty string keeps each format's own type names.
+var canonicalTypeModes = map[string]bool{"": true, "java": true, "i2pd": true, "none": true}
+
+// typeName returns the tunnel type name c writes for format. By default this
+// is formatTypeName; a converter with CanonicalTypes set instead writes the
+// Java I2P name ("java"), the i2pd name ("i2pd"), or the type as parsed
+// ("none"), whatever the output format.
+func (c *Converter) typeName(t, format string) string {
+	switch c.canonicalTypes {
+	case "java":
+		return NormalizeTypeName(t)
+	case "i2pd":
+		canonical := NormalizeTypeName(t)
+		if name, ok := translateTypeName(canonical, "java", "i2pd"); ok {
+			return name
+		}
+		return canonical
+	case "none":
+		return t
+	default:
+		return formatTypeName(t, format)
+	}
+}
+
+// formatSupportedTypes lists, per output format, the canonical tunnel types
+// the target router understands. A format without an entry (yaml) accepts
+// every type. Types that appear in no list are unknown to this registry and
+// are never reported as unsupported.
+var formatSupportedTypes = map[string]map[TunnelType]bool{
+	// Java I2P supports every type the validator defines.
+	"properties": {
+		TunnelTypeHTTPClient: true, TunnelTypeSOCKS: true, TunnelTypeSOCKSServer: true,
+		TunnelTypeIRCClient: true, TunnelTypeClient: true, TunnelTypeStreamClient: true,
+		TunnelTypeHTTPServer: true, TunnelTypeServer: true, TunnelTypeIRCServer: true,
+		TunnelTypeStreamServer: true, TunnelTypeHTTPBidir: true, TunnelTypeSOCKSIRC: true,
+	},
+	// i2pd has no bidirectional HTTP, streamr, or SOCKS-IRC tunnels, but
+	// adds UDP tunnels that Java I2P lacks.
+	"ini": {
+		TunnelTypeHTTPClient: true, TunnelTypeSOCKS: true, TunnelTypeIRCClient: true, TunnelTypeClient: true,
+		TunnelTypeHTTPServer: true, TunnelTypeServer: true, TunnelTypeIRCServer: true,
+		"udpclient": true, "udpserver": true,
+	},
+}
+
+// checkTypeSupported returns an error when the tunnel type of config is known
+// to this registry but is not supported by the router behind format.
+func checkTypeSupported(config *TunnelConfig, format string) error {
+	supported, ok := formatSupportedTypes[format]
+	if !ok {
+		return nil
+	}
+	t := TunnelType(NormalizeTypeName(config.Type))
+	if supported[t] {
+		return nil
+	}
+	for _, types := range formatSupportedTypes {
+		if types[t] {
+			return fmt.Errorf("tunnel type '%s' is not supported by the %s format's router", config.Type, format)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,177 @@
+package i2pconv
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/urfave/cli/v2"
+)
+
+// TestCheckTypeSupported verifies the per-format supported-types registry.
+func TestCheckTypeSupported(t *testing.T) {
+	tests := []struct {
+		tunnelType string
+		format     string
+		wantErr    bool
+	}{
+		{"httpbidirserver", "ini", true},
+		{"streamrserver", "ini", true},
+		{"httpclient", "ini", false},
+		{"http", "ini", false},
+		{"httpbidirserver", "properties", false},
+		{"udpserver", "properties", true},
+		{"udpserver", "ini", false},
+		{"httpbidirserver", "yaml", false},
+		{"somethingnew", "ini", false},
+	}
+	for _, tt := range tests {
+		err := checkTypeSupported(&TunnelConfig{Type: tt.tunnelType}, tt.format)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("checkTypeSupported(%q, %q) error = %v, wantErr %v", tt.tunnelType, tt.format, err, tt.wantErr)
+		}
+		if err != nil && !strings.Contains(err.Error(), tt.tunnelType) {
+			t.Errorf("error %q should name the type %q", err, tt.tunnelType)
+		}
+	}
+}
+
+// TestProcessSingleFile_UnsupportedTargetType verifies that converting a
+// Java-only type to i2pd warns in normal mode and fails in strict mode.
+func TestProcessSingleFile_UnsupportedTargetType(t *testing.T) {
+	dir := t.TempDir()
+	inputFile := filepath.Join(dir, "bidir.properties")
+	content := "name=bidir\ntype=httpbidirserver\ntargetHost=127.0.0.1\ntargetPort=8080\nlistenPort=8888\n"
+	if err := os.WriteFile(inputFile, []byte(content), 0o644); err != nil {
+		t.Fatalf("setup: %v", err)
+	}
+	opts := processOptions{outputFormat: "ini", dryRun: true}
+
+	var err error
+	stderr := captureOutput(t, &os.Stderr, func() {
+		_, err = processSingleFile(inputFile, "", opts, &Converter{})
+	})
+	if err != nil {
+		t.Fatalf("non-strict conversion should succeed with a warning, got: %v", err)
+	}
+	if !strings.Contains(stderr, "tunnel type 'httpbidirserver' is not supported by the ini format") {
+		t.Errorf("expected unsupported-type warning on stderr, got: %q", stderr)
+	}
+
+	_, err = processSingleFile(inputFile, "", opts, &Converter{strict: true})
+	if err == nil || !strings.Contains(err.Error(), "not supported") {
+		t.Errorf("strict conversion should fail with unsupported type, got: %v", err)
+	}
+}
+
+// TestTypeNameTranslation checks that the tunnel type is written with the
+// target router's name in both directions instead of being copied verbatim.
+func TestTypeNameTranslation(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		inFmt    string
+		outFmt   string
+		wantLine string
+	}{
+		{"httpclient properties to ini", "name=web\ntype=httpclient\nlistenPort=4444\n", "properties", "ini", "type = http\n"},
+		{"sockstunnel properties to ini", "name=socks\ntype=sockstunnel\nlistenPort=4447\n", "properties", "ini", "type = socks\n"},
+		{"client properties to ini", "name=c\ntype=client\nlistenPort=4448\ntargetDestination=a.i2p\n", "properties", "ini", "type = client\n"},
+		{"server properties to ini", "name=s\ntype=server\ntargetHost=127.0.0.1\n", "properties", "ini", "type = server\n"},
+		{"http ini to properties", "[web]\ntype = http\nport = 4444\n", "ini", "properties", "type=httpclient\n"},
+		{"socks ini to properties", "[socks]\ntype = socks\nport = 4447\n", "ini", "properties", "type=sockstunnel\n"},
+		{"http ini to ini", "[web]\ntype = http\nport = 4444\n", "ini", "ini", "type = http\n"},
+		{"socks ini to yaml", "[socks]\ntype = socks\nport = 4447\n", "ini", "yaml", "type: sockstunnel\n"},
+		{"ircclient properties to ini", "name=irc\ntype=ircclient\nlistenPort=6668\ntargetDestination=irc.postman.i2p\n", "properties", "ini", "type = irc\n"},
+		{"irc ini to properties", "[irc]\ntype = irc\nport = 6668\ndestination = irc.postman.i2p\n", "ini", "properties", "type=ircclient\n"},
+		{"httpserver properties to ini", "name=web\ntype=httpserver\ntargetHost=127.0.0.1\ntargetPort=8080\n", "properties", "ini", "type = httpserver\n"},
+		{"ircserver ini to properties", "[ircd]\ntype = ircserver\naddress = 127.0.0.1\nport = 6667\n", "ini", "properties", "type=ircserver\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := (&Converter{}).Convert([]byte(tt.input), tt.inFmt, tt.outFmt)
+			if err != nil {
+				t.Fatalf("Convert() error = %v", err)
+			}
+			if !strings.Contains(string(out), tt.wantLine) {
+				t.Errorf("output missing %q:\n%s", tt.wantLine, out)
+			}
+		})
+	}
+
+	for _, names := range tunnelTypeNames {
+		if got := formatTypeName(names.java, "ini"); got != names.i2pd {
+			t.Errorf("formatTypeName(%q, ini) = %q, want %q", names.java, got, names.i2pd)
+		}
+		if got := formatTypeName(names.i2pd, "properties"); got != names.java {
+			t.Errorf("formatTypeName(%q, properties) = %q, want %q", names.i2pd, got, names.java)
+		}
+	}
+	if got := formatTypeName("CustomType", "ini"); got != "CustomType" {
+		t.Errorf("unknown types should pass through unchanged, got %q", got)
+	}
+}
+
+// TestCanonicalTypes checks that CanonicalTypes overrides the per-format type
+// names for a converted httpclient tunnel.
+func TestCanonicalTypes(t *testing.T) {
+	input := "name=web\ntype=httpclient\nlistenPort=4444\n"
+	tests := []struct {
+		mode     string
+		outFmt   string
+		wantLine string
+	}{
+		{"", "ini", "type = http\n"},
+		{"", "properties", "type=httpclient\n"},
+		{"java", "ini", "type = httpclient\n"},
+		{"java", "properties", "type=httpclient\n"},
+		{"java", "yaml", "type: httpclient\n"},
+		{"i2pd", "ini", "type = http\n"},
+		{"i2pd", "properties", "type=http\n"},
+		{"i2pd", "yaml", "type: http\n"},
+		{"none", "ini", "type = httpclient\n"},
+		{"none", "properties", "type=httpclient\n"},
+		{"none", "yaml", "type: httpclient\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mode+" to "+tt.outFmt, func(t *testing.T) {
+			c := NewConverterWithOptions(ConverterOptions{CanonicalTypes: tt.mode})
+			out, err := c.Convert([]byte(input), "properties", tt.outFmt)
+			if err != nil {
+				t.Fatalf("Convert() error = %v", err)
+			}
+			if !strings.Contains(string(out), tt.wantLine) {
+				t.Errorf("output missing %q:\n%s", tt.wantLine, out)
+			}
+		})
+	}
+
+	irc := "name=irc\ntype=ircclient\nlistenPort=6668\ntargetDestination=irc.postman.i2p\n"
+	out, err := NewConverterWithOptions(ConverterOptions{CanonicalTypes: "i2pd"}).Convert([]byte(irc), "properties", "yaml")
+	if err != nil {
+		t.Fatalf("Convert() error = %v", err)
+	}
+	if !strings.Contains(string(out), "type: irc\n") {
+		t.Errorf("i2pd canonical types should write ircclient as irc:\n%s", out)
+	}
+}
+
+// TestConvertCommand_CanonicalTypesInvalid checks that an unknown
+// --canonical-types value is rejected before anything is converted.
+func TestConvertCommand_CanonicalTypesInvalid(t *testing.T) {
+	dir := t.TempDir()
+	inputFile := filepath.Join(dir, "web.properties")
+	if err := os.WriteFile(inputFile, []byte("name=web\ntype=httpclient\nlistenPort=4444\n"), 0o644); err != nil {
+		t.Fatalf("setup: %v", err)
+	}
+	app := makeStdinApp()
+	app.Flags = append(app.Flags, &cli.StringFlag{Name: "canonical-types"})
+
+	err := app.Run([]string{"test", "--canonical-types", "go", "--dry-run", inputFile})
+	if err == nil || !strings.Contains(err.Error(), "--canonical-types") {
+		t.Errorf("expected --canonical-types error, got: %v", err)
+	}
+}
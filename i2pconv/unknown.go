@@ -0,0 +1,17 @@
+package i2pconv
+
+import (
+	"fmt"
+	"strings"
+)
+
+// checkUnknownPassthrough returns a warning when config carries unrecognised
+// keys into a different format. The keys are still written verbatim, but the
+// target router may not understand them. Same-format conversions are silent.
+func checkUnknownPassthrough(config *TunnelConfig, inFormat, outFormat string) error {
+	if len(config.Unknown) == 0 || inFormat == outFormat {
+		return nil
+	}
+	return fmt.Errorf("%d unrecognised %s key(s) copied unchanged into %s output: %s",
+		len(config.Unknown), inFormat, outFormat, strings.Join(sortedKeys(config.Unknown), ", "))
+}
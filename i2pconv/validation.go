@@ -1,8 +1,10 @@
 package i2pconv
 
 import (
+	"errors"
 	"fmt"
 	"net"
+	"slices"
 	"strconv"
 	"strings"
 )
@@ -30,12 +32,39 @@ const (
 	TunnelTypeSOCKSIRC  TunnelType = "socksirc"
 )
 
+// IsServer reports whether t exposes a local service to I2P. A server's
+// target is the host of that service, while its destination comes from its
+// keys. i2pd alias names and any letter case are accepted.
+func (t TunnelType) IsServer() bool {
+	switch TunnelType(NormalizeTypeName(string(t))) {
+	case TunnelTypeHTTPServer, TunnelTypeServer, TunnelTypeIRCServer, TunnelTypeStreamServer,
+		TunnelTypeHTTPBidir, TunnelTypeSOCKSServer:
+		return true
+	}
+	return false
+}
+
+// IsClient reports whether t accepts local connections and forwards them into
+// I2P. A client's target is an I2P destination and its interface the local
+// bind address. i2pd alias names and any letter case are accepted.
+func (t TunnelType) IsClient() bool {
+	switch TunnelType(NormalizeTypeName(string(t))) {
+	case TunnelTypeHTTPClient, TunnelTypeSOCKS, TunnelTypeIRCClient, TunnelTypeClient,
+		TunnelTypeStreamClient, TunnelTypeSOCKSIRC:
+		return true
+	}
+	return false
+}
+
 // ValidationRule defines a validation constraint
 type ValidationRule struct {
 	Field       string
 	Required    bool
 	Validator   func(config *TunnelConfig) error
 	Description string
+	// Unused marks a field the type accepts but ignores. In strict mode a
+	// value set on such a field produces an advisory warning, not an error.
+	Unused bool
 }
 
 // TunnelTypeSpec defines validation rules for a specific tunnel type
@@ -45,11 +74,80 @@ type TunnelTypeSpec struct {
 	Rules       []ValidationRule
 }
 
+// Severity classifies a ValidationIssue.
+type Severity int
+
+const (
+	// SeverityWarning marks a suspicious value that does not stop conversion.
+	SeverityWarning Severity = iota
+	// SeverityError marks a value that makes the configuration invalid.
+	SeverityError
+)
+
+// String returns "warning" or "error".
+func (s Severity) String() string {
+	if s == SeverityError {
+		return "error"
+	}
+	return "warning"
+}
+
+// ValidationIssue is one problem found by Validate.
+type ValidationIssue struct {
+	Severity Severity
+	Message  string
+}
+
+// String renders the issue as "severity: message".
+func (i ValidationIssue) String() string {
+	return i.Severity.String() + ": " + i.Message
+}
+
 // ValidationContext holds validation configuration
 type ValidationContext struct {
 	Strict      bool
 	Format      string
 	TunnelSpecs map[TunnelType]TunnelTypeSpec
+	// WarningsAsErrors makes Validate fail on the first warning, as strict
+	// mode did before warnings were separated from errors.
+	WarningsAsErrors bool
+	// Issues collects every warning found by Validate, followed by the error
+	// it returned, if any.
+	Issues []ValidationIssue
+}
+
+// validationWarning is returned by a validator for a value that is suspicious
+// but usable. applyRule records it as a warning instead of failing.
+type validationWarning struct {
+	msg string
+}
+
+func (w *validationWarning) Error() string { return w.msg }
+
+// warningf returns a validationWarning with a formatted message.
+func warningf(format string, args ...interface{}) error {
+	return &validationWarning{msg: fmt.Sprintf(format, args...)}
+}
+
+// Warnings returns the messages of the warnings found by Validate.
+func (v *ValidationContext) Warnings() []string {
+	var warnings []string
+	for _, issue := range v.Issues {
+		if issue.Severity == SeverityWarning {
+			warnings = append(warnings, issue.Message)
+		}
+	}
+	return warnings
+}
+
+// warn records msg as a warning, or returns it as an error when
+// WarningsAsErrors is set.
+func (v *ValidationContext) warn(msg string) error {
+	if v.WarningsAsErrors {
+		return errors.New(msg)
+	}
+	v.Issues = append(v.Issues, ValidationIssue{Severity: SeverityWarning, Message: msg})
+	return nil
 }
 
 // NewValidationContext creates a new validation context with predefined tunnel type specifications
@@ -71,6 +169,7 @@ func (v *ValidationContext) initializeTunnelSpecs() {
 		Description: "HTTP proxy client tunnel",
 		Rules: []ValidationRule{
 			{Field: "port", Required: true, Validator: v.validatePort, Description: "Local port is required for HTTP client"},
+			{Field: "target", Required: false, Validator: v.validateClientDestination, Description: "Target must be an I2P destination"},
 			{Field: "interface", Required: false, Validator: v.validateInterface, Description: "Interface must be valid if specified"},
 			{Field: "target", Required: false, Validator: v.validateTarget, Description: "Target must be valid if specified"},
 		},
@@ -82,7 +181,7 @@ func (v *ValidationContext) initializeTunnelSpecs() {
 		Description: "HTTP server tunnel",
 		Rules: []ValidationRule{
 			{Field: "target", Required: true, Validator: v.validateTarget, Description: "Target is required for HTTP server"},
-			{Field: "port", Required: false, Validator: v.validatePort, Description: "Port must be valid if specified"},
+			{Field: "port", Required: false, Validator: v.validatePort, Description: "Port must be valid if specified", Unused: true},
 			{Field: "interface", Required: false, Validator: v.validateInterface, Description: "Interface must be valid if specified"},
 		},
 	}
@@ -109,6 +208,7 @@ func (v *ValidationContext) initializeTunnelSpecs() {
 		Description: "Generic client tunnel",
 		Rules: []ValidationRule{
 			{Field: "port", Required: true, Validator: v.validatePort, Description: "Local port is required for client tunnel"},
+			{Field: "target", Required: false, Validator: v.validateClientDestination, Description: "Target must be an I2P destination"},
 			{Field: "target", Required: false, Validator: v.validateTarget, Description: "Target must be valid if specified"},
 			{Field: "interface", Required: false, Validator: v.validateInterface, Description: "Interface must be valid if specified"},
 		},
@@ -120,7 +220,7 @@ func (v *ValidationContext) initializeTunnelSpecs() {
 		Description: "Generic server tunnel",
 		Rules: []ValidationRule{
 			{Field: "target", Required: true, Validator: v.validateTarget, Description: "Target is required for server tunnel"},
-			{Field: "port", Required: false, Validator: v.validatePort, Description: "Port must be valid if specified"},
+			{Field: "port", Required: false, Validator: v.validatePort, Description: "Port must be valid if specified", Unused: true},
 			{Field: "interface", Required: false, Validator: v.validateInterface, Description: "Interface must be valid if specified"},
 		},
 	}
@@ -131,6 +231,7 @@ func (v *ValidationContext) initializeTunnelSpecs() {
 		Description: "IRC client tunnel",
 		Rules: []ValidationRule{
 			{Field: "port", Required: true, Validator: v.validatePort, Description: "Local port is required for IRC client"},
+			{Field: "target", Required: false, Validator: v.validateClientDestination, Description: "Target must be an I2P destination"},
 			{Field: "interface", Required: false, Validator: v.validateInterface, Description: "Interface must be valid if specified"},
 		},
 	}
@@ -141,7 +242,7 @@ func (v *ValidationContext) initializeTunnelSpecs() {
 		Description: "IRC server tunnel",
 		Rules: []ValidationRule{
 			{Field: "target", Required: true, Validator: v.validateTarget, Description: "Target is required for IRC server"},
-			{Field: "port", Required: false, Validator: v.validatePort, Description: "Port must be valid if specified"},
+			{Field: "port", Required: false, Validator: v.validatePort, Description: "Port must be valid if specified", Unused: true},
 			{Field: "interface", Required: false, Validator: v.validateInterface, Description: "Interface must be valid if specified"},
 		},
 	}
@@ -188,6 +289,18 @@ func (v *ValidationContext) initializeTunnelSpecs() {
 		},
 	}
 
+	// Every server type checks its target the same way: it names the local
+	// service, so it needs a port and must not be an I2P destination.
+	for name, spec := range v.TunnelSpecs {
+		if name.IsServer() {
+			spec.Rules = append(spec.Rules[:len(spec.Rules):len(spec.Rules)],
+				ValidationRule{Field: "target", Required: false, Validator: v.validateServerTargetPort, Description: "Target must name the local service port"},
+				ValidationRule{Field: "target", Required: false, Validator: v.validateServerKeyfile, Description: "Target must not conflict with the keys file"},
+			)
+			v.TunnelSpecs[name] = spec
+		}
+	}
+
 	// i2pd alias types - map to the same specs as their canonical counterparts.
 	// i2pd uses "http" for httpclient and "socks" for sockstunnel.
 	// NormalizeTypeName handles these at parse time, but specs are also
@@ -205,11 +318,28 @@ func (v *ValidationContext) initializeTunnelSpecs() {
 	v.TunnelSpecs["udptunnel"] = udpAlias
 }
 
-// Validate validates a tunnel configuration according to its type and format
+// Validate validates a tunnel configuration according to its type and format.
+// It runs every check instead of stopping at the first failure and returns
+// all failures joined with errors.Join, or nil. Warnings are recorded in Issues
+// and do not fail validation unless WarningsAsErrors is set; each returned
+// error is recorded there too.
 func (v *ValidationContext) Validate(config *TunnelConfig) error {
+	errs := v.validate(config)
+	for _, err := range errs {
+		v.Issues = append(v.Issues, ValidationIssue{Severity: SeverityError, Message: err.Error()})
+	}
+	return errors.Join(errs...)
+}
+
+// validate runs the validation steps and returns every error found.
+func (v *ValidationContext) validate(config *TunnelConfig) []error {
 	// Basic validation - name and type are always required
-	if err := v.validateBasicFields(config); err != nil {
-		return err
+	errs := v.validateBasicFields(config)
+	if err := v.validateEncoding(config); err != nil {
+		errs = append(errs, err)
+	}
+	if config.Type == "" {
+		return errs
 	}
 
 	// Type-specific validation
@@ -217,45 +347,119 @@ func (v *ValidationContext) Validate(config *TunnelConfig) error {
 	spec, exists := v.TunnelSpecs[tunnelType]
 	if !exists {
 		if v.Strict {
-			return fmt.Errorf("unknown tunnel type: %s", config.Type)
+			errs = append(errs, fmt.Errorf("unknown tunnel type: %s", config.Type))
 		}
 		// In non-strict mode, only do basic validation for unknown types
-		return nil
+		return errs
 	}
 
 	// Apply type-specific rules
 	for _, rule := range spec.Rules {
 		if err := v.applyRule(config, rule); err != nil {
-			return err
+			errs = append(errs, err)
+		}
+	}
+
+	if v.Strict {
+		if err := validateCryptoOptions(config); err != nil {
+			errs = append(errs, err)
+		}
+		if err := v.validateConnectionLimits(config); err != nil {
+			errs = append(errs, err)
+		}
+		if err := validateTunnelPoolOptions(config); err != nil {
+			errs = append(errs, err)
+		}
+		if err := v.validateI2CPOptionNames(config); err != nil {
+			errs = append(errs, err)
+		}
+		if err := validateLeaseSetEncType(config); err != nil {
+			errs = append(errs, err)
 		}
 	}
 
 	// Format-specific validation
 	if err := v.validateFormatSpecific(config); err != nil {
-		return err
+		errs = append(errs, err)
 	}
 
-	return nil
+	return errs
 }
 
 // validateBasicFields performs basic validation that applies to all tunnel types
-func (v *ValidationContext) validateBasicFields(config *TunnelConfig) error {
+func (v *ValidationContext) validateBasicFields(config *TunnelConfig) []error {
+	var errs []error
 	if config.Name == "" {
-		return fmt.Errorf("tunnel name is required")
+		errs = append(errs, fmt.Errorf("tunnel name is required"))
 	}
 
 	if config.Type == "" {
-		return fmt.Errorf("tunnel type is required")
+		errs = append(errs, fmt.Errorf("tunnel type is required"))
+	}
+
+	if config.Style != "" && !slices.Contains(samStyles, strings.ToUpper(config.Style)) {
+		errs = append(errs, fmt.Errorf("style '%s' is not a SAM session style (use %s)", config.Style, strings.Join(samStyles, ", ")))
 	}
 
 	// Validate name doesn't contain problematic characters
-	if strings.ContainsAny(config.Name, " \t\n\r=[]") {
-		return fmt.Errorf("tunnel name '%s' contains invalid characters (spaces, tabs, newlines, equals, or brackets)", config.Name)
+	if err := checkTunnelName(config.Name); err != nil {
+		errs = append(errs, err)
 	}
 
+	return errs
+}
+
+// checkTunnelName returns an error when name contains a character that would
+// break the INI section header or properties key it is written to.
+func checkTunnelName(name string) error {
+	if strings.ContainsAny(name, " \t\n\r=[]") {
+		return fmt.Errorf("tunnel name '%s' contains invalid characters (spaces, tabs, newlines, equals, or brackets)", name)
+	}
 	return nil
 }
 
+// validateEncoding warns about every field whose key or value contains the
+// Unicode replacement character U+FFFD, which a decoder substitutes for bytes
+// it cannot read. It usually means a Latin-1 file was read as UTF-8.
+func (v *ValidationContext) validateEncoding(config *TunnelConfig) error {
+	const replacement = "\uFFFD"
+	var fields []string
+	scalars := []struct{ path, value string }{
+		{"name", config.Name},
+		{"type", config.Type},
+		{"interface", config.Interface},
+		{"target", config.Target},
+		{"description", config.Description},
+		{"style", config.Style},
+	}
+	for _, f := range scalars {
+		if strings.Contains(f.value, replacement) {
+			fields = append(fields, f.path)
+		}
+	}
+	sections := []struct {
+		name string
+		m    map[string]interface{}
+	}{
+		{"i2cp", config.I2CP},
+		{"options", tunnelAndUnknown(config)},
+		{"inbound", config.Inbound},
+		{"outbound", config.Outbound},
+	}
+	for _, sec := range sections {
+		for _, k := range sortedKeys(sec.m) {
+			if strings.Contains(k, replacement) || strings.Contains(formatPropertyValue(sec.m[k]), replacement) {
+				fields = append(fields, sec.name+"."+k)
+			}
+		}
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+	return v.warn(fmt.Sprintf("replacement character U+FFFD in %s; the input was probably read with the wrong encoding (e.g. Latin-1 as UTF-8)",
+		strings.Join(fields, ", ")))
+}
+
 // applyRule applies a specific validation rule to the configuration
 func (v *ValidationContext) applyRule(config *TunnelConfig, rule ValidationRule) error {
 	// Check if field is required but missing
@@ -276,9 +480,22 @@ func (v *ValidationContext) applyRule(config *TunnelConfig, rule ValidationRule)
 		}
 	}
 
+	if rule.Unused && v.Strict {
+		if reason, unused := fieldHasNoEffect(config, rule.Field); unused {
+			if err := v.warn(fmt.Sprintf("%s tunnel '%s' sets %s, which has no effect (%s)",
+				config.Type, config.Name, rule.Field, reason)); err != nil {
+				return err
+			}
+		}
+	}
+
 	// Apply field-specific validation if field has a value
 	if rule.Validator != nil {
 		if err := rule.Validator(config); err != nil {
+			var w *validationWarning
+			if errors.As(err, &w) {
+				return v.warn(fmt.Sprintf("%s: %s", rule.Description, w.msg))
+			}
 			return fmt.Errorf("%s: %w", rule.Description, err)
 		}
 	}
@@ -286,6 +503,19 @@ func (v *ValidationContext) applyRule(config *TunnelConfig, rule ValidationRule)
 	return nil
 }
 
+// fieldHasNoEffect reports whether field holds a value that an Unused rule
+// should flag, and why. A port only goes unused when the target carries its
+// own port; i2pd server tunnels otherwise take the service port from "port".
+func fieldHasNoEffect(config *TunnelConfig, field string) (string, bool) {
+	switch field {
+	case "port":
+		if config.Port > 0 && strings.Count(config.Target, ":") == 1 && !strings.HasSuffix(config.Target, ":") {
+			return fmt.Sprintf("target '%s' already includes a port", config.Target), true
+		}
+	}
+	return "", false
+}
+
 // validatePort validates that the port is in a valid range
 func (v *ValidationContext) validatePort(config *TunnelConfig) error {
 	if config.Port <= 0 {
@@ -298,7 +528,17 @@ func (v *ValidationContext) validatePort(config *TunnelConfig) error {
 
 	// In strict mode, warn about privileged ports
 	if v.Strict && config.Port < 1024 {
-		return fmt.Errorf("port %d is in privileged range (1-1023), may require root privileges", config.Port)
+		return warningf("port %d is in privileged range (1-1023), may require root privileges", config.Port)
+	}
+
+	// In strict mode, warn about ports the router's own services listen on,
+	// unless the tunnel is that service, as an httpclient on 4444 is
+	service, ok := knownRouterPorts[config.Port]
+	if owner, isOwner := routerPortOwners[config.Port]; isOwner && TunnelType(config.Type) == owner {
+		ok = false
+	}
+	if v.Strict && ok {
+		return warningf("port %d is the default %s port and likely conflicts with the router", config.Port, service)
 	}
 
 	return nil
@@ -325,7 +565,7 @@ func (v *ValidationContext) validateInterface(config *TunnelConfig) error {
 
 	// In strict mode, be more restrictive
 	if v.Strict {
-		return fmt.Errorf("interface '%s' should be a valid IP address or localhost", config.Interface)
+		return warningf("interface '%s' should be a valid IP address or localhost", config.Interface)
 	}
 
 	// In non-strict mode, allow any reasonable interface name
@@ -377,6 +617,185 @@ func (v *ValidationContext) validateTarget(config *TunnelConfig) error {
 	return nil
 }
 
+// validateServerTargetPort checks, in strict mode, that a server tunnel says
+// which port the local service listens on. The port may come from the target
+// ("host:port"), from targetPort (Java I2P), or from the tunnel port (i2pd,
+// where "port" is the service port of a server tunnel).
+func (v *ValidationContext) validateServerTargetPort(config *TunnelConfig) error {
+	if !v.Strict || config.Target == "" {
+		return nil
+	}
+	if config.Port > 0 {
+		return nil
+	}
+	// A bare IPv6 address such as "::1" has colons but no port
+	if _, port, err := net.SplitHostPort(config.Target); err == nil && port != "" {
+		return nil
+	}
+	if _, ok := config.Tunnel["targetPort"]; ok {
+		return nil
+	}
+	return fmt.Errorf("target '%s' has no port; use 'host:port' or set the service port", config.Target)
+}
+
+// validateServerKeyfile checks, in strict mode, that a persistent server
+// tunnel does not also name an I2P destination as its target. The keys file
+// defines the server's destination; the target is the local service.
+func (v *ValidationContext) validateServerKeyfile(config *TunnelConfig) error {
+	if !v.Strict || !config.PersistentKey || config.Target == "" {
+		return nil
+	}
+	if isLocalAddress(config.Target) || !isI2PDestination(config.Target) {
+		return nil
+	}
+	keys := "the persistent keys"
+	if keyfile, ok := config.Tunnel["keyfile"]; ok {
+		keys = fmt.Sprintf("keys file '%v'", keyfile)
+	}
+	return fmt.Errorf("target '%s' is an I2P destination, but the server's destination comes from %s; set target to the local service address", config.Target, keys)
+}
+
+// validateClientDestination checks, in strict mode, that each destination in
+// a client target is an I2P address, a base64 destination, or a local
+// address. Java I2P accepts a comma-separated list of destinations.
+func (v *ValidationContext) validateClientDestination(config *TunnelConfig) error {
+	if !v.Strict || config.Target == "" {
+		return nil
+	}
+	for _, dest := range strings.Split(config.Target, ",") {
+		dest = strings.TrimSpace(dest)
+		if !isI2PDestination(dest) && !isLocalAddress(dest) {
+			return fmt.Errorf("target '%s' is not an I2P destination; use a name ending in .i2p, a 52-character .b32.i2p address, or a base64 destination", dest)
+		}
+	}
+	return nil
+}
+
+// validateCryptoOptions checks the known crypto.* options against their
+// ranges. i2pd keeps them with the tunnel options; Java I2P and hand-written
+// YAML may put them with the I2CP options, so both maps are checked.
+func validateCryptoOptions(config *TunnelConfig) error {
+	for _, m := range []map[string]interface{}{config.Tunnel, config.I2CP} {
+		if _, err := checkOptionRanges("", m, knownCryptoOptionRanges); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateConnectionLimits checks the known streaming connection limits
+// against their ranges, then warns about limits that contradict each other: a
+// per-minute limit above the hourly one, or a per-peer limit above the total
+// for the same period. The tighter limit applies first, so the other is
+// meaningless. Like the crypto options they may sit in either map.
+func (v *ValidationContext) validateConnectionLimits(config *TunnelConfig) error {
+	limits := make(map[string]int)
+	for _, m := range []map[string]interface{}{config.Tunnel, config.I2CP} {
+		values, err := checkOptionRanges("", m, knownConnectionLimitRanges)
+		if err != nil {
+			return err
+		}
+		for k, n := range values {
+			limits[k] = n
+		}
+	}
+
+	const prefix = "i2p.streaming."
+	pairs := [][2]string{
+		{"maxConnsPerMinute", "maxConnsPerHour"},
+		{"maxConnsPerHour", "maxConnsPerDay"},
+		{"maxTotalConnsPerMinute", "maxTotalConnsPerHour"},
+		{"maxTotalConnsPerHour", "maxTotalConnsPerDay"},
+		{"maxConnsPerMinute", "maxTotalConnsPerMinute"},
+		{"maxConnsPerHour", "maxTotalConnsPerHour"},
+		{"maxConnsPerDay", "maxTotalConnsPerDay"},
+	}
+	for _, p := range pairs {
+		lower, higher := limits[prefix+p[0]], limits[prefix+p[1]]
+		if lower > 0 && higher > 0 && lower > higher {
+			if err := v.warn(fmt.Sprintf("%s%s=%d exceeds %s%s=%d and can never be reached", prefix, p[0], lower, prefix, p[1], higher)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// validateTunnelPoolOptions checks the known inbound and outbound tunnel pool
+// options, such as inbound.quantity, against their ranges.
+func validateTunnelPoolOptions(config *TunnelConfig) error {
+	for _, pool := range []struct {
+		prefix string
+		m      map[string]interface{}
+	}{{"inbound.", config.Inbound}, {"outbound.", config.Outbound}} {
+		if _, err := checkOptionRanges(pool.prefix, pool.m, knownTunnelPoolOptionRanges); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkOptionRanges checks the entries of m that have a range in ranges,
+// naming each in errors with prefix, and returns the checked values as
+// integers keyed like m. Keys without a range are skipped.
+func checkOptionRanges(prefix string, m map[string]interface{}, ranges map[string]optionRange) (map[string]int, error) {
+	values := make(map[string]int)
+	for _, key := range sortedKeys(m) {
+		r, known := ranges[key]
+		if !known {
+			continue
+		}
+		n, err := coerceInt(m[key])
+		if err != nil {
+			return nil, fmt.Errorf("%s%s must be an integer, got '%v'", prefix, key, m[key])
+		}
+		if n < r.min || n > r.max {
+			return nil, fmt.Errorf("%s%s=%d is out of range (%d-%d)", prefix, key, n, r.min, r.max)
+		}
+		values[key] = n
+	}
+	return values, nil
+}
+
+// validateLeaseSetEncType checks that every entry of i2cp.leaseSetEncType is
+// a known encryption type. The value may be a single int, a comma-separated
+// string, or a list, depending on the parser that produced it.
+func validateLeaseSetEncType(config *TunnelConfig) error {
+	v, ok := config.I2CP["leaseSetEncType"]
+	if !ok {
+		return nil
+	}
+	for _, entry := range optionListValues(v) {
+		if !isKnownLeaseSetEncType(entry) {
+			valid := make([]string, len(knownLeaseSetEncTypes))
+			for i, t := range knownLeaseSetEncTypes {
+				valid[i] = strconv.Itoa(t)
+			}
+			return fmt.Errorf("i2cp.leaseSetEncType entry '%s' is not a known encryption type; valid values are %s", entry, strings.Join(valid, ", "))
+		}
+	}
+	return nil
+}
+
+// validateI2CPOptionNames warns about I2CP keys that are not recognised
+// option names, suggesting the closest known name when there is one. The
+// keys are still converted; routers ignore options they do not know.
+func (v *ValidationContext) validateI2CPOptionNames(config *TunnelConfig) error {
+	for _, key := range sortedKeys(config.I2CP) {
+		if isKnownI2CPOption(key) {
+			continue
+		}
+		msg := fmt.Sprintf("unknown I2CP option 'i2cp.%s'", key)
+		if s := suggestI2CPOption(key); s != "" {
+			msg += fmt.Sprintf(" (did you mean 'i2cp.%s'?)", s)
+		}
+		if err := v.warn(msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // validateFormatSpecific performs validation specific to the configuration format
 func (v *ValidationContext) validateFormatSpecific(config *TunnelConfig) error {
 	switch v.Format {
@@ -410,10 +829,50 @@ func (v *ValidationContext) validateINIFormat(config *TunnelConfig) error {
 		if strings.ContainsAny(config.Name, "[]") {
 			return fmt.Errorf("tunnel name '%s' contains characters that may cause issues in INI format", config.Name)
 		}
+		if err := validateSignatureType(config); err != nil {
+			return err
+		}
+		return v.validateINIKeyfile(config)
 	}
 	return nil
 }
 
+// validateINIKeyfile warns when a persistent tunnel names no keyfile. INI
+// output then writes "keys = <name>.dat", which silently shares one key file
+// between tunnels whose names only differ in spaces and underscores, or
+// between same-named tunnels in different files.
+func (v *ValidationContext) validateINIKeyfile(config *TunnelConfig) error {
+	if !config.PersistentKey {
+		return nil
+	}
+	if _, ok := config.Tunnel["keyfile"]; ok {
+		return nil
+	}
+	return v.warn(fmt.Sprintf("persistent tunnel '%s' names no keyfile; INI output derives '%s' from the name, which may collide with another tunnel's keys", config.Name, iniKeyFile(config)))
+}
+
+// validateSignatureType checks that the i2pd signaturetype option, when set,
+// is a signing key type i2pd supports.
+func validateSignatureType(config *TunnelConfig) error {
+	v, ok := config.Tunnel["signaturetype"]
+	if !ok {
+		return nil
+	}
+	valid := make([]string, len(knownSignatureTypes))
+	for i, t := range knownSignatureTypes {
+		valid[i] = strconv.Itoa(t)
+	}
+	n, err := coerceInt(v)
+	if err == nil {
+		for _, t := range knownSignatureTypes {
+			if n == t {
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("signaturetype '%v' is not supported; valid values are %s", v, strings.Join(valid, ", "))
+}
+
 // validateYAMLFormat validates YAML-format-specific constraints
 func (v *ValidationContext) validateYAMLFormat(config *TunnelConfig) error {
 	// YAML format is generally more flexible, fewer constraints
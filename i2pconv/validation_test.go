@@ -1,6 +1,8 @@
 package i2pconv
 
 import (
+	"errors"
+	"strings"
 	"testing"
 )
 
@@ -93,6 +95,8 @@ func TestValidationContext_HTTPClientValidation(t *testing.T) {
 		strict      bool
 		expectedErr bool
 		errorText   string
+		// warningsAsErrors turns strict-mode warnings into failures
+		warningsAsErrors bool
 	}{
 		{
 			name: "valid httpclient",
@@ -143,8 +147,19 @@ func TestValidationContext_HTTPClientValidation(t *testing.T) {
 				Port: 80,
 			},
 			strict:      true,
-			expectedErr: true,
-			errorText:   "privileged range",
+			expectedErr: false,
+		},
+		{
+			name: "httpclient with privileged port (strict, warnings as errors)",
+			config: &TunnelConfig{
+				Name: "webclient",
+				Type: "httpclient",
+				Port: 80,
+			},
+			strict:           true,
+			warningsAsErrors: true,
+			expectedErr:      true,
+			errorText:        "privileged range",
 		},
 		{
 			name: "httpclient with valid target",
@@ -174,6 +189,7 @@ func TestValidationContext_HTTPClientValidation(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			ctx := NewValidationContext(tt.strict, "")
+			ctx.WarningsAsErrors = tt.warningsAsErrors
 			err := ctx.Validate(tt.config)
 
 			if tt.expectedErr && err == nil {
@@ -270,6 +286,8 @@ func TestValidationContext_InterfaceValidation(t *testing.T) {
 		strict      bool
 		expectedErr bool
 		errorText   string
+		// warningsAsErrors turns strict-mode warnings into failures
+		warningsAsErrors bool
 	}{
 		{
 			name: "valid IPv4 interface",
@@ -325,8 +343,20 @@ func TestValidationContext_InterfaceValidation(t *testing.T) {
 				Interface: "eth0",
 			},
 			strict:      true,
-			expectedErr: true,
-			errorText:   "should be a valid IP address",
+			expectedErr: false,
+		},
+		{
+			name: "invalid interface (strict, warnings as errors)",
+			config: &TunnelConfig{
+				Name:      "test",
+				Type:      "httpclient",
+				Port:      8080,
+				Interface: "eth0",
+			},
+			strict:           true,
+			warningsAsErrors: true,
+			expectedErr:      true,
+			errorText:        "should be a valid IP address",
 		},
 		{
 			name: "invalid interface (non-strict)",
@@ -344,6 +374,7 @@ func TestValidationContext_InterfaceValidation(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			ctx := NewValidationContext(tt.strict, "")
+			ctx.WarningsAsErrors = tt.warningsAsErrors
 			err := ctx.Validate(tt.config)
 
 			if tt.expectedErr && err == nil {
@@ -616,7 +647,7 @@ func TestConverter_ValidationIntegration(t *testing.T) {
 			if tt.format == "" {
 				err = converter.validate(tt.config)
 			} else {
-				err = converter.validateWithFormat(tt.config, tt.format)
+				_, err = converter.validateWithFormat(tt.config, tt.format)
 			}
 
 			if tt.expectedErr && err == nil {
@@ -650,11 +681,12 @@ func contains(s, substr string) bool {
 // TestValidateINIFormat exercises validateINIFormat strict-mode branches.
 func TestValidateINIFormat(t *testing.T) {
 	tests := []struct {
-		name      string
-		config    *TunnelConfig
-		strict    bool
-		wantError bool
-		errorText string
+		name        string
+		config      *TunnelConfig
+		strict      bool
+		wantError   bool
+		errorText   string
+		wantWarning string
 	}{
 		{
 			name:      "valid name non-strict",
@@ -693,6 +725,51 @@ func TestValidateINIFormat(t *testing.T) {
 			strict:    true,
 			wantError: false,
 		},
+		{
+			name:      "valid signaturetype strict",
+			config:    &TunnelConfig{Name: "signed", Type: "server", Tunnel: map[string]interface{}{"signaturetype": 7}},
+			strict:    true,
+			wantError: false,
+		},
+		{
+			name:      "invalid signaturetype strict",
+			config:    &TunnelConfig{Name: "signed", Type: "server", Tunnel: map[string]interface{}{"signaturetype": 99}},
+			strict:    true,
+			wantError: true,
+			errorText: "signaturetype '99' is not supported; valid values are 0, 1, 2, 3, 4, 5, 6, 7, 9, 10, 11",
+		},
+		{
+			name:      "non-numeric signaturetype strict",
+			config:    &TunnelConfig{Name: "signed", Type: "server", Tunnel: map[string]interface{}{"signaturetype": "ed25519"}},
+			strict:    true,
+			wantError: true,
+			errorText: "signaturetype 'ed25519' is not supported",
+		},
+		{
+			name:      "invalid signaturetype non-strict is ok",
+			config:    &TunnelConfig{Name: "signed", Type: "server", Tunnel: map[string]interface{}{"signaturetype": 99}},
+			strict:    false,
+			wantError: false,
+		},
+		{
+			name:      "persistent key with explicit keyfile strict",
+			config:    &TunnelConfig{Name: "web", Type: "httpclient", Port: 4444, PersistentKey: true, Tunnel: map[string]interface{}{"keyfile": "web-keys.dat"}},
+			strict:    true,
+			wantError: false,
+		},
+		{
+			name:        "persistent key without keyfile strict",
+			config:      &TunnelConfig{Name: "my web", Type: "httpclient", Port: 4444, PersistentKey: true},
+			strict:      true,
+			wantError:   false,
+			wantWarning: "persistent tunnel 'my web' names no keyfile; INI output derives 'my_web.dat'",
+		},
+		{
+			name:      "persistent key without keyfile non-strict",
+			config:    &TunnelConfig{Name: "web", Type: "httpclient", Port: 4444, PersistentKey: true},
+			strict:    false,
+			wantError: false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -706,6 +783,12 @@ func TestValidateINIFormat(t *testing.T) {
 			} else if tt.wantError && err != nil && !contains(err.Error(), tt.errorText) {
 				t.Errorf("expected error containing %q, got: %v", tt.errorText, err)
 			}
+			warnings := ctx.Warnings()
+			if tt.wantWarning == "" && len(warnings) > 0 {
+				t.Errorf("unexpected warnings: %v", warnings)
+			} else if tt.wantWarning != "" && (len(warnings) != 1 || !contains(warnings[0], tt.wantWarning)) {
+				t.Errorf("warnings = %v, want one containing %q", warnings, tt.wantWarning)
+			}
 		})
 	}
 }
@@ -767,3 +850,600 @@ func TestValidateYAMLFormat(t *testing.T) {
 		})
 	}
 }
+
+// TestValidationContext_UnusedPortAdvisory checks the strict-mode advisory for
+// a server whose port is ignored because its target already names a port.
+func TestValidationContext_UnusedPortAdvisory(t *testing.T) {
+	tests := []struct {
+		name        string
+		config      *TunnelConfig
+		strict      bool
+		wantWarning bool
+	}{
+		{
+			name:        "server port with target port",
+			config:      &TunnelConfig{Name: "site", Type: "server", Port: 8081, Target: "127.0.0.1:8080"},
+			strict:      true,
+			wantWarning: true,
+		},
+		{
+			name:        "httpserver port with target port",
+			config:      &TunnelConfig{Name: "web", Type: "httpserver", Port: 8081, Target: "127.0.0.1:8080"},
+			strict:      true,
+			wantWarning: true,
+		},
+		{
+			name:   "advisory only in strict mode",
+			config: &TunnelConfig{Name: "site", Type: "server", Port: 8081, Target: "127.0.0.1:8080"},
+			strict: false,
+		},
+		{
+			name:   "i2pd-style server port supplies the target port",
+			config: &TunnelConfig{Name: "site", Type: "server", Port: 8080, Target: "127.0.0.1"},
+			strict: true,
+		},
+		{
+			name:   "client uses its port",
+			config: &TunnelConfig{Name: "proxy", Type: "httpclient", Port: 8118, Target: "example.i2p:80"},
+			strict: true,
+		},
+		{
+			name:   "bidir server listens on its port",
+			config: &TunnelConfig{Name: "bidir", Type: "httpbidirserver", Port: 4445, Target: "127.0.0.1:8080"},
+			strict: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := NewValidationContext(tt.strict, "")
+			if err := ctx.Validate(tt.config); err != nil {
+				t.Fatalf("advisories must not fail validation: %v", err)
+			}
+			if got := len(ctx.Warnings()) > 0; got != tt.wantWarning {
+				t.Errorf("warnings = %v, want warning: %v", ctx.Warnings(), tt.wantWarning)
+			}
+			if tt.wantWarning && !contains(ctx.Warnings()[0], "sets port") {
+				t.Errorf("unexpected warning text: %q", ctx.Warnings()[0])
+			}
+		})
+	}
+}
+
+// TestValidationContext_ServerTargetPort checks that strict mode rejects a
+// server whose target names no port when neither targetPort nor the i2pd
+// service port supplies one.
+func TestValidationContext_ServerTargetPort(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  *TunnelConfig
+		strict  bool
+		wantErr bool
+	}{
+		{
+			name:    "server target without port",
+			config:  &TunnelConfig{Name: "site", Type: "server", Target: "webserver"},
+			strict:  true,
+			wantErr: true,
+		},
+		{
+			name:    "httpserver target without port",
+			config:  &TunnelConfig{Name: "web", Type: "httpserver", Target: "127.0.0.1"},
+			strict:  true,
+			wantErr: true,
+		},
+		{
+			name:    "bare IPv6 target",
+			config:  &TunnelConfig{Name: "site", Type: "server", Target: "::1"},
+			strict:  true,
+			wantErr: true,
+		},
+		{
+			name:    "ircserver target without port",
+			config:  &TunnelConfig{Name: "irc", Type: "ircserver", Target: "localhost"},
+			strict:  true,
+			wantErr: true,
+		},
+		{
+			name:   "allowed when not strict",
+			config: &TunnelConfig{Name: "site", Type: "server", Target: "webserver"},
+		},
+		{
+			name:   "host:port target",
+			config: &TunnelConfig{Name: "site", Type: "server", Target: "webserver:8080"},
+			strict: true,
+		},
+		{
+			name:   "Java targetPort",
+			config: &TunnelConfig{Name: "web", Type: "httpserver", Target: "127.0.0.1", Tunnel: map[string]interface{}{"targetPort": 8080}},
+			strict: true,
+		},
+		{
+			name:   "i2pd service port",
+			config: &TunnelConfig{Name: "web", Type: "httpserver", Target: "127.0.0.1", Port: 8080},
+			strict: true,
+		},
+		{
+			name:   "client types are not checked",
+			config: &TunnelConfig{Name: "proxy", Type: "client", Port: 4444, Target: "example.i2p"},
+			strict: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := NewValidationContext(tt.strict, "").Validate(tt.config)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && !contains(err.Error(), "has no port") {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+// TestValidationContext_CryptoOptionRanges checks the strict-mode range checks
+// on the crypto tag options, in either the tunnel or the I2CP map.
+func TestValidationContext_CryptoOptionRanges(t *testing.T) {
+	tests := []struct {
+		name    string
+		tunnel  map[string]interface{}
+		i2cp    map[string]interface{}
+		strict  bool
+		wantErr bool
+	}{
+		{name: "default tagsToSend", tunnel: map[string]interface{}{"crypto.tagsToSend": 40}, strict: true},
+		{name: "string value", tunnel: map[string]interface{}{"crypto.lowTagThreshold": "30"}, strict: true},
+		{name: "tagsToSend too large", tunnel: map[string]interface{}{"crypto.tagsToSend": 999999}, strict: true, wantErr: true},
+		{name: "lowTagThreshold zero", tunnel: map[string]interface{}{"crypto.lowTagThreshold": 0}, strict: true, wantErr: true},
+		{name: "not a number", tunnel: map[string]interface{}{"crypto.tagsToSend": "lots"}, strict: true, wantErr: true},
+		{name: "checked in i2cp map", i2cp: map[string]interface{}{"crypto.tagsToSend": 999999}, strict: true, wantErr: true},
+		{name: "allowed when not strict", tunnel: map[string]interface{}{"crypto.tagsToSend": 999999}},
+		{name: "other crypto options ignored", tunnel: map[string]interface{}{"crypto.ratchet.inboundTags": 999999}, strict: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &TunnelConfig{Name: "proxy", Type: "httpclient", Port: 4444, Tunnel: tt.tunnel, I2CP: tt.i2cp}
+			err := NewValidationContext(tt.strict, "").Validate(config)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestValidationContext_ConnectionLimits checks the strict-mode range checks on
+// the streaming connection limits and the warning for a per-peer limit above
+// its wider or total counterpart.
+func TestValidationContext_ConnectionLimits(t *testing.T) {
+	tests := []struct {
+		name        string
+		tunnel      map[string]interface{}
+		i2cp        map[string]interface{}
+		strict      bool
+		wantErr     string
+		wantWarning string
+	}{
+		{name: "valid maxConcurrentStreams", tunnel: map[string]interface{}{"i2p.streaming.maxConcurrentStreams": 50}, strict: true},
+		{name: "unlimited default", tunnel: map[string]interface{}{"i2p.streaming.maxConcurrentStreams": -1}, strict: true},
+		{name: "maxConcurrentStreams too large", tunnel: map[string]interface{}{"i2p.streaming.maxConcurrentStreams": 5000000}, strict: true, wantErr: "i2p.streaming.maxConcurrentStreams=5000000 is out of range (-1-10000)"},
+		{name: "negative per-minute limit", tunnel: map[string]interface{}{"i2p.streaming.maxConnsPerMinute": -5}, strict: true, wantErr: "out of range"},
+		{name: "not a number", i2cp: map[string]interface{}{"i2p.streaming.maxConnsPerHour": "many"}, strict: true, wantErr: "must be an integer"},
+		{name: "allowed when not strict", tunnel: map[string]interface{}{"i2p.streaming.maxConcurrentStreams": 5000000}},
+		{name: "consistent limits", tunnel: map[string]interface{}{"i2p.streaming.maxConnsPerMinute": 5, "i2p.streaming.maxConnsPerHour": 60, "i2p.streaming.maxTotalConnsPerMinute": 20}, strict: true},
+		{name: "minute above hour", tunnel: map[string]interface{}{"i2p.streaming.maxConnsPerMinute": 50, "i2p.streaming.maxConnsPerHour": 20}, strict: true, wantWarning: "i2p.streaming.maxConnsPerMinute=50 exceeds i2p.streaming.maxConnsPerHour=20"},
+		{name: "per peer above total", tunnel: map[string]interface{}{"i2p.streaming.maxConnsPerDay": 500, "i2p.streaming.maxTotalConnsPerDay": 100}, strict: true, wantWarning: "maxConnsPerDay=500 exceeds i2p.streaming.maxTotalConnsPerDay=100"},
+		{name: "zero disables a limit", tunnel: map[string]interface{}{"i2p.streaming.maxConnsPerMinute": 50, "i2p.streaming.maxConnsPerHour": 0}, strict: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &TunnelConfig{Name: "web", Type: "server", Target: "127.0.0.1:8080", Tunnel: tt.tunnel, I2CP: tt.i2cp}
+			ctx := NewValidationContext(tt.strict, "")
+			err := ctx.Validate(config)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("Validate() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Validate() error = %v", err)
+			}
+			warnings := strings.Join(ctx.Warnings(), "\n")
+			if tt.wantWarning == "" && strings.Contains(warnings, "exceeds") {
+				t.Errorf("unexpected warnings: %s", warnings)
+			}
+			if !strings.Contains(warnings, tt.wantWarning) {
+				t.Errorf("warnings = %q, want %q", warnings, tt.wantWarning)
+			}
+		})
+	}
+}
+
+// TestValidationContext_TunnelPoolOptions checks the strict-mode range checks
+// on the inbound and outbound length, quantity, and backup quantity.
+func TestValidationContext_TunnelPoolOptions(t *testing.T) {
+	tests := []struct {
+		name     string
+		inbound  map[string]interface{}
+		outbound map[string]interface{}
+		strict   bool
+		wantErr  string
+	}{
+		{name: "valid quantities", inbound: map[string]interface{}{"quantity": 3, "backupQuantity": 1}, outbound: map[string]interface{}{"quantity": "2"}, strict: true},
+		{name: "negative quantity", inbound: map[string]interface{}{"quantity": -1}, strict: true, wantErr: "inbound.quantity=-1 is out of range (1-16)"},
+		{name: "zero quantity", outbound: map[string]interface{}{"quantity": 0}, strict: true, wantErr: "outbound.quantity=0 is out of range"},
+		{name: "backup quantity too large", outbound: map[string]interface{}{"backupQuantity": 100}, strict: true, wantErr: "outbound.backupQuantity=100 is out of range (0-16)"},
+		{name: "length too large", inbound: map[string]interface{}{"length": 12}, strict: true, wantErr: "inbound.length=12 is out of range (0-7)"},
+		{name: "not a number", inbound: map[string]interface{}{"quantity": "lots"}, strict: true, wantErr: "inbound.quantity must be an integer"},
+		{name: "allowed when not strict", inbound: map[string]interface{}{"quantity": -1}},
+		{name: "unknown pool options ignored", inbound: map[string]interface{}{"nickname": "web"}, strict: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &TunnelConfig{Name: "proxy", Type: "httpclient", Port: 8118, Inbound: tt.inbound, Outbound: tt.outbound}
+			err := NewValidationContext(tt.strict, "").Validate(config)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Validate() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestValidationContext_Issues checks that warnings are recorded as issues with
+// their severity, and that WarningsAsErrors records them as errors.
+func TestValidationContext_Issues(t *testing.T) {
+	config := &TunnelConfig{Name: "web", Type: "httpclient", Port: 80, Interface: "eth0"}
+
+	ctx := NewValidationContext(true, "")
+	if err := ctx.Validate(config); err != nil {
+		t.Fatalf("warnings must not fail strict validation: %v", err)
+	}
+	if len(ctx.Issues) != 2 {
+		t.Fatalf("Issues = %v, want a privileged port and an interface warning", ctx.Issues)
+	}
+	for _, issue := range ctx.Issues {
+		if issue.Severity != SeverityWarning {
+			t.Errorf("issue %q has severity %v, want warning", issue.Message, issue.Severity)
+		}
+	}
+	if !contains(ctx.Issues[0].String(), "warning: ") || !contains(ctx.Issues[0].Message, "privileged range") {
+		t.Errorf("first issue = %q, want privileged port warning", ctx.Issues[0])
+	}
+
+	ctx = NewValidationContext(true, "")
+	ctx.WarningsAsErrors = true
+	if err := ctx.Validate(config); err == nil || !contains(err.Error(), "privileged range") {
+		t.Fatalf("expected privileged port error with WarningsAsErrors, got %v", err)
+	}
+	if len(ctx.Issues) != 2 || ctx.Issues[0].Severity != SeverityError || ctx.Issues[1].Severity != SeverityError {
+		t.Errorf("Issues = %v, want two errors", ctx.Issues)
+	}
+	if len(ctx.Warnings()) != 0 {
+		t.Errorf("Warnings() = %v, want none", ctx.Warnings())
+	}
+}
+
+// TestValidationContext_ReportsAllErrors checks that validation reports every
+// error it finds rather than stopping at the first, and that ValidationError
+// lists them.
+func TestValidationContext_ReportsAllErrors(t *testing.T) {
+	config := &TunnelConfig{Name: "proxy", Type: "httpclient", Interface: "127.0.0.1 bad"}
+
+	err := NewValidationContext(false, "").Validate(config)
+	if err == nil {
+		t.Fatal("expected validation to fail")
+	}
+	for _, want := range []string{"port must be specified", "whitespace characters"} {
+		if !contains(err.Error(), want) {
+			t.Errorf("error %q does not mention %q", err, want)
+		}
+	}
+
+	_, convErr := (&Converter{}).Convert([]byte("[proxy]\ntype = httpclient\nhost = 127.0.0.1 bad\n"), "ini", "yaml")
+	var verr *ValidationError
+	if !errors.As(convErr, &verr) {
+		t.Fatalf("Convert() error = %v, want *ValidationError", convErr)
+	}
+	if got := verr.Errors(); len(got) != 2 {
+		t.Errorf("ValidationError.Errors() = %v, want 2 errors", got)
+	}
+}
+
+// TestValidationContext_I2CPOptionNames checks the strict-mode warnings for
+// unknown I2CP option names, with a suggestion when a known option is close.
+func TestValidationContext_I2CPOptionNames(t *testing.T) {
+	tests := []struct {
+		name        string
+		i2cp        map[string]interface{}
+		strict      bool
+		wantWarning string
+	}{
+		{name: "known options", i2cp: map[string]interface{}{"reduceIdleTime": 1200000, "leaseSetEncType": "4,0"}, strict: true},
+		{name: "option family", i2cp: map[string]interface{}{"leaseSetClient.dh.0": "alice:key"}, strict: true},
+		{name: "misspelled", i2cp: map[string]interface{}{"reduceIdleTim": 1200000}, strict: true, wantWarning: "did you mean 'i2cp.reduceIdleTime'?"},
+		{name: "wrong case", i2cp: map[string]interface{}{"leasesetenctype": 4}, strict: true, wantWarning: "did you mean 'i2cp.leaseSetEncType'?"},
+		{name: "no close match", i2cp: map[string]interface{}{"frobnicate": true}, strict: true, wantWarning: "unknown I2CP option 'i2cp.frobnicate'"},
+		{name: "ignored when not strict", i2cp: map[string]interface{}{"reduceIdleTim": 1200000}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &TunnelConfig{Name: "proxy", Type: "httpclient", Port: 8118, I2CP: tt.i2cp}
+			ctx := NewValidationContext(tt.strict, "")
+			if err := ctx.Validate(config); err != nil {
+				t.Fatalf("Validate() error = %v", err)
+			}
+			warnings := strings.Join(ctx.Warnings(), "\n")
+			if tt.wantWarning == "" {
+				if warnings != "" {
+					t.Errorf("unexpected warnings: %s", warnings)
+				}
+				return
+			}
+			if !strings.Contains(warnings, tt.wantWarning) {
+				t.Errorf("warnings = %q, want %q", warnings, tt.wantWarning)
+			}
+		})
+	}
+}
+
+// TestValidationContext_ServerKeyfile checks that strict mode rejects a server
+// with persistent keys whose target is an I2P destination, since its
+// destination comes from the keys.
+func TestValidationContext_ServerKeyfile(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  *TunnelConfig
+		strict  bool
+		wantErr string
+	}{
+		{
+			name:   "keyfile with local target",
+			config: &TunnelConfig{Name: "web", Type: "httpserver", Target: "127.0.0.1:8080", PersistentKey: true, Tunnel: map[string]interface{}{"keyfile": "web.dat"}},
+			strict: true,
+		},
+		{
+			name:   "keyfile with hostname target",
+			config: &TunnelConfig{Name: "web", Type: "server", Target: "backend.lan:8080", PersistentKey: true, Tunnel: map[string]interface{}{"keyfile": "web.dat"}},
+			strict: true,
+		},
+		{
+			name:    "keyfile with I2P target",
+			config:  &TunnelConfig{Name: "web", Type: "httpserver", Target: "example.i2p:80", PersistentKey: true, Tunnel: map[string]interface{}{"keyfile": "web.dat"}},
+			strict:  true,
+			wantErr: "destination comes from keys file 'web.dat'",
+		},
+		{
+			name:    "persistent key without keyfile",
+			config:  &TunnelConfig{Name: "irc", Type: "ircserver", Target: "irc.postman.i2p:6667", PersistentKey: true},
+			strict:  true,
+			wantErr: "destination comes from the persistent keys",
+		},
+		{
+			name:   "transient keys with I2P target",
+			config: &TunnelConfig{Name: "web", Type: "server", Target: "example.i2p:80"},
+			strict: true,
+		},
+		{
+			name:   "allowed when not strict",
+			config: &TunnelConfig{Name: "web", Type: "server", Target: "example.i2p:80", PersistentKey: true},
+		},
+		{
+			name:    "bidir server with I2P target",
+			config:  &TunnelConfig{Name: "bidir", Type: "httpbidirserver", Port: 4445, Target: "example.i2p:80", PersistentKey: true},
+			strict:  true,
+			wantErr: "destination comes from the persistent keys",
+		},
+		{
+			name:    "streamr server with I2P target",
+			config:  &TunnelConfig{Name: "udp", Type: "streamrserver", Target: "example.i2p:80", PersistentKey: true},
+			strict:  true,
+			wantErr: "destination comes from the persistent keys",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := NewValidationContext(tt.strict, "").Validate(tt.config)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Validate() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestValidationContext_LeaseSetEncType checks the strict-mode check of each
+// leaseSetEncType entry, whether given as a string, a list, or a number.
+func TestValidationContext_LeaseSetEncType(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   interface{}
+		strict  bool
+		wantErr bool
+	}{
+		{name: "comma string", value: "4,0", strict: true},
+		{name: "string list", value: []string{"4", "0"}, strict: true},
+		{name: "yaml list", value: []interface{}{4, 0}, strict: true},
+		{name: "single int", value: 4, strict: true},
+		{name: "invalid int", value: 9, strict: true, wantErr: true},
+		{name: "invalid entry in list", value: []string{"4", "9"}, strict: true, wantErr: true},
+		{name: "not a number", value: "4,x25519", strict: true, wantErr: true},
+		{name: "allowed when not strict", value: 9},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &TunnelConfig{Name: "proxy", Type: "httpclient", Port: 4444, I2CP: map[string]interface{}{"leaseSetEncType": tt.value}}
+			err := NewValidationContext(tt.strict, "").Validate(config)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !strings.Contains(err.Error(), "valid values are 0, 4, 5, 6, 7") {
+				t.Errorf("error should list the valid types, got %v", err)
+			}
+		})
+	}
+}
+
+// TestValidationContext_RouterPort checks the strict-mode warning for a tunnel
+// that listens on the port of a well-known router service, and that the
+// tunnel providing that service, such as the HTTP proxy on 4444, is exempt.
+func TestValidationContext_RouterPort(t *testing.T) {
+	tests := []struct {
+		name        string
+		config      *TunnelConfig
+		strict      bool
+		wantWarning string
+	}{
+		{name: "I2CP port", config: &TunnelConfig{Name: "proxy", Type: "client", Port: 7654, Target: "example.i2p"}, strict: true, wantWarning: "port 7654 is the default I2CP port"},
+		{name: "SAM port", config: &TunnelConfig{Name: "socks", Type: "socks", Port: 7656}, strict: true, wantWarning: "port 7656 is the default SAM bridge port"},
+		{name: "BOB port", config: &TunnelConfig{Name: "socks", Type: "socks", Port: 2827}, strict: true, wantWarning: "port 2827 is the default BOB bridge port"},
+		{name: "router console port", config: &TunnelConfig{Name: "web", Type: "httpclient", Port: 7657}, strict: true, wantWarning: "port 7657 is the default Java I2P router console port"},
+		{name: "HTTP proxy port", config: &TunnelConfig{Name: "socks", Type: "sockstunnel", Port: 4444}, strict: true, wantWarning: "port 4444 is the default HTTP proxy port"},
+		{name: "HTTP proxy on its own port", config: &TunnelConfig{Name: "proxy", Type: "httpclient", Port: 4444}, strict: true},
+		{name: "SOCKS proxy on its own port", config: &TunnelConfig{Name: "socks", Type: "sockstunnel", Port: 4447}, strict: true},
+		{name: "unrelated port", config: &TunnelConfig{Name: "proxy", Type: "client", Port: 12345, Target: "example.i2p"}, strict: true},
+		{name: "not strict", config: &TunnelConfig{Name: "proxy", Type: "client", Port: 7654, Target: "example.i2p"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := NewValidationContext(tt.strict, "")
+			if err := ctx.Validate(tt.config); err != nil {
+				t.Fatalf("Validate() error = %v", err)
+			}
+			warnings := strings.Join(ctx.Warnings(), "\n")
+			if tt.wantWarning == "" {
+				if warnings != "" {
+					t.Errorf("unexpected warnings: %s", warnings)
+				}
+				return
+			}
+			if !strings.Contains(warnings, tt.wantWarning) {
+				t.Errorf("warnings = %q, want %q", warnings, tt.wantWarning)
+			}
+		})
+	}
+}
+
+// TestValidationContext_ReplacementCharacter checks the warning for U+FFFD,
+// left behind by a bad encoding conversion, in the description and in option
+// keys and values.
+func TestValidationContext_ReplacementCharacter(t *testing.T) {
+	tests := []struct {
+		name        string
+		config      *TunnelConfig
+		wantWarning string
+	}{
+		{
+			name:        "description",
+			config:      &TunnelConfig{Name: "web", Type: "server", Target: "127.0.0.1:8080", Description: "Caf\uFFFD server"},
+			wantWarning: "replacement character U+FFFD in description;",
+		},
+		{
+			name: "option keys and values",
+			config: &TunnelConfig{Name: "web", Type: "server", Target: "127.0.0.1:8080",
+				I2CP:    map[string]interface{}{"inboundNickname": "M\uFFFDnchen"},
+				Unknown: map[string]string{"gr\uFFFD\uFFFDe": "1"}},
+			wantWarning: "replacement character U+FFFD in i2cp.inboundNickname, options.gr\uFFFD\uFFFDe;",
+		},
+		{
+			name:   "clean",
+			config: &TunnelConfig{Name: "web", Type: "server", Target: "127.0.0.1:8080", Description: "Café server"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := NewValidationContext(false, "")
+			if err := ctx.Validate(tt.config); err != nil {
+				t.Fatalf("Validate() error = %v", err)
+			}
+			warnings := strings.Join(ctx.Warnings(), "\n")
+			if tt.wantWarning == "" {
+				if warnings != "" {
+					t.Errorf("unexpected warnings: %s", warnings)
+				}
+				return
+			}
+			if !strings.Contains(warnings, tt.wantWarning) {
+				t.Errorf("warnings = %q, want %q", warnings, tt.wantWarning)
+			}
+		})
+	}
+
+	// A misdecoded properties file carries the character through the parser
+	config, err := (&Converter{}).ParseInput([]byte("name=web\ntype=server\ntargetHost=127.0.0.1\ntargetPort=8080\ndescription=Caf\uFFFD\n"), "properties")
+	if err != nil {
+		t.Fatalf("ParseInput() error = %v", err)
+	}
+	ctx := NewValidationContext(false, "properties")
+	ctx.WarningsAsErrors = true
+	if err := ctx.Validate(config); err == nil || !strings.Contains(err.Error(), "U+FFFD in description") {
+		t.Errorf("Validate() with WarningsAsErrors error = %v, want the encoding warning", err)
+	}
+}
+
+// TestTunnelType_IsServerIsClient checks that every tunnel type, i2pd aliases
+// and mixed case included, is a server or a client but not both, and that
+// unknown types are neither.
+func TestTunnelType_IsServerIsClient(t *testing.T) {
+	tests := []struct {
+		tunnelType TunnelType
+		server     bool
+		client     bool
+	}{
+		{TunnelTypeHTTPClient, false, true},
+		{TunnelTypeSOCKS, false, true},
+		{TunnelTypeSOCKSServer, true, false},
+		{TunnelTypeIRCClient, false, true},
+		{TunnelTypeClient, false, true},
+		{TunnelTypeStreamClient, false, true},
+		{TunnelTypeHTTPServer, true, false},
+		{TunnelTypeServer, true, false},
+		{TunnelTypeIRCServer, true, false},
+		{TunnelTypeStreamServer, true, false},
+		{TunnelTypeHTTPBidir, true, false},
+		{TunnelTypeSOCKSIRC, false, true},
+		{"http", false, true},
+		{"socks", false, true},
+		{"udptunnel", false, true},
+		{"HTTPServer", true, false},
+		{"unknown", false, false},
+		{"", false, false},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.tunnelType), func(t *testing.T) {
+			if got := tt.tunnelType.IsServer(); got != tt.server {
+				t.Errorf("IsServer() = %v, want %v", got, tt.server)
+			}
+			if got := tt.tunnelType.IsClient(); got != tt.client {
+				t.Errorf("IsClient() = %v, want %v", got, tt.client)
+			}
+		})
+	}
+
+	// Every registered type must be classified one way or the other, so a new
+	// type cannot be added without deciding which it is.
+	for name := range NewValidationContext(false, "").TunnelSpecs {
+		if name.IsServer() == name.IsClient() {
+			t.Errorf("tunnel type %q is classified as server=%v client=%v", name, name.IsServer(), name.IsClient())
+		}
+	}
+}
@@ -8,47 +8,128 @@ import (
 )
 
 // countYAMLTunnels returns the number of tunnels defined under the top-level
-// "tunnels" key in the YAML input. Returns 0 on any parse error.
+// "tunnels" key in the YAML input, in either the map or the list form.
+// Returns 0 on any parse error.
 // Used to detect multi-tunnel files and warn the user when only the first
 // tunnel is converted.
 func countYAMLTunnels(input []byte) int {
 	type wrapper struct {
-		Tunnels map[string]interface{} `yaml:"tunnels"`
+		Tunnels interface{} `yaml:"tunnels"`
 	}
 	var w wrapper
 	if err := yaml.Unmarshal(input, &w); err != nil {
 		return 0
 	}
-	return len(w.Tunnels)
+	switch tunnels := w.Tunnels.(type) {
+	case map[interface{}]interface{}:
+		return len(tunnels)
+	case []interface{}:
+		return len(tunnels)
+	default:
+		return 0
+	}
 }
 
-// splitYAMLTunnels returns one TunnelConfig per key in the top-level "tunnels" map.
+// yamlTunnels holds the entries of the top-level "tunnels" key. The go-i2p
+// format writes them as a map keyed by tunnel name, but hand-written files
+// often use a list of entries that each carry a "name"; both decode to the
+// same configs. Map entries are named by their key; list entries keep their
+// order and must have unique, non-empty names.
+type yamlTunnels []*TunnelConfig
+
+// UnmarshalYAML implements yaml.Unmarshaler, choosing the map or list form
+// from the shape of the node so type errors inside an entry are reported
+// against the form the file actually uses.
+func (t *yamlTunnels) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var shape interface{}
+	if err := unmarshal(&shape); err != nil {
+		return err
+	}
+
+	if _, isList := shape.([]interface{}); !isList {
+		var byName map[string]*TunnelConfig
+		if err := unmarshal(&byName); err != nil {
+			return err
+		}
+		for name, config := range byName {
+			if config == nil {
+				return fmt.Errorf("yaml: tunnel '%s' in tunnels map has no fields", name)
+			}
+			config.Name = name
+			*t = append(*t, config)
+		}
+		return nil
+	}
+
+	var list []*TunnelConfig
+	if err := unmarshal(&list); err != nil {
+		return err
+	}
+	seen := make(map[string]int, len(list))
+	for i, config := range list {
+		if config == nil || config.Name == "" {
+			return fmt.Errorf("yaml: tunnels list entry %d has no name", i+1)
+		}
+		if first, exists := seen[config.Name]; exists {
+			return fmt.Errorf("yaml: duplicate tunnel name '%s' in tunnels list (entries %d and %d)", config.Name, first, i+1)
+		}
+		seen[config.Name] = i + 1
+	}
+	*t = list
+	return nil
+}
+
+// splitYAMLTunnels returns one TunnelConfig per entry under the top-level
+// "tunnels" key, in either the map or the list form.
 func splitYAMLTunnels(input []byte) ([]*TunnelConfig, error) {
 	type wrapper struct {
-		Tunnels map[string]*TunnelConfig `yaml:"tunnels"`
+		Tunnels yamlTunnels `yaml:"tunnels"`
 	}
 	var w wrapper
 	if err := yaml.Unmarshal(input, &w); err != nil {
 		return nil, fmt.Errorf("yaml parse error: %w", err)
 	}
 	if len(w.Tunnels) == 0 {
+		if config, ok := parseFlatYAML(input); ok {
+			return []*TunnelConfig{config}, nil
+		}
 		return nil, fmt.Errorf("yaml: no tunnels found in tunnels map")
 	}
-	configs := make([]*TunnelConfig, 0, len(w.Tunnels))
-	for name, cfg := range w.Tunnels {
-		cfg.Name = name
-		configs = append(configs, cfg)
+	for _, cfg := range w.Tunnels {
+		normalizeOptionTypes(cfg)
 	}
-	return configs, nil
+	return w.Tunnels, nil
+}
+
+// parseFlatYAML parses a legacy flat go-i2p document, where the tunnel fields
+// sit at the top level instead of under a "tunnels" map. It only applies when
+// the document has no "tunnels" key at all and names the tunnel with a
+// top-level "name" field; otherwise the second result is false.
+func parseFlatYAML(input []byte) (*TunnelConfig, bool) {
+	var top map[string]interface{}
+	if err := yaml.Unmarshal(input, &top); err != nil {
+		return nil, false
+	}
+	if _, nested := top["tunnels"]; nested {
+		return nil, false
+	}
+	var config TunnelConfig
+	if err := yaml.Unmarshal(input, &config); err != nil || config.Name == "" {
+		return nil, false
+	}
+	normalizeOptionTypes(&config)
+	return &config, true
 }
 
 // parseYAML parses YAML using the standard nested structure with "tunnels" map.
 // This is the go-i2p format where tunnels are defined in a "tunnels" map.
 // The parser extracts the first tunnel for single-tunnel conversion workflows.
-// The tunnel name is set from the map key.
+// The tunnel name is set from the map key. A list of tunnels, each with a
+// "name" field, is accepted in place of the map. Legacy flat documents without
+// a "tunnels" key are accepted as a single tunnel named by their "name" field.
 func (c *Converter) parseYAML(input []byte) (*TunnelConfig, error) {
 	type wrapper struct {
-		Tunnels map[string]*TunnelConfig `yaml:"tunnels"`
+		Tunnels yamlTunnels `yaml:"tunnels"`
 	}
 
 	var w wrapper
@@ -59,16 +140,15 @@ func (c *Converter) parseYAML(input []byte) (*TunnelConfig, error) {
 
 	// Extract the first tunnel (for single-tunnel conversion)
 	if len(w.Tunnels) == 0 {
+		if config, ok := parseFlatYAML(input); ok {
+			return config, nil
+		}
 		return nil, fmt.Errorf("yaml: no tunnels found in tunnels map")
 	}
 
-	// Return the first tunnel with its name set from the map key
-	for name, config := range w.Tunnels {
-		config.Name = name
-		return config, nil
-	}
-
-	return nil, fmt.Errorf("yaml: failed to extract tunnel from nested structure")
+	config := w.Tunnels[0]
+	normalizeOptionTypes(config)
+	return config, nil
 }
 
 // enhanceYAMLError wraps YAML parsing errors with line context.
@@ -115,19 +195,66 @@ func (c *Converter) enhanceYAMLError(input []byte, err error) error {
 	return fmt.Errorf("yaml parse error: %w", err)
 }
 
-// generateYAML creates YAML output in the standard nested structure format.
-// This is the go-i2p format where tunnels are defined in a "tunnels" map.
-// The tunnel is keyed by its name in the map, allowing for future multi-tunnel support.
-func (c *Converter) generateYAML(config *TunnelConfig) ([]byte, error) {
-	type wrapper struct {
-		Tunnels map[string]*TunnelConfig `yaml:"tunnels"`
+// generateMultiYAML creates a single go-i2p YAML document holding every
+// config under the "tunnels" map, keyed by tunnel name. Names must be unique.
+// With PreserveEmptyMaps each tunnel is written through tunnelConfigAllMaps.
+func (c *Converter) generateMultiYAML(configs []*TunnelConfig) ([]byte, error) {
+	tunnels := make(map[string]interface{}, len(configs))
+	for _, config := range configs {
+		if _, exists := tunnels[config.Name]; exists {
+			return nil, fmt.Errorf("duplicate tunnel name '%s'", config.Name)
+		}
+		config = c.withYAMLTypeName(config)
+		if c.preserveEmptyMaps {
+			tunnels[config.Name] = (*tunnelConfigAllMaps)(config)
+		} else {
+			tunnels[config.Name] = config
+		}
 	}
 
-	out := wrapper{
-		Tunnels: map[string]*TunnelConfig{
-			config.Name: config,
-		},
+	return yaml.Marshal(struct {
+		Tunnels map[string]interface{} `yaml:"tunnels"`
+	}{Tunnels: tunnels})
+}
+
+// withYAMLTypeName returns config with its type renamed as the converter's
+// CanonicalTypes setting asks. YAML otherwise keeps the type as parsed, so
+// config itself is returned when no override is set.
+func (c *Converter) withYAMLTypeName(config *TunnelConfig) *TunnelConfig {
+	if c.canonicalTypes == "" {
+		return config
 	}
+	renamed := *config
+	renamed.Type = c.typeName(config.Type, "yaml")
+	return &renamed
+}
+
+// generateYAML creates YAML output in the standard nested structure format.
+// This is the go-i2p format where tunnels are defined in a "tunnels" map.
+// The tunnel is keyed by its name in the map, as generateMultiYAML writes a
+// document of one tunnel.
+func (c *Converter) generateYAML(config *TunnelConfig) ([]byte, error) {
+	return c.generateMultiYAML([]*TunnelConfig{config})
+}
 
-	return yaml.Marshal(out)
+// tunnelConfigAllMaps is TunnelConfig without omitempty on the i2cp,
+// options, inbound, and outbound maps, so a nil or empty map is written as
+// "{}". generateMultiYAML converts to it when the converter preserves empty maps;
+// the conversion fails to compile if the two structs drift apart.
+type tunnelConfigAllMaps struct {
+	Name          string                 `yaml:"name"`
+	Type          string                 `yaml:"type"`
+	Interface     string                 `yaml:"interface,omitempty"`
+	Port          int                    `yaml:"port,omitempty"`
+	Target        string                 `yaml:"target,omitempty"`
+	PersistentKey bool                   `yaml:"persistentKey,omitempty"`
+	Description   string                 `yaml:"description,omitempty"`
+	Enabled       *bool                  `yaml:"enabled,omitempty"`
+	Style         string                 `yaml:"style,omitempty"`
+	I2CP          map[string]interface{} `yaml:"i2cp"`
+	Tunnel        map[string]interface{} `yaml:"options"`
+	Inbound       map[string]interface{} `yaml:"inbound"`
+	Outbound      map[string]interface{} `yaml:"outbound"`
+	Unknown       map[string]string      `yaml:"unknown,omitempty"`
+	Comments      *Comments              `yaml:"-"`
 }
@@ -0,0 +1,267 @@
+package i2pconv
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestParseYAML_FlatFallback verifies that legacy flat YAML documents, which
+// have no "tunnels" wrapper, are parsed as a single tunnel.
+func TestParseYAML_FlatFallback(t *testing.T) {
+	flat := `name: legacy-proxy
+type: httpclient
+interface: 127.0.0.1
+port: 4444
+i2cp:
+  reduceOnIdle: "yes"
+inbound:
+  length: 2
+`
+	dir := t.TempDir()
+	path := filepath.Join(dir, "legacy.yaml")
+	if err := os.WriteFile(path, []byte(flat), 0o644); err != nil {
+		t.Fatalf("setup: %v", err)
+	}
+
+	var config TunnelConfig
+	if err := config.LoadConfig(path); err != nil {
+		t.Fatalf("LoadConfig() on flat YAML: %v", err)
+	}
+	if config.Name != "legacy-proxy" || config.Type != "httpclient" || config.Port != 4444 {
+		t.Errorf("unexpected config: %+v", config)
+	}
+	if config.I2CP["reduceOnIdle"] != true {
+		t.Errorf("flat documents should be normalized like nested ones, got %#v", config.I2CP["reduceOnIdle"])
+	}
+	if config.Inbound["length"] != 2 {
+		t.Errorf("inbound.length = %#v, want 2", config.Inbound["length"])
+	}
+
+	configs, err := (&Converter{}).SplitTunnels([]byte(flat), "yaml")
+	if err != nil || len(configs) != 1 || configs[0].Name != "legacy-proxy" {
+		t.Errorf("SplitTunnels() on flat YAML = %v, %v", configs, err)
+	}
+}
+
+// TestParseYAML_FlatFallbackRejected checks that parseYAML finds no tunnel in
+// an empty tunnels map, even beside flat keys, or in flat keys without a name.
+func TestParseYAML_FlatFallbackRejected(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{name: "empty tunnels map", input: "tunnels: {}\nname: x\ntype: client\n"},
+		{name: "flat without name", input: "type: httpclient\nport: 4444\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := (&Converter{}).parseYAML([]byte(tt.input)); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+// TestParseYAML_ListForm verifies that a list of tunnels under "tunnels"
+// parses to the same configs as the map form.
+func TestParseYAML_ListForm(t *testing.T) {
+	mapForm := `tunnels:
+  web:
+    type: httpclient
+    port: 4444
+    i2cp:
+      reduceOnIdle: "yes"
+  irc:
+    type: ircclient
+    port: 6668
+    target: irc.postman.i2p
+`
+	listForm := `tunnels:
+  - name: web
+    type: httpclient
+    port: 4444
+    i2cp:
+      reduceOnIdle: "yes"
+  - name: irc
+    type: ircclient
+    port: 6668
+    target: irc.postman.i2p
+`
+	conv := &Converter{}
+	byName := func(input string) map[string]*TunnelConfig {
+		t.Helper()
+		configs, err := conv.SplitTunnels([]byte(input), "yaml")
+		if err != nil {
+			t.Fatalf("SplitTunnels() error = %v", err)
+		}
+		m := make(map[string]*TunnelConfig, len(configs))
+		for _, c := range configs {
+			m[c.Name] = c
+		}
+		return m
+	}
+	fromMap, fromList := byName(mapForm), byName(listForm)
+	if len(fromList) != 2 {
+		t.Fatalf("list form gave %d tunnels, want 2", len(fromList))
+	}
+	for name, want := range fromMap {
+		got, ok := fromList[name]
+		if !ok {
+			t.Errorf("list form is missing tunnel %q", name)
+			continue
+		}
+		if diffs := Diff(want, got); len(diffs) != 0 {
+			t.Errorf("tunnel %q differs between forms: %v", name, diffs)
+		}
+	}
+
+	first, err := conv.parseYAML([]byte(listForm))
+	if err != nil {
+		t.Fatalf("parseYAML() error = %v", err)
+	}
+	if first.Name != "web" || first.I2CP["reduceOnIdle"] != true {
+		t.Errorf("parseYAML() = %+v, want the first list entry, normalized", first)
+	}
+	if got := countYAMLTunnels([]byte(listForm)); got != 2 {
+		t.Errorf("countYAMLTunnels() = %d, want 2", got)
+	}
+}
+
+// TestParseYAML_InvalidEntriesRejected checks that list entries without a
+// unique name, and map entries without any fields, are parse errors rather
+// than silently dropped or a panic.
+func TestParseYAML_InvalidEntriesRejected(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr string
+	}{
+		{name: "duplicate names", input: "tunnels:\n  - name: web\n    type: httpclient\n  - name: web\n    type: server\n", wantErr: "duplicate tunnel name 'web'"},
+		{name: "missing name", input: "tunnels:\n  - type: httpclient\n    port: 4444\n", wantErr: "entry 1 has no name"},
+		{name: "empty map entry", input: "tunnels:\n  web:\n", wantErr: "tunnel 'web' in tunnels map has no fields"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, parse := range []func([]byte) error{
+				func(b []byte) error { _, err := (&Converter{}).parseYAML(b); return err },
+				func(b []byte) error { _, err := splitYAMLTunnels(b); return err },
+			} {
+				if err := parse([]byte(tt.input)); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("error = %v, want it to contain %q", err, tt.wantErr)
+				}
+			}
+		})
+	}
+}
+
+// TestYAMLEnabledRoundTrip checks that a disabled tunnel keeps enabled: false
+// through a YAML round trip.
+func TestYAMLEnabledRoundTrip(t *testing.T) {
+	conv := &Converter{}
+	input := []byte(`tunnels:
+  web:
+    type: httpclient
+    port: 4444
+    enabled: false
+`)
+	config, err := conv.ParseInput(input, "yaml")
+	if err != nil {
+		t.Fatalf("ParseInput() error = %v", err)
+	}
+	if config.Enabled == nil || *config.Enabled {
+		t.Fatalf("Enabled = %v, want false", config.Enabled)
+	}
+
+	out, err := conv.generateYAML(config)
+	if err != nil {
+		t.Fatalf("generateYAML() error = %v", err)
+	}
+	if !strings.Contains(string(out), "enabled: false") {
+		t.Errorf("expected 'enabled: false' in output:\n%s", out)
+	}
+	again, err := conv.ParseInput(out, "yaml")
+	if err != nil {
+		t.Fatalf("re-parse error = %v", err)
+	}
+	if again.Enabled == nil || *again.Enabled {
+		t.Errorf("Enabled after round trip = %v, want false", again.Enabled)
+	}
+
+	if err := checkEnabledState(config, "ini"); err == nil || !strings.Contains(err.Error(), "disabled") {
+		t.Errorf("checkEnabledState(ini) = %v, want disabled warning", err)
+	}
+	if err := checkEnabledState(config, "yaml"); err != nil {
+		t.Errorf("checkEnabledState(yaml) = %v, want nil", err)
+	}
+
+	// An unset state is not written at all.
+	unset := &TunnelConfig{Name: "web", Type: "httpclient", Port: 4444}
+	out, err = conv.generateYAML(unset)
+	if err != nil {
+		t.Fatalf("generateYAML() error = %v", err)
+	}
+	if strings.Contains(string(out), "enabled") {
+		t.Errorf("unset Enabled should be omitted:\n%s", out)
+	}
+	if err := checkEnabledState(unset, "properties"); err != nil {
+		t.Errorf("checkEnabledState(unset) = %v, want nil", err)
+	}
+}
+
+// TestGenerateYAML_PreserveEmptyMaps checks that empty option maps are left
+// out of YAML by default and written as "{}" with PreserveEmptyMaps, for
+// single-tunnel and merged multi-tunnel output alike.
+func TestGenerateYAML_PreserveEmptyMaps(t *testing.T) {
+	config := &TunnelConfig{
+		Name:     "web",
+		Type:     "httpclient",
+		Port:     4480,
+		I2CP:     map[string]interface{}{},
+		Outbound: map[string]interface{}{"length": 2},
+	}
+	emptyMaps := []string{"    i2cp: {}\n", "    options: {}\n", "    inbound: {}\n"}
+
+	out, err := NewConverter(false).Marshal(config, "yaml")
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	for _, line := range emptyMaps {
+		if strings.Contains(string(out), line) {
+			t.Errorf("default output contains %q:\n%s", line, out)
+		}
+	}
+
+	conv := NewConverterWithOptions(ConverterOptions{PreserveEmptyMaps: true})
+	out, err = conv.Marshal(config, "yaml")
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	for _, line := range append(emptyMaps, "    outbound:\n      length: 2\n") {
+		if !strings.Contains(string(out), line) {
+			t.Errorf("output with PreserveEmptyMaps lacks %q:\n%s", line, out)
+		}
+	}
+	if strings.Contains(string(out), "unknown:") {
+		t.Errorf("PreserveEmptyMaps must not write the unknown map:\n%s", out)
+	}
+	reparsed, err := conv.ParseInput(out, "yaml")
+	if err != nil {
+		t.Fatalf("ParseInput() error = %v", err)
+	}
+	if !config.Equal(reparsed) {
+		t.Errorf("empty maps changed the config: %v", Diff(config, reparsed))
+	}
+
+	other := &TunnelConfig{Name: "irc", Type: "ircclient", Port: 6668, Target: "irc.postman.i2p"}
+	out, err = conv.generateMultiYAML([]*TunnelConfig{config, other})
+	if err != nil {
+		t.Fatalf("generateMultiYAML() error = %v", err)
+	}
+	if got := strings.Count(string(out), "    i2cp: {}\n"); got != 2 {
+		t.Errorf("merged output has %d empty i2cp maps, want one per tunnel:\n%s", got, out)
+	}
+}
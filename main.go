@@ -5,6 +5,7 @@
 // Usage:
 //
 //	go-i2ptunnel-config [options] <input-file> [output-file]
+//	go-i2ptunnel-config <command> [options] [arguments...]
 //
 // Run go-i2ptunnel-config --help for the full list of flags.
 package main
@@ -74,6 +75,10 @@ COMMON USAGE PATTERNS:
      $ go-i2ptunnel-config --batch --out-format ini "~/.i2p/i2ptunnel.config.d/*.config"
      Converts all Java I2P tunnel configs to i2pd format
 
+  9. Check that a migration preserved every setting:
+     $ go-i2ptunnel-config diff tunnel.config tunnel.yaml
+     Prints field-level differences; exits non-zero when the configs differ
+
 CONFIGURATION EXAMPLES:
   Ready-to-use configuration templates are available in the examples/ directory:
   - httpclient   : HTTP proxy for browsing I2P websites
@@ -91,11 +96,15 @@ VALIDATION MODES:
 
 BATCH PROCESSING:
   When using --batch, the tool:
-  - Accepts glob patterns (e.g., "*.config", "dir/**/*.properties")
+  - Accepts glob patterns (e.g., "*.config", "dir/*.properties", "{http,socks}*.config")
+  - With --recursive, walks a directory tree (e.g., "dir" or "dir/*.config")
   - Processes all matching files independently
-  - Continues processing even if some files fail
+  - Continues processing even if some files fail (unless --fail-fast is set)
   - Reports summary of successful/failed conversions
   - Cannot be used with --output flag (each file gets auto-generated name)
+  - With --output-dir, writes the converted files into that directory instead
+    of next to each input (the directory is created if missing); with
+    --recursive the input's subdirectories are recreated under it
 
 OUTPUT FILE NAMING:
   If no output file is specified, the tool automatically generates one based on:
@@ -108,61 +117,268 @@ EXIT CODES:
   1 : Error (invalid arguments, conversion failure, validation failure)
 
 For more information, visit: https://github.com/go-i2p/go-i2ptunnel-config`,
-		ArgsUsage: "<input-file> [output-file]",
-		Flags: []cli.Flag{
-			&cli.StringFlag{
-				Name:    "in-format",
-				Aliases: []string{"if"},
-				Usage:   "Override input format detection (properties|ini|yaml); required when reading from stdin (\"-\")",
-			},
-			&cli.StringFlag{
-				Name:    "out-format",
-				Aliases: []string{"of"},
-				Usage:   "Set output format: properties (Java I2P), ini (i2pd), yaml (go-i2p)",
-				Value:   "yaml",
-			},
-			&cli.StringFlag{
-				Name:    "output",
-				Aliases: []string{"o"},
-				Usage:   "Specify output file path (auto-generated from input filename if not set)",
-			},
-			&cli.BoolFlag{
-				Name:  "validate",
-				Usage: "Validate configuration without performing conversion",
+		ArgsUsage: "<input-file> [output-file] | --merge -o <output-file> <input-file>...",
+		Flags:     convertFlags(),
+		Action:    i2pconv.ConvertCommand,
+		Commands: []*cli.Command{
+			{
+				Name:      "convert",
+				Usage:     "Convert a tunnel config (the default when no command is given)",
+				ArgsUsage: "<input-file> [output-file] | --merge -o <output-file> <input-file>...",
+				Flags:     convertFlags(),
+				Action:    i2pconv.ConvertCommand,
 			},
-			&cli.BoolFlag{
-				Name:  "strict",
-				Usage: "Enable strict validation (port ranges, target formats, privileged port warnings)",
+			{
+				Name:      "validate",
+				Usage:     "Validate tunnel configs without converting them (same as convert --validate)",
+				ArgsUsage: "<input-file> | --batch <pattern>",
+				Flags:     convertFlags(),
+				Action:    i2pconv.ValidateCommand,
 			},
-			&cli.BoolFlag{
-				Name:  "dry-run",
-				Usage: "Preview conversion output on console without writing files",
+			{
+				Name:   "list-types",
+				Usage:  "Print the supported tunnel types with their descriptions and required fields",
+				Action: i2pconv.ListTypesCommand,
 			},
-			&cli.BoolFlag{
-				Name:  "batch",
-				Usage: "Process multiple files using glob patterns (e.g., \"*.config\")",
+			{
+				Name:   "schema",
+				Usage:  "Print a JSON Schema for the go-i2p YAML format, for editor validation and completion",
+				Action: i2pconv.SchemaCommand,
 			},
-			&cli.BoolFlag{
-				Name:  "sam",
-				Usage: "Generate or load SAM I2P keys; creates a .keys file in --keystore directory",
-			},
-			&cli.StringFlag{
-				Name:  "keystore",
-				Usage: "Directory for SAM .keys files (default: current working directory)",
-			},
-			&cli.BoolFlag{
-				Name:  "split",
-				Usage: "Split a multi-tunnel file, writing one output file per tunnel",
-			},
-			&cli.BoolFlag{
-				Name:  "list-tunnels",
-				Usage: "List all tunnel names in a multi-tunnel file without converting",
+			{
+				Name:      "diff",
+				Usage:     "Compare two tunnel configs field by field, across formats",
+				ArgsUsage: "<file-a> <file-b>",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "in-format",
+						Usage: "Format of the first file (properties|ini|yaml) - auto-detected if not specified",
+					},
+					&cli.StringFlag{
+						Name:  "other-format",
+						Usage: "Format of the second file (properties|ini|yaml) - auto-detected if not specified",
+					},
+				},
+				Action: i2pconv.DiffCommand,
 			},
 		},
-		Action: i2pconv.ConvertCommand,
 	}
 
 	if err := cmd.Run(os.Args); err != nil {
 		log.Fatal(err)
 	}
 }
+
+// convertFlags returns the flags shared by the root command and the convert
+// and validate subcommands. Each call returns new flag values, since cli
+// records parse state on the flags themselves.
+func convertFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:    "in-format",
+			Aliases: []string{"if"},
+			Usage:   "Override input format detection (properties|ini|yaml); required when reading from stdin (\"-\")",
+		},
+		&cli.BoolFlag{
+			Name:  "confirm-format",
+			Usage: "With --in-format, fail if the file extension or content indicates a different format",
+		},
+		&cli.StringFlag{
+			Name:    "out-format",
+			Aliases: []string{"of"},
+			Usage:   "Set output format: properties (Java I2P), ini (i2pd), yaml (go-i2p)",
+			Value:   "yaml",
+		},
+		&cli.StringFlag{
+			Name:    "output",
+			Aliases: []string{"o"},
+			Usage:   "Specify output file path (auto-generated from input filename if not set)",
+		},
+		&cli.BoolFlag{
+			Name:  "validate",
+			Usage: "Validate configuration without performing conversion",
+		},
+		&cli.BoolFlag{
+			Name:  "strict",
+			Usage: "Enable strict validation (port ranges, target formats, privileged port warnings)",
+		},
+		&cli.BoolFlag{
+			Name:  "warnings-as-errors",
+			Usage: "With --strict, fail on warnings such as a privileged port or an unusual interface name",
+		},
+		&cli.StringFlag{
+			Name:  "rules",
+			Usage: "Apply the extra validation rules in this YAML or JSON `FILE` (field, required, allowed, pattern by tunnel type)",
+		},
+		&cli.StringFlag{
+			Name:  "schema-validate",
+			Usage: "Validate each parsed tunnel against a JSON Schema file before converting",
+		},
+		&cli.BoolFlag{
+			Name:  "dry-run",
+			Usage: "Preview conversion output on console without writing files",
+		},
+		&cli.BoolFlag{
+			Name:  "batch",
+			Usage: "Process multiple files using glob patterns (e.g., \"*.config\")",
+		},
+		&cli.StringFlag{
+			Name:  "files-from",
+			Usage: "Batch-convert the paths listed in a file, or on stdin with \"-\" (newline or NUL delimited)",
+		},
+		&cli.StringFlag{
+			Name:  "output-dir",
+			Usage: "With --batch, write the converted files into this directory (created if missing) instead of next to each input",
+		},
+		&cli.BoolFlag{
+			Name:  "recursive",
+			Usage: "With --batch, walk a directory tree (\"dir\" or \"dir/*.config\") instead of a single-level glob",
+		},
+		&cli.BoolFlag{
+			Name:  "report-json",
+			Usage: "With --batch, print the results as a JSON array instead of the text summary",
+		},
+		&cli.StringFlag{
+			Name:  "report-file",
+			Usage: "With --batch, also write the JSON results to this file; stdout keeps the normal summary",
+		},
+		&cli.BoolFlag{
+			Name:  "fail-fast",
+			Usage: "With --batch, stop at the first file that fails instead of processing the rest (files are always processed one at a time)",
+		},
+		&cli.BoolFlag{
+			Name:  "group-by-type",
+			Usage: "With --batch, add a count of converted files per tunnel type to the summary",
+		},
+		&cli.BoolFlag{
+			Name:  "sam",
+			Usage: "Generate or load SAM I2P keys; creates a .keys file in --keystore directory",
+		},
+		&cli.StringFlag{
+			Name:  "keystore",
+			Usage: "Directory for SAM .keys files (default: current working directory)",
+		},
+		&cli.BoolFlag{
+			Name:  "list-types",
+			Usage: "Print the supported tunnel types with their descriptions and required fields",
+		},
+		&cli.BoolFlag{
+			Name:  "schema",
+			Usage: "Print a JSON Schema for the go-i2p YAML format and exit",
+		},
+		&cli.BoolFlag{
+			Name:  "stats",
+			Usage: "Print how many fields and i2cp, options, inbound, and outbound entries each input sets (to stderr, alongside the normal run)",
+		},
+		&cli.BoolFlag{
+			Name:  "count-tunnels",
+			Usage: "Print how many tunnels the input file contains without converting it",
+		},
+		&cli.BoolFlag{
+			Name:  "quiet",
+			Usage: "With --count-tunnels, print only the number, for scripts",
+		},
+		&cli.BoolFlag{
+			Name:  "count-only",
+			Usage: "Print only the number of tunnels in the input file; shorthand for --count-tunnels --quiet",
+		},
+		&cli.BoolFlag{
+			Name:  "split",
+			Usage: "Split a multi-tunnel file, writing one output file per tunnel (into the --output directory if given)",
+		},
+		&cli.BoolFlag{
+			Name:  "generate-keys",
+			Usage: "With --split, generate a key file next to each persistent tunnel's output (needs a SAM bridge on 127.0.0.1:7656)",
+		},
+		&cli.BoolFlag{
+			Name:  "list-tunnels",
+			Usage: "List all tunnel names in a multi-tunnel file without converting",
+		},
+		&cli.BoolFlag{
+			Name:  "in-place",
+			Usage: "Overwrite the input file with the converted output (incompatible with --output; with --dry-run, print a unified diff of the changes instead)",
+		},
+		&cli.BoolFlag{
+			Name:  "normalize",
+			Usage: "Rewrite the input in place in its own format, with sorted keys, explicit defaults, true/false booleans, and comma-joined lists",
+		},
+		&cli.BoolFlag{
+			Name:  "canonical-check",
+			Usage: "Fail unless the input is already canonical, as --normalize writes it: generator key order, true/false booleans, the format's type names, and defaults written out (a config that omits a default is not canonical)",
+		},
+		&cli.BoolFlag{
+			Name:  "no-key-warning",
+			Usage: "Do not warn when the config embeds private keys such as i2cp.leaseSetPrivateKey (the output is converted either way)",
+		},
+		&cli.BoolFlag{
+			Name:  "minimal",
+			Usage: "Emit only name, type, and values that differ from the router defaults (Java I2P's; INI output for i2pd keeps every option)",
+		},
+		&cli.BoolFlag{
+			Name:  "preserve-empty-maps",
+			Usage: "In YAML output, write empty i2cp, options, inbound, and outbound maps as {} instead of omitting them",
+		},
+		&cli.BoolFlag{
+			Name:  "humanize-durations",
+			Usage: "Write duration options such as i2cp.reduceIdleTime as \"15m\" instead of milliseconds (for reading; routers expect milliseconds)",
+		},
+		&cli.BoolFlag{
+			Name:  "extract-comments",
+			Usage: "Also write the input's comments to a <output>.meta.yaml sidecar, keyed by the field they precede",
+		},
+		&cli.StringFlag{
+			Name:  "apply-comments",
+			Usage: "Write the comments of a .meta.yaml sidecar from --extract-comments into the output",
+		},
+		&cli.BoolFlag{
+			Name:  "sort-output",
+			Usage: "Sort list values such as access lists for stable output (preference lists like leaseSetEncType keep their order)",
+		},
+		&cli.StringFlag{
+			Name:  "line-endings",
+			Value: "lf",
+			Usage: "Newline style of generated files (lf|crlf)",
+		},
+		&cli.StringFlag{
+			Name:  "ini-dialect",
+			Value: "standard",
+			Usage: "Key convention for INI tunnel pool options: standard (inbound.length) or prefixed (tunnels.inbound.length); input is read in either",
+		},
+		&cli.StringFlag{
+			Name:  "canonical-types",
+			Usage: "Write tunnel types with Java I2P names (java), i2pd names (i2pd), or as parsed (none) in every output format, overriding the per-format type mapping",
+		},
+		&cli.BoolFlag{
+			Name:  "verify",
+			Usage: "Re-parse the generated output and fail if it does not match the input config",
+		},
+		&cli.BoolFlag{
+			Name:  "force",
+			Usage: "Overwrite the output file if it already exists",
+		},
+		&cli.BoolFlag{
+			Name:  "backup",
+			Usage: "Rename an existing output file to <name>.<timestamp>.bak before writing (implies --force)",
+		},
+		&cli.BoolFlag{
+			Name:  "merge",
+			Usage: "Combine all input files into one multi-tunnel YAML written to --output",
+		},
+		&cli.StringFlag{
+			Name:  "name",
+			Usage: "Rename the tunnel in the output, leaving the input unchanged (not allowed with --batch, --split, or --merge)",
+		},
+		&cli.StringFlag{
+			Name:  "base",
+			Usage: "Lay the input over a base template config; fields the input sets win and option maps are merged key by key",
+		},
+		&cli.StringFlag{
+			Name:  "merge-i2cp-from",
+			Usage: "Merge the i2cp options from another config file into each tunnel (the input's own options win)",
+		},
+		&cli.StringFlag{
+			Name:  "probe-router",
+			Usage: "Test-create each tunnel on the SAM bridge at `ADDR` (e.g. 127.0.0.1:7656) and tear it down again",
+		},
+	}
+}
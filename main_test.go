@@ -12,23 +12,25 @@ import (
 
 // newApp builds the same cli.App that main() creates, so tests exercise flag
 // parsing and routing through ConvertCommand without spawning a subprocess.
+// The convert flags come from convertFlags so the two cannot drift apart.
 func newApp() *cli.App {
 	return &cli.App{
-		Name: "go-i2ptunnel-config",
-		Flags: []cli.Flag{
-			&cli.StringFlag{Name: "in-format", Aliases: []string{"if"}},
-			&cli.StringFlag{Name: "out-format", Aliases: []string{"of"}, Value: "yaml"},
-			&cli.StringFlag{Name: "output", Aliases: []string{"o"}},
-			&cli.BoolFlag{Name: "validate"},
-			&cli.BoolFlag{Name: "strict"},
-			&cli.BoolFlag{Name: "dry-run"},
-			&cli.BoolFlag{Name: "batch"},
-			&cli.BoolFlag{Name: "sam"},
-			&cli.StringFlag{Name: "keystore"},
-			&cli.BoolFlag{Name: "split"},
-			&cli.BoolFlag{Name: "list-tunnels"},
-		},
+		Name:   "go-i2ptunnel-config",
+		Flags:  convertFlags(),
 		Action: i2pconv.ConvertCommand,
+		Commands: []*cli.Command{
+			{Name: "convert", Flags: convertFlags(), Action: i2pconv.ConvertCommand},
+			{Name: "validate", Flags: convertFlags(), Action: i2pconv.ValidateCommand},
+			{Name: "list-types", Action: i2pconv.ListTypesCommand},
+			{
+				Name: "diff",
+				Flags: []cli.Flag{
+					&cli.StringFlag{Name: "in-format"},
+					&cli.StringFlag{Name: "other-format"},
+				},
+				Action: i2pconv.DiffCommand,
+			},
+		},
 	}
 }
 
@@ -115,6 +117,49 @@ func TestMain_Validate(t *testing.T) {
 	}
 }
 
+// TestMain_Subcommands checks that the convert and validate subcommands behave
+// like the bare form, which stays the default for backward compatibility.
+func TestMain_Subcommands(t *testing.T) {
+	content := "tunnel.0.name=myTunnel\ntunnel.0.type=httpclient\ntunnel.0.listenPort=4444\n"
+	tests := []struct {
+		name       string
+		args       []string
+		wantOutput bool
+	}{
+		{name: "bare file", args: nil, wantOutput: true},
+		{name: "convert subcommand", args: []string{"convert"}, wantOutput: true},
+		{name: "convert subcommand with flags", args: []string{"convert", "--out-format", "yaml"}, wantOutput: true},
+		{name: "validate subcommand", args: []string{"validate"}, wantOutput: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			src := filepath.Join(dir, "tunnel.properties")
+			if err := os.WriteFile(src, []byte(content), 0o644); err != nil {
+				t.Fatalf("setup: %v", err)
+			}
+
+			args := append([]string{"go-i2ptunnel-config"}, tt.args...)
+			if err := newApp().Run(append(args, src)); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			_, err := os.Stat(filepath.Join(dir, "tunnel.yaml"))
+			if gotOutput := err == nil; gotOutput != tt.wantOutput {
+				t.Errorf("output written = %v, want %v", gotOutput, tt.wantOutput)
+			}
+		})
+	}
+
+	t.Run("validate subcommand rejects invalid file", func(t *testing.T) {
+		invalid := filepath.Join(t.TempDir(), "invalid.yaml")
+		os.WriteFile(invalid, []byte("tunnels:\n  t:\n    name: t\n    type: httpclient\n"), 0o644)
+		if err := newApp().Run([]string{"go-i2ptunnel-config", "validate", invalid}); err == nil {
+			t.Error("expected validation error, got nil")
+		}
+	})
+}
+
 // TestMain_DryRunStdout checks that --dry-run produces output on stdout without
 // writing any file.
 func TestMain_DryRunStdout(t *testing.T) {
@@ -263,6 +308,44 @@ func TestMain_SplitDryRun(t *testing.T) {
 	}
 }
 
+// TestMain_ListTypes checks that the list-types command and flag need no
+// input file and print each tunnel type with its description and required fields.
+func TestMain_ListTypes(t *testing.T) {
+	for _, arg := range []string{"list-types", "--list-types"} {
+		t.Run(arg, func(t *testing.T) {
+			origStdout := os.Stdout
+			r, w, _ := os.Pipe()
+			os.Stdout = w
+
+			app := newApp()
+			runErr := app.Run([]string{"go-i2ptunnel-config", arg})
+
+			w.Close()
+			os.Stdout = origStdout
+			var buf strings.Builder
+			tmp := make([]byte, 4096)
+			for {
+				n, err := r.Read(tmp)
+				buf.Write(tmp[:n])
+				if err != nil {
+					break
+				}
+			}
+			r.Close()
+
+			if runErr != nil {
+				t.Fatalf("unexpected error: %v", runErr)
+			}
+			output := buf.String()
+			for _, want := range []string{"httpclient", "HTTP proxy client tunnel (requires: port)", "httpserver", "(requires: target)"} {
+				if !strings.Contains(output, want) {
+					t.Errorf("%s output missing %q; got:\n%s", arg, want, output)
+				}
+			}
+		})
+	}
+}
+
 // TestMain_ListTunnels checks that --list-tunnels on a 3-section INI file
 // prints all three tunnel names without error and without writing files.
 func TestMain_ListTunnels(t *testing.T) {